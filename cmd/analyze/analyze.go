@@ -14,34 +14,86 @@ limitations under the License.
 package analyze
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/ai/interactive"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// problemsExitCode and partialFailureExitCode are the exit codes of the
+// summary output when problems are found, and when none are but the analysis
+// is incomplete. The errors exit with 1.
+const (
+	problemsExitCode       = 2
+	partialFailureExitCode = 3
+)
+
 var (
-	explain         bool
-	backend         string
-	output          string
-	filters         []string
-	language        string
-	nocache         bool
-	namespace       string
-	labelSelector   string
-	anonymize       bool
-	maxConcurrency  int
-	withDoc         bool
-	interactiveMode bool
-	customAnalysis  bool
-	customHeaders   []string
-	withStats       bool
+	explain           bool
+	backend           string
+	output            string
+	filters           []string
+	language          string
+	nocache           bool
+	namespaces        []string
+	labelSelector     string
+	fieldSelector     string
+	anonymize         bool
+	maxConcurrency    int
+	withDoc           bool
+	interactiveMode   bool
+	customAnalysis    bool
+	customHeaders     []string
+	withStats         bool
+	executionBudget   time.Duration
+	excludeFilters    []string
+	analyzerTimeout   time.Duration
+	minSeverity       string
+	noProgress        bool
+	stream            bool
+	dryRun            bool
+	manifests         string
+	groupBy           string
+	previous          string
+	since             time.Duration
+	maxProblems       int
+	maxPerAnalyzer    int
+	statsFile         string
+	outputFile        string
+	outputs           []analysis.Output
+	noAIOnCacheMiss   bool
+	withDocBestEffort bool
+	noPermissionCheck bool
+	recheckCount      int
+	recheckDelay      time.Duration
+	kubecontexts      []string
+	saveSession       string
+	fromSession       string
+	includeEvents     bool
+	includeCluster    bool
+	showKinds         []string
+	watch             bool
+	interval          time.Duration
+	temperature       float32
+	topP              float32
+	maxTokens         int
 )
 
 // AnalyzeCmd represents the problems command
@@ -52,8 +104,65 @@ var AnalyzeCmd = &cobra.Command{
 	Long: `This command will find problems within your Kubernetes cluster and
 	provide you with a list of issues that need to be resolved`,
 	Run: func(cmd *cobra.Command, args []string) {
+		// Interrupting the run stops the analysis, a second interrupt exits.
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			stop()
+		}()
+
+		log := analysis.NewLogger()
+		var err error
+		outputs, err = analysis.ParseOutputs(output, outputFile)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		if fromSession != "" {
+			// Neither the cluster nor the AI provider are queried.
+			config, err := analysis.LoadSession(fromSession)
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+			config.GroupBy = groupBy
+			writeOutput(config, log)
+			if code := statusExitCode(config.Status()); hasOutput("summary") && code != 0 {
+				os.Exit(code)
+			}
+			return
+		}
+
+		if manifests != "" {
+			viper.Set("manifests", manifests)
+		}
+		// The generation parameters of the AI providers are overridden for
+		// this run only.
+		if cmd.Flags().Changed("temperature") {
+			viper.Set("generation.temperature", temperature)
+		}
+		if cmd.Flags().Changed("top-p") {
+			viper.Set("generation.top_p", topP)
+		}
+		if cmd.Flags().Changed("max-tokens") {
+			viper.Set("generation.max_tokens", maxTokens)
+		}
+		if len(kubecontexts) > 0 {
+			kubecontexts, _ = util.RemoveDuplicates(kubecontexts)
+			viper.Set("kubecontexts", kubecontexts)
+		}
+
+		// A single namespace is analyzed as before, several are fanned out.
+		namespaces, _ = util.RemoveDuplicates(namespaces)
+		var namespace string
+		if len(namespaces) == 1 {
+			namespace = namespaces[0]
+		}
+
 		// Create analysis configuration first.
-		config, err := analysis.NewAnalysis(
+		config, err := analysis.NewAnalysisWithContext(
+			ctx,
 			backend,
 			language,
 			filters,
@@ -65,108 +174,318 @@ var AnalyzeCmd = &cobra.Command{
 			withDoc,
 			interactiveMode,
 			customHeaders,
-			withStats,
+			// The stats file needs the stats collected too.
+			withStats || statsFile != "",
 		)
-
-		verbose := viper.GetBool("verbose")
-		if verbose {
-			fmt.Println("Debug: Checking analysis configuration.")
-		}
 		if err != nil {
 			color.Red("Error: %v", err)
 			os.Exit(1)
 		}
-		if verbose {
-			fmt.Println("Debug: Analysis initialized.")
-		}
+		log.Debug("Analysis initialized")
 		defer config.Close()
-
-		if customAnalysis {
-			config.RunCustomAnalysis()
-			if verbose {
-				fmt.Println("Debug: All custom analyzers completed.")
+		if len(namespaces) > 1 {
+			config.Namespaces = namespaces
+		}
+		config.ExecutionBudget = executionBudget
+		config.ExcludeFilters = excludeFilters
+		if noProgress {
+			config.NoProgress = true
+		}
+		if withDocBestEffort {
+			config.WithDocBestEffort = true
+		}
+		if noPermissionCheck {
+			config.CheckPermissions = false
+		}
+		if includeEvents {
+			config.IncludeEvents = true
+		}
+		if includeCluster {
+			config.IncludeClusterInfo = true
+		}
+		config.Stream = stream
+		if consoleFormats() == "ndjson" && !watch {
+			// The results are printed as soon as they are explained, the
+			// output then adds the others and the summary line. Another
+			// format on the standard output would be interleaved with them.
+			config.ResultLines = os.Stdout
+		}
+		if address := viper.GetString("metrics.address"); address != "" {
+			// A single run exits before the metrics could be scraped.
+			if watch {
+				config.Metrics = analysis.PrometheusMetrics{}
+				go serveMetrics(address)
+			} else {
+				color.Yellow("metrics.address is only served with --watch")
 			}
 		}
-		config.RunAnalysis()
-		if verbose {
-			fmt.Println("Debug: All core analyzers completed.")
+		if analyzerTimeout > 0 {
+			config.AnalyzerTimeout = analyzerTimeout
+		}
+		if recheckCount > 0 {
+			config.RecheckCount = recheckCount
+		}
+		if recheckDelay > 0 {
+			config.RecheckDelay = recheckDelay
+		}
+		if minSeverity != "" {
+			config.MinSeverity, err = common.ParseSeverity(minSeverity)
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
 		}
 
-		if explain {
-			err := config.GetAIResults(output, anonymize)
-			if verbose {
-				fmt.Println("Debug: Checking AI results.")
+		if groupBy != "" && groupBy != analysis.GroupByNamespace {
+			color.Red("Error: unsupported grouping: %s. Available grouping %s", groupBy, analysis.GroupByNamespace)
+			os.Exit(1)
+		}
+		if interactiveMode && explain {
+			config.ConfirmBudget = func(estimate analysis.CostEstimate) bool {
+				confirmed, _ := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("The estimated cost of %.4f (%d prompt tokens) exceeds the budget of %.4f. Explain the results anyway?", estimate.Cost, estimate.PromptTokens, config.Budget))
+				return confirmed
 			}
+		}
+		config.GroupBy = groupBy
+		config.ShowKinds = showKinds
+		config.FieldSelector = fieldSelector
+		config.Since = since
+		if maxProblems < 0 {
+			color.Red("Error: --max-problems must not be negative")
+			os.Exit(1)
+		}
+		config.MaxProblems = maxProblems
+		if maxPerAnalyzer < 0 {
+			color.Red("Error: --max-results-per-analyzer must not be negative")
+			os.Exit(1)
+		}
+		config.MaxResultsPerAnalyzer = maxPerAnalyzer
+		if noAIOnCacheMiss && nocache {
+			color.Red("Error: --no-ai-on-cache-miss cannot be used with --no-cache")
+			os.Exit(1)
+		}
+		config.CacheOnly = noAIOnCacheMiss
+		if previous != "" {
+			config.Previous, err = analysis.LoadPreviousOutput(previous)
 			if err != nil {
 				color.Red("Error: %v", err)
 				os.Exit(1)
 			}
 		}
-		// print results
-		output_data, err := config.PrintOutput(output)
-		if verbose {
-			fmt.Println("Debug: Checking output.")
+
+		if watch && interval <= 0 {
+			color.Red("Error: --interval must be positive")
+			os.Exit(1)
 		}
-		if err != nil {
-			color.Red("Error: %v", err)
+		if watch && interactiveMode {
+			color.Red("Error: --watch cannot be used with --interactive")
 			os.Exit(1)
 		}
 
-		if withStats {
-			statsData := config.PrintStats()
-			fmt.Println(string(statsData))
+		if dryRun {
+			config.CustomAnalysis = customAnalysis
+			fmt.Print(string(config.PrintDryRun(config.DryRun())))
+			return
 		}
 
-		fmt.Println(string(output_data))
+		for run := 1; ; run++ {
+			explainErr := runAnalysis(config, log)
+			// In watch mode the runs without new or resolved problems aren't
+			// output.
+			if run == 1 || config.Changed() {
+				config.SendWebhook()
+				if saveSession != "" {
+					if err := config.SaveSession(saveSession); err != nil {
+						color.Red("Error: %v", err)
+						os.Exit(1)
+					}
+					log.Debug("Session saved", "file", saveSession)
+				}
+				writeOutput(config, log)
+				if config.Status() == analysis.StateNoAnalyzers {
+					// Not a healthy cluster, the filters are wrong.
+					config.Close()
+					color.Red("Error: %v", analysis.ErrNoAnalyzers)
+					os.Exit(1)
+				}
+			} else {
+				log.Debug("Run found no new or resolved problems", "run", run)
+			}
+			if explainErr != nil {
+				if watch {
+					// A later run may explain them.
+					color.Red("Error: %v", explainErr)
+				} else {
+					// The results explained before the failure are output
+					// above, and cached by Close.
+					config.Close()
+					color.Red("Error: %v", explainErr)
+					os.Exit(1)
+				}
+			}
+			if code := statusExitCode(config.Status()); !watch && hasOutput("summary") && code != 0 {
+				// For the scripts branching on the exit code.
+				config.Close()
+				os.Exit(code)
+			}
+			if !watch {
+				break
+			}
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				// Returning runs the deferred Close of the analysis.
+				return
+			}
+			config.NextRun()
+		}
 
 		if interactiveMode && explain {
-			if output == "json" {
+			if hasOutput("json") {
 				color.Yellow("Caution: interactive mode using --json enabled may use additional tokens.")
 			}
-			sigs := make(chan os.Signal, 1)
-			signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-			interactiveClient := interactive.NewInteractionRunner(config, output_data)
-
-			go interactiveClient.StartInteraction()
-			for {
-				select {
-				case res := <-sigs:
-					switch res {
-					default:
-						os.Exit(0)
-					}
-				case res := <-interactiveClient.State:
-					switch res {
-					case interactive.E_EXITED:
-						os.Exit(0)
-					}
-				}
+			interactiveClient := interactive.NewInteractionRunner(config)
+			done := make(chan struct{})
+			go func() {
+				interactiveClient.StartInteraction()
+				close(done)
+			}()
+			// Returning runs the deferred Close of the analysis.
+			select {
+			case <-done:
+			case <-ctx.Done():
 			}
 		}
 	},
 }
 
+// runAnalysis runs the custom analyzers when enabled and the core analyzers,
+// then explains the results when enabled. It returns the error explaining
+// them.
+func runAnalysis(config *analysis.Analysis, log *slog.Logger) error {
+	if customAnalysis {
+		config.RunCustomAnalysis()
+		log.Debug("All custom analyzers completed")
+	}
+	config.RunAnalysis()
+	log.Debug("All core analyzers completed")
+
+	if !explain {
+		return nil
+	}
+	// The progress bar is drawn in the console, so it doesn't matter which
+	// formats go to files unless they all do.
+	formats := consoleFormats()
+	if formats == "" {
+		formats = output
+	}
+	return config.GetAIResults(formats, anonymize)
+}
+
+// statusExitCode is the exit code of the summary output for the status of the
+// analysis.
+func statusExitCode(status analysis.AnalysisStatus) int {
+	switch status {
+	case analysis.StateProblemDetected:
+		return problemsExitCode
+	case analysis.StatePartialFailure:
+		return partialFailureExitCode
+	default:
+		return 0
+	}
+}
+
+// hasOutput tells whether format is one of the outputs.
+func hasOutput(format string) bool {
+	return slices.ContainsFunc(outputs, func(output analysis.Output) bool { return output.Format == format })
+}
+
+// consoleFormats returns the formats of the outputs written to the standard
+// output, comma-separated.
+func consoleFormats() string {
+	var formats []string
+	for _, output := range outputs {
+		if output.File == "" {
+			formats = append(formats, output.Format)
+		}
+	}
+	return strings.Join(formats, ",")
+}
+
+// writeOutput prints the output of the analysis and its stats in the formats
+// and to the files of the flags.
+func writeOutput(config *analysis.Analysis, log *slog.Logger) {
+	// The summary and the ndjson outputs have the stats on their line.
+	if withStats && !hasOutput("summary") && !hasOutput("ndjson") {
+		statsData := config.PrintStats()
+		fmt.Println(string(statsData))
+	}
+	if statsFile != "" {
+		if err := config.WriteStats(statsFile); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := config.WriteOutputs(outputs); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	for _, output := range outputs {
+		if output.File != "" {
+			log.Debug("Output written", "format", output.Format, "file", output.File)
+		}
+	}
+}
+
+// serveMetrics exposes the Prometheus metrics on address for the lifetime of
+// the command.
+func serveMetrics(address string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{
+		ReadHeaderTimeout: 3 * time.Second,
+		Addr:              address,
+		Handler:           mux,
+	}
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		color.Red("Error: serving metrics on %s: %v", address, err)
+	}
+}
+
 func init() {
 	// namespace flag
-	AnalyzeCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to analyze")
+	AnalyzeCmd.Flags().StringSliceVarP(&namespaces, "namespace", "n", nil, "Namespaces to analyze, comma-separated or repeated (e.g. -n team-a,team-b). All namespaces when not set")
 	// no cache flag
 	AnalyzeCmd.Flags().BoolVarP(&nocache, "no-cache", "c", false, "Do not use cached data")
 	// anonymize flag
 	AnalyzeCmd.Flags().BoolVarP(&anonymize, "anonymize", "a", false, "Anonymize data before sending it to the AI backend. This flag masks sensitive data, such as Kubernetes object names and labels, by replacing it with a key. However, please note that this flag does not currently apply to events.")
 	// array of strings flag
 	AnalyzeCmd.Flags().StringSliceVarP(&filters, "filter", "f", []string{}, "Filter for these analyzers (e.g. Pod, PersistentVolumeClaim, Service, ReplicaSet)")
+	// exclude analyzers flag
+	AnalyzeCmd.Flags().StringSliceVar(&excludeFilters, "exclude", []string{}, "Exclude these analyzers, even when selected by filters (e.g. Service)")
 	// explain flag
 	AnalyzeCmd.Flags().BoolVarP(&explain, "explain", "e", false, "Explain the problem to me")
 	// add flag for backend
 	AnalyzeCmd.Flags().StringVarP(&backend, "backend", "b", "", "Backend AI provider")
 	// output as json
-	AnalyzeCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format (text, json)")
+	AnalyzeCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format (text, json, yaml, sarif, junit, summary, ndjson), or a comma-separated list of them, rendered after a single analysis. With summary, a single status line is printed and k8sgpt exits with 2 when problems are found, or with 3 when none are but analyzers failed. With ndjson, a JSON line is printed per result as soon as it is explained, then a summary line")
+	// output file flag
+	AnalyzeCmd.Flags().StringVar(&outputFile, "output-file", "", "Write the output to this file instead of the standard output, in the format of --output. With several formats, {format} in the path is replaced by each of them, or the formats are mapped to their files as format=path,... and the others are printed. Parent directories are created and the files are replaced atomically")
 	// add language options for output
 	AnalyzeCmd.Flags().StringVarP(&language, "language", "l", "english", "Languages to use for AI (e.g. 'English', 'Spanish', 'French', 'German', 'Italian', 'Portuguese', 'Dutch', 'Russian', 'Chinese', 'Japanese', 'Korean')")
 	// add max concurrency
-	AnalyzeCmd.Flags().IntVarP(&maxConcurrency, "max-concurrency", "m", 10, "Maximum number of concurrent requests to the Kubernetes API server")
+	AnalyzeCmd.Flags().IntVarP(&maxConcurrency, "max-concurrency", "m", 10, "Maximum number of concurrent requests to the Kubernetes API server, see ai.concurrency for the AI provider")
 	// kubernetes doc flag
 	AnalyzeCmd.Flags().BoolVarP(&withDoc, "with-doc", "d", false, "Give me the official documentation of the involved field")
+	// kubernetes doc best effort flag
+	AnalyzeCmd.Flags().BoolVar(&withDocBestEffort, "with-doc-best-effort", false, "Analyze without the documentation when it can't be fetched, e.g. without the RBAC to, instead of reporting an error. Also read from the with_doc_best_effort configuration key")
+	// no permission check flag
+	AnalyzeCmd.Flags().BoolVar(&noPermissionCheck, "no-permission-check", false, "Do not check with the API server that the selected analyzers are permitted to list what they analyze before running them, sparing the extra requests. Also read from the no_permission_check configuration key")
+	// include events flag
+	AnalyzeCmd.Flags().BoolVar(&includeEvents, "include-events", false, "Send the recent events of the objects with problems to the AI provider along with the problems, for better explanations. It lists the events of each problem. Also read from the include_events configuration key")
+	// include cluster info flag
+	AnalyzeCmd.Flags().BoolVar(&includeCluster, "include-cluster-info", false, "Send the Kubernetes version, cloud provider and region, and node OS and architecture of the cluster to the AI provider along with each problem, for better explanations. Also read from the include_cluster_info configuration key")
 	// interactive mode flag
 	AnalyzeCmd.Flags().BoolVarP(&interactiveMode, "interactive", "i", false, "Enable interactive mode that allows further conversation with LLM about the problem. Works only with --explain flag")
 	// custom analysis flag
@@ -175,6 +494,53 @@ func init() {
 	AnalyzeCmd.Flags().StringSliceVarP(&customHeaders, "custom-headers", "r", []string{}, "Custom Headers, <key>:<value> (e.g CustomHeaderKey:CustomHeaderValue AnotherHeader:AnotherValue)")
 	// label selector flag
 	AnalyzeCmd.Flags().StringVarP(&labelSelector, "selector", "L", "", "Label selector (label query) to filter on, supports '=', '==', and '!='. (e.g. -L key1=value1,key2=value2). Matching objects must satisfy all of the specified label constraints.")
+	// field selector flag
+	AnalyzeCmd.Flags().StringVar(&fieldSelector, "field-selector", "", "Field selector (field query) to filter on, supports '=', '==', and '!='. (e.g. --field-selector status.phase=Running). Matching objects must also satisfy --selector. The analyzers of the resources which don't support a field fail, combine it with --filter")
 	// print stats
 	AnalyzeCmd.Flags().BoolVarP(&withStats, "with-stat", "s", false, "Print analysis stats. This option disables errors display.")
+	// execution budget flag
+	AnalyzeCmd.Flags().DurationVarP(&executionBudget, "execution-budget", "", 0, "Wall-clock budget for launching analyzers (e.g. 30s, 2m). Once exceeded, remaining analyzers are skipped and reported. 0 means no budget")
+	// recheck flags
+	AnalyzeCmd.Flags().IntVar(&recheckCount, "recheck", 0, "Run the analyzers which found problems this many more times before explaining them, and drop the problems which cleared in between, e.g. during a rollout. Overrides the recheck.count configuration. 0 disables the rechecks")
+	AnalyzeCmd.Flags().DurationVar(&recheckDelay, "recheck-delay", 0, "Time to wait before each recheck (e.g. 30s), overrides the recheck.delay configuration")
+	// kube contexts flag
+	AnalyzeCmd.Flags().StringSliceVar(&kubecontexts, "kubecontexts", []string{}, "Kube contexts to analyze in one run, instead of --kubecontext (e.g. --kubecontexts prod,staging). The problems are labeled with their context")
+	// analyzer timeout flag
+	AnalyzeCmd.Flags().DurationVar(&analyzerTimeout, "analyzer-timeout", 0, "Maximum time each analyzer, custom ones included, may run (e.g. 90s), overrides the analyzer_timeout configuration. 0 means no timeout")
+	// show kind flag
+	AnalyzeCmd.Flags().StringSliceVar(&showKinds, "show-kind", []string{}, "Only report and explain the problems of this kind, ignoring case, e.g. Pod for the Pod and Security/Pod problems. Can be repeated. Unlike --filter, all the analyzers still run")
+	// minimum severity flag
+	AnalyzeCmd.Flags().StringVar(&minSeverity, "min-severity", "", "Only report problems of at least this severity (Critical, Warning, Info)")
+	// no progress flag
+	AnalyzeCmd.Flags().BoolVar(&noProgress, "no-progress", false, "Do not draw the progress bar while explaining the results, overrides the no_progress configuration")
+	// stream flag
+	AnalyzeCmd.Flags().BoolVar(&stream, "stream", false, "Print the explanations as the AI provider generates them. Works only with --explain flag and the text output")
+	// dry run flag
+	AnalyzeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the analyzers which would run and the number of objects each would scan, without analyzing or calling the AI provider")
+	// manifests flag
+	AnalyzeCmd.Flags().StringVar(&manifests, "manifests", "", "Analyze the YAML/JSON manifests of this directory instead of a cluster")
+	// group by flag
+	AnalyzeCmd.Flags().StringVar(&groupBy, "group-by", "", "Group the results of the text output (namespace)")
+	// previous analysis flag
+	AnalyzeCmd.Flags().StringVar(&previous, "previous", "", "JSON output of a previous analysis to report the new and resolved problems against. Only the new problems are explained")
+	// since flag
+	AnalyzeCmd.Flags().DurationVar(&since, "since", 0, "Only analyze the objects created or changed within this window (e.g. 1h). Ignored by the Log, Security, Storage and TLSCertificate analyzers, the integrations and the custom analyzers. 0 analyzes all objects")
+	// stats file flag
+	AnalyzeCmd.Flags().StringVar(&statsFile, "stats-file", "", "Write the analysis stats as JSON to this file, with the durations in milliseconds")
+	// session flags
+	AnalyzeCmd.Flags().StringVar(&saveSession, "save-session", "", "Save the results, errors and stats of the analysis to this file, to render them later with --from-session")
+	AnalyzeCmd.Flags().StringVar(&fromSession, "from-session", "", "Render a session saved with --save-session in the format of --output, without analyzing the cluster or querying the AI provider")
+	// watch flags
+	AnalyzeCmd.Flags().BoolVar(&watch, "watch", false, "Analyze again every --interval until interrupted, and only output the runs with new or resolved problems. The unchanged problems reuse their explanations")
+	AnalyzeCmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "Time to wait between the runs of --watch (e.g. 5m)")
+	// max problems flag
+	AnalyzeCmd.Flags().IntVar(&maxProblems, "max-problems", 0, "Only report and explain the first N problems, the most severe ones, once duplicates are collapsed. 0 reports all problems")
+	// max results per analyzer flag
+	AnalyzeCmd.Flags().IntVar(&maxPerAnalyzer, "max-results-per-analyzer", 0, "Only keep the N most severe results of each analyzer, counting the others as truncated. 0 keeps all results")
+	// no AI on cache miss flag
+	AnalyzeCmd.Flags().BoolVar(&noAIOnCacheMiss, "no-ai-on-cache-miss", false, "Only explain the results with a cached explanation and never call the AI provider, e.g. in air-gapped clusters. Works only with --explain flag")
+	// generation parameter flags
+	AnalyzeCmd.Flags().Float32Var(&temperature, "temperature", 0, "Override the sampling temperature of the AI providers for this run, between 0 (more deterministic) and 1 (more random)")
+	AnalyzeCmd.Flags().Float32Var(&topP, "top-p", 0, "Override the probability cutoff of the AI providers for this run, between 0 and 1")
+	AnalyzeCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Override the maximum output length of the AI providers for this run, in tokens")
 }
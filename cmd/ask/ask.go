@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ask
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backend       string
+	language      string
+	nocache       bool
+	anonymize     bool
+	output        string
+	customHeaders []string
+)
+
+// AskCmd represents the ask command
+var AskCmd = &cobra.Command{
+	Use:   "ask [text]",
+	Short: "Explain an error message without analyzing the cluster",
+	Long: `This command explains an arbitrary text, such as a kubectl error message,
+	with the AI provider. The text is read from the arguments, or from stdin when there are none.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		text := strings.Join(args, " ")
+		if len(args) == 0 {
+			input, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+			text = string(input)
+		}
+
+		config, err := analysis.NewTextAnalysis(context.Background(), backend, language, nocache, customHeaders)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		defer config.Close()
+
+		result, err := config.ExplainText(text, anonymize)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+
+		if output == "json" {
+			outputData, err := config.PrintOutput(output)
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(outputData))
+			return
+		}
+		fmt.Println(color.GreenString(result.Details))
+	},
+}
+
+func init() {
+	// add flag for backend
+	AskCmd.Flags().StringVarP(&backend, "backend", "b", "", "Backend AI provider")
+	// add language options for output
+	AskCmd.Flags().StringVarP(&language, "language", "l", "english", "Languages to use for AI (e.g. 'English', 'Spanish', 'French', 'German', 'Italian', 'Portuguese', 'Dutch', 'Russian', 'Chinese', 'Japanese', 'Korean')")
+	// no cache flag
+	AskCmd.Flags().BoolVarP(&nocache, "no-cache", "c", false, "Do not use cached data")
+	// anonymize flag
+	AskCmd.Flags().BoolVarP(&anonymize, "anonymize", "a", false, "Anonymize the text before sending it to the AI backend, masking the matches of the anonymize.patterns configuration")
+	// output flag
+	AskCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format (text, json)")
+	// add custom headers flag
+	AskCmd.Flags().StringSliceVarP(&customHeaders, "custom-headers", "r", []string{}, "Custom Headers, <key>:<value> (e.g CustomHeaderKey:CustomHeaderValue AnotherHeader:AnotherValue)")
+}
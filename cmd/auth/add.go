@@ -114,7 +114,14 @@ var addCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if ai.NeedPassword(backend) && password == "" {
+		parsedSafetySettings, err := ai.ParseSafetySettings(safetySettings)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+
+		// The token source replaces the key.
+		if ai.NeedPassword(backend) && password == "" && tokenFile == "" && tokenCommand == "" {
 			fmt.Printf("Enter %s Key: ", backend)
 			bytePassword, err := term.ReadPassword(int(syscall.Stdin))
 			if err != nil {
@@ -133,6 +140,7 @@ var addCmd = &cobra.Command{
 			BaseURL:        baseURL,
 			EndpointName:   endpointName,
 			Engine:         engine,
+			APIVersion:     apiVersion,
 			Temperature:    temperature,
 			ProviderRegion: providerRegion,
 			ProviderId:     providerId,
@@ -141,6 +149,9 @@ var addCmd = &cobra.Command{
 			TopK:           topK,
 			MaxTokens:      maxTokens,
 			OrganizationId: organizationId,
+			SafetySettings: parsedSafetySettings,
+			TokenFile:      tokenFile,
+			TokenCommand:   tokenCommand,
 		}
 
 		if providerIndex == -1 {
@@ -168,15 +179,17 @@ func init() {
 	// add flag for endpointName
 	addCmd.Flags().StringVarP(&endpointName, "endpointname", "n", "", "Endpoint Name, e.g. `endpoint-xxxxxxxxxxxx` (only for amazonbedrock, amazonsagemaker backends)")
 	// add flag for topP
-	addCmd.Flags().Float32VarP(&topP, "topp", "", 0.5, "Probability Cutoff: Set a threshold (0.0-1.0) to limit word choices. Higher values add randomness, lower values increase predictability.")
+	addCmd.Flags().Float32VarP(&topP, "topp", "", ai.DefaultTopP, "Probability Cutoff: Set a threshold (0.0-1.0) to limit word choices. Higher values add randomness, lower values increase predictability.")
 	// add flag for topK
-	addCmd.Flags().Int32VarP(&topK, "topk", "c", 50, "Sampling Cutoff: Set a threshold (1-100) to restrict the sampling process to the top K most probable words at each step. Higher values lead to greater variability, lower values increases predictability.")
+	addCmd.Flags().Int32VarP(&topK, "topk", "c", ai.DefaultTopK, "Sampling Cutoff: Set a threshold (1-100) to restrict the sampling process to the top K most probable words at each step. Higher values lead to greater variability, lower values increases predictability.")
 	// max tokens
-	addCmd.Flags().IntVarP(&maxTokens, "maxtokens", "l", 2048, "Specify a maximum output length. Adjust (1-...) to control text length. Higher values produce longer output, lower values limit length")
+	addCmd.Flags().IntVarP(&maxTokens, "maxtokens", "l", ai.DefaultMaxTokens, "Specify a maximum output length. Adjust (1-...) to control text length. Higher values produce longer output, lower values limit length")
 	// add flag for temperature
-	addCmd.Flags().Float32VarP(&temperature, "temperature", "t", 0.7, "The sampling temperature, value ranges between 0 ( output be more deterministic) and 1 (more random)")
+	addCmd.Flags().Float32VarP(&temperature, "temperature", "t", ai.DefaultTemperature, "The sampling temperature, value ranges between 0 ( output be more deterministic) and 1 (more random)")
 	// add flag for azure open ai engine/deployment name
 	addCmd.Flags().StringVarP(&engine, "engine", "e", "", "Azure AI deployment name (only for azureopenai backend)")
+	// add flag for azure open ai api version
+	addCmd.Flags().StringVarP(&apiVersion, "api-version", "", "", "Azure OpenAI API version, e.g. `2024-06-01` (only for azureopenai backend)")
 	//add flag for amazonbedrock region name
 	addCmd.Flags().StringVarP(&providerRegion, "providerRegion", "r", "", "Provider Region name (only for amazonbedrock, googlevertexai backend)")
 	//add flag for vertexAI/WatsonxAI Project ID
@@ -185,4 +198,10 @@ func init() {
 	addCmd.Flags().StringVarP(&compartmentId, "compartmentId", "k", "", "Compartment ID for generative AI model (only for oci backend)")
 	// add flag for openai organization
 	addCmd.Flags().StringVarP(&organizationId, "organizationId", "o", "", "OpenAI or AzureOpenAI Organization ID (only for openai and azureopenai backend)")
+	// add flag for provider-side content moderation overrides
+	addCmd.Flags().StringSliceVarP(&safetySettings, "safety-settings", "", []string{}, "Opt-in content moderation overrides, <category>=<threshold> (e.g. dangerous_content=none). Provider-dependent, ignored by backends without moderation controls (only for google, googlevertexai backend)")
+	// add flag for the file of a rotating bearer token
+	addCmd.Flags().StringVarP(&tokenFile, "token-file", "", "", "File read for the bearer token of each request, e.g. a projected service account token (takes precedence over --token-command)")
+	// add flag for the command printing a rotating bearer token
+	addCmd.Flags().StringVarP(&tokenCommand, "token-command", "", "", "Shell command run for the bearer token of each request, e.g. `gcloud auth print-identity-token`")
 }
@@ -25,6 +25,7 @@ var (
 	endpointName   string
 	model          string
 	engine         string
+	apiVersion     string
 	temperature    float32
 	providerRegion string
 	providerId     string
@@ -33,6 +34,9 @@ var (
 	topK           int32
 	maxTokens      int
 	organizationId string
+	safetySettings []string
+	tokenFile      string
+	tokenCommand   string
 )
 
 var configAI ai.AIConfiguration
@@ -50,7 +50,9 @@ var removeCmd = &cobra.Command{
 					foundBackend = true
 					configAI.Providers = append(configAI.Providers[:i], configAI.Providers[i+1:]...)
 					if configAI.DefaultProvider == b {
-						configAI.DefaultProvider = "openai"
+						// Falls back to openai, without naming a
+						// provider which may not be configured.
+						configAI.DefaultProvider = ""
 					}
 					color.Green("%s deleted from the AI backend provider list", b)
 					break
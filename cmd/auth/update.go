@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -53,10 +54,24 @@ var updateCmd = &cobra.Command{
 
 		backend, _ := cmd.Flags().GetString("backend")
 
+		parsedSafetySettings, err := ai.ParseSafetySettings(safetySettings)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+
 		if temperature > 1.0 || temperature < 0.0 {
 			color.Red("Error: temperature ranges from 0 to 1.")
 			os.Exit(1)
 		}
+		if topP > 1.0 || topP < 0.0 {
+			color.Red("Error: topP ranges from 0 to 1.")
+			os.Exit(1)
+		}
+		if maxTokens < 0 {
+			color.Red("Error: maxtokens must not be negative.")
+			os.Exit(1)
+		}
 
 		foundBackend := false
 		for i, provider := range configAI.Providers {
@@ -81,11 +96,31 @@ var updateCmd = &cobra.Command{
 				if engine != "" {
 					configAI.Providers[i].Engine = engine
 				}
+				if apiVersion != "" {
+					configAI.Providers[i].APIVersion = apiVersion
+					color.Blue("API version updated successfully")
+				}
 				if organizationId != "" {
 					configAI.Providers[i].OrganizationId = organizationId
 					color.Blue("Organization Id updated successfully")
 				}
-				configAI.Providers[i].Temperature = temperature
+				if len(parsedSafetySettings) > 0 {
+					configAI.Providers[i].SafetySettings = parsedSafetySettings
+					color.Blue("Safety settings updated successfully")
+				}
+				// The generation parameters are only updated when given.
+				if cmd.Flags().Changed("temperature") {
+					configAI.Providers[i].Temperature = temperature
+					color.Blue("Temperature updated successfully")
+				}
+				if cmd.Flags().Changed("topp") {
+					configAI.Providers[i].TopP = topP
+					color.Blue("TopP updated successfully")
+				}
+				if cmd.Flags().Changed("maxtokens") {
+					configAI.Providers[i].MaxTokens = maxTokens
+					color.Blue("Max tokens updated successfully")
+				}
 				color.Green("%s updated in the AI backend provider list", backend)
 			}
 		}
@@ -112,9 +147,17 @@ func init() {
 	// update flag for url
 	updateCmd.Flags().StringVarP(&baseURL, "baseurl", "u", "", "Update URL AI provider, (e.g `http://localhost:8080/v1`)")
 	// add flag for temperature
-	updateCmd.Flags().Float32VarP(&temperature, "temperature", "t", 0.7, "The sampling temperature, value ranges between 0 ( output be more deterministic) and 1 (more random)")
+	updateCmd.Flags().Float32VarP(&temperature, "temperature", "t", ai.DefaultTemperature, "The sampling temperature, value ranges between 0 ( output be more deterministic) and 1 (more random)")
+	// update flag for topP
+	updateCmd.Flags().Float32VarP(&topP, "topp", "", ai.DefaultTopP, "Update the probability cutoff (0.0-1.0) limiting word choices")
+	// update flag for max tokens
+	updateCmd.Flags().IntVarP(&maxTokens, "maxtokens", "l", ai.DefaultMaxTokens, "Update the maximum output length, in tokens")
 	// update flag for azure open ai engine/deployment name
 	updateCmd.Flags().StringVarP(&engine, "engine", "e", "", "Update Azure AI deployment name")
+	// update flag for azure open ai api version
+	updateCmd.Flags().StringVarP(&apiVersion, "api-version", "", "", "Update Azure OpenAI API version")
 	// update flag for organizationId
 	updateCmd.Flags().StringVarP(&organizationId, "organizationId", "o", "", "Update OpenAI or Azure organization Id")
+	// update flag for provider-side content moderation overrides
+	updateCmd.Flags().StringSliceVarP(&safetySettings, "safety-settings", "", []string{}, "Update content moderation overrides, <category>=<threshold> (only for google, googlevertexai backend)")
 }
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
@@ -33,6 +34,10 @@ var (
 	projectId      string
 	endpoint       string
 	insecure       bool
+	redisPassword  string
+	redisDB        int
+	redisPrefix    string
+	ttl            string
 )
 
 // addCmd represents the add command
@@ -44,7 +49,8 @@ var addCmd = &cobra.Command{
 	- Azure Blob storage (e.g., k8sgpt cache add azure)
 	- Google Cloud storage (e.g., k8sgpt cache add gcs)
 	- S3 (e.g., k8sgpt cache add s3)
-	- Interplex (e.g., k8sgpt cache add interplex)`,
+	- Interplex (e.g., k8sgpt cache add interplex)
+	- Redis (e.g., k8sgpt cache add redis --endpoint redis://localhost:6379/0)`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			color.Red("Error: Please provide a value for cache types. Run k8sgpt cache add --help")
@@ -52,11 +58,29 @@ var addCmd = &cobra.Command{
 		}
 		fmt.Println(color.YellowString("Adding remote based cache"))
 		cacheType := args[0]
-		remoteCache, err := cache.NewCacheProvider(strings.ToLower(cacheType), bucketName, region, endpoint, storageAccount, containerName, projectId, insecure)
+		var remoteCache cache.CacheProvider
+		var err error
+		if strings.ToLower(cacheType) == "redis" {
+			remoteCache, err = cache.NewRedisCacheProvider(cache.RedisCacheConfiguration{
+				URL:      endpoint,
+				Password: redisPassword,
+				DB:       redisDB,
+				Prefix:   redisPrefix,
+			})
+		} else {
+			remoteCache, err = cache.NewCacheProvider(strings.ToLower(cacheType), bucketName, region, endpoint, storageAccount, containerName, projectId, insecure)
+		}
 		if err != nil {
 			color.Red("Error: %v", err)
 			os.Exit(1)
 		}
+		if ttl != "" {
+			if duration, err := time.ParseDuration(ttl); err != nil || duration < 0 {
+				color.Red("Error: invalid cache TTL %q", ttl)
+				os.Exit(1)
+			}
+			remoteCache.TTL = ttl
+		}
 		err = cache.AddRemoteCache(remoteCache)
 		if err != nil {
 			color.Red("Error: %v", err)
@@ -68,12 +92,16 @@ var addCmd = &cobra.Command{
 func init() {
 	CacheCmd.AddCommand(addCmd)
 	addCmd.Flags().StringVarP(&region, "region", "r", "us-east-1", "The region to use for the AWS S3 or GCS cache")
-	addCmd.Flags().StringVarP(&endpoint, "endpoint", "e", "", "The S3 or minio endpoint")
+	addCmd.Flags().StringVarP(&endpoint, "endpoint", "e", "", "The S3 or minio endpoint, the Interplex connection string or the Redis URL")
 	addCmd.Flags().BoolVarP(&insecure, "insecure", "i", false, "Skip TLS verification for S3/Minio custom endpoint")
 	addCmd.Flags().StringVarP(&bucketName, "bucket", "b", "", "The name of the AWS S3 bucket to use for the cache")
 	addCmd.Flags().StringVarP(&projectId, "projectid", "p", "", "The GCP project ID")
 	addCmd.Flags().StringVarP(&storageAccount, "storageacc", "s", "", "The Azure storage account name of the container")
 	addCmd.Flags().StringVarP(&containerName, "container", "c", "", "The Azure container name to use for the cache")
+	addCmd.Flags().StringVar(&redisPassword, "password", "", "The Redis password, if not part of the URL")
+	addCmd.Flags().IntVar(&redisDB, "db", 0, "The Redis database index, overrides the one of the URL")
+	addCmd.Flags().StringVar(&redisPrefix, "prefix", "", "The prefix of the Redis keys (default \"k8sgpt:\")")
+	addCmd.Flags().StringVar(&ttl, "ttl", "", "The duration after which cached explanations expire, e.g. 24h, sets cache.ttl")
 	addCmd.MarkFlagsRequiredTogether("storageacc", "container")
 	// Tedious check to ensure we don't include arguments from different providers
 	addCmd.MarkFlagsMutuallyExclusive("region", "storageacc")
@@ -19,6 +19,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/custom"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -34,6 +35,12 @@ var addCmd = &cobra.Command{
 		coreFilters, additionalFilters, integrationFilters := analyzer.ListFilters()
 
 		availableFilters := append(append(coreFilters, additionalFilters...), integrationFilters...)
+		// Custom analyzers are selected by their name.
+		var customAnalyzers []custom.CustomAnalyzer
+		_ = viper.UnmarshalKey("custom_analyzers", &customAnalyzers)
+		for _, cAnalyzer := range customAnalyzers {
+			availableFilters = append(availableFilters, cAnalyzer.Name)
+		}
 		// Verify filter exist
 		invalidFilters := []string{}
 		for _, f := range inputFilters {
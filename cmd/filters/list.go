@@ -14,58 +14,91 @@ limitations under the License.
 package filters
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"slices"
 
 	"github.com/fatih/color"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/integration"
-	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+var listOutput string
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available filters",
 	Long:  `The list command displays a list of available filters that can be used to analyze Kubernetes resources.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		activeFilters := viper.GetStringSlice("active_filters")
-		coreFilters, additionalFilters, integrationFilters := analyzer.ListFilters()
-		integration := integration.NewIntegration()
-		availableFilters := append(append(coreFilters, additionalFilters...), integrationFilters...)
+		filters := analyzer.DescribeFilters()
 
-		if len(activeFilters) == 0 {
-			activeFilters = coreFilters
+		switch listOutput {
+		case "json":
+			output, err := json.MarshalIndent(filters, "", "  ")
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(output))
+			return
+		case "text":
+		default:
+			color.Red("Error: output format %s is not supported, use text or json", listOutput)
+			os.Exit(1)
 		}
-		inactiveFilters := util.SliceDiff(availableFilters, activeFilters)
+
 		fmt.Print(color.YellowString("Active: \n"))
-		for _, filter := range activeFilters {
-			// if the filter is an integration, mark this differently
-			// but if the integration is inactive, remove
-			if slices.Contains(integrationFilters, filter) {
-				fmt.Printf("> %s\n", color.BlueString("%s (integration)", filter))
-			} else {
-				// This strange bit of logic will loop through every integration via
-				// OwnsAnalyzer subcommand to check the filter and as the integrationFilters...
-				// was no match, we know this isn't part of an active integration
-				if _, err := integration.AnalyzerByIntegration(filter); err != nil {
-					fmt.Printf("> %s\n", color.GreenString(filter))
-				}
+		for _, filter := range filters {
+			if filter.Active {
+				fmt.Printf("> %s\n", filterLine(filter, color.GreenString))
+			}
+		}
+		// The active filters which no longer exist, e.g. of a removed custom
+		// analyzer, are skipped by the analysis. Those of the inactive
+		// integrations aren't shown.
+		integration := integration.NewIntegration()
+		for _, name := range viper.GetStringSlice("active_filters") {
+			if slices.ContainsFunc(filters, func(filter analyzer.Filter) bool { return filter.Name == name }) {
+				continue
+			}
+			if _, err := integration.AnalyzerByIntegration(name); err != nil {
+				fmt.Printf("> %s\n", color.RedString("%s (unknown)", name))
 			}
 		}
 
 		// display inactive filters
-		if len(inactiveFilters) != 0 {
-			fmt.Print(color.YellowString("Unused: \n"))
-			for _, filter := range inactiveFilters {
-				// if the filter is an integration, mark this differently
-				if slices.Contains(integrationFilters, filter) {
-					fmt.Printf("> %s\n", color.BlueString("%s (integration)", filter))
-				} else {
-					fmt.Printf("> %s\n", color.RedString(filter))
-				}
+		var unused bool
+		for _, filter := range filters {
+			if filter.Active {
+				continue
+			}
+			if !unused {
+				fmt.Print(color.YellowString("Unused: \n"))
+				unused = true
 			}
+			fmt.Printf("> %s\n", filterLine(filter, color.RedString))
 		}
 	},
 }
+
+// filterLine renders a filter with its source and description, the filters
+// of the integrations and the custom analyzers are marked differently.
+func filterLine(filter analyzer.Filter, colorString func(format string, a ...interface{}) string) string {
+	line := colorString(filter.Name)
+	switch filter.Source {
+	case analyzer.FilterSourceIntegration, analyzer.FilterSourceCustom:
+		line = color.BlueString("%s (%s)", filter.Name, filter.Source)
+	}
+	if filter.Description != "" {
+		line += " - " + filter.Description
+	}
+	return line
+}
+
+func init() {
+	// output flag
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "text", "Output format (text, json)")
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/adrg/xdg"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/analyze"
+	"github.com/k8sgpt-ai/k8sgpt/cmd/ask"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/auth"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/cache"
 	customanalyzer "github.com/k8sgpt-ai/k8sgpt/cmd/customAnalyzer"
@@ -27,6 +28,7 @@ import (
 	"github.com/k8sgpt-ai/k8sgpt/cmd/filters"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/generate"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/integration"
+	"github.com/k8sgpt-ai/k8sgpt/cmd/schema"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/serve"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
 	"github.com/spf13/cobra"
@@ -75,6 +77,7 @@ func init() {
 
 	rootCmd.AddCommand(auth.AuthCmd)
 	rootCmd.AddCommand(analyze.AnalyzeCmd)
+	rootCmd.AddCommand(ask.AskCmd)
 	rootCmd.AddCommand(dump.DumpCmd)
 	rootCmd.AddCommand(filters.FiltersCmd)
 	rootCmd.AddCommand(generate.GenerateCmd)
@@ -82,6 +85,7 @@ func init() {
 	rootCmd.AddCommand(serve.ServeCmd)
 	rootCmd.AddCommand(cache.CacheCmd)
 	rootCmd.AddCommand(customanalyzer.CustomAnalyzerCmd)
+	rootCmd.AddCommand(schema.SchemaCmd)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", fmt.Sprintf("Default config file (%s/k8sgpt/k8sgpt.yaml)", xdg.ConfigHome))
 	rootCmd.PersistentFlags().StringVar(&kubecontext, "kubecontext", "", "Kubernetes context to use. Only required if out-of-cluster.")
 	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
@@ -55,6 +55,10 @@ var ServeCmd = &cobra.Command{
 			color.Red("Error: %v", err)
 			os.Exit(1)
 		}
+		if err := configAI.Validate(); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
 		var aiProvider *ai.AIProvider
 		if len(configAI.Providers) == 0 {
 			// we validate and set temperature for our backend
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const anthropicClientName = "anthropic"
+
+const (
+	anthropicDefaultBaseURL = "https://api.anthropic.com"
+	anthropicDefaultModel   = "claude-3-5-sonnet-latest"
+	anthropicAPIVersion     = "2023-06-01"
+)
+
+// AnthropicClient talks to the Anthropic Messages API, which takes the system
+// instructions apart from the user message.
+type AnthropicClient struct {
+	nopCloser
+
+	client      *http.Client
+	baseURL     string
+	apiKey      string
+	model       string
+	temperature float32
+	topP        float32
+	maxTokens   int
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float32            `json:"temperature"`
+	TopP        float32            `json:"top_p,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+type anthropicError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *AnthropicClient) Configure(config IAIConfig) error {
+	c.baseURL = strings.TrimSuffix(config.GetBaseURL(), "/")
+	if c.baseURL == "" {
+		c.baseURL = anthropicDefaultBaseURL
+	}
+
+	transport, err := httpTransport(config)
+	if err != nil {
+		return err
+	}
+	c.client = &http.Client{Transport: transport}
+
+	c.apiKey = config.GetPassword()
+	c.model = config.GetModel()
+	if c.model == "" {
+		c.model = anthropicDefaultModel
+	}
+	c.temperature = config.GetTemperature()
+	c.topP = config.GetTopP()
+	c.maxTokens = config.GetMaxTokens()
+	if c.maxTokens <= 0 {
+		c.maxTokens = maxToken
+	}
+	return nil
+}
+
+func (c *AnthropicClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	response, _, err := c.GetCompletionWithUsage(ctx, prompt)
+	return response, err
+}
+
+func (c *AnthropicClient) GetCompletionWithUsage(ctx context.Context, prompt string) (string, TokenUsage, error) {
+	return c.GetCompletionWithSystem(ctx, "", prompt)
+}
+
+func (c *AnthropicClient) GetCompletionWithSystem(ctx context.Context, system string, prompt string) (string, TokenUsage, error) {
+	requestBody, err := json.Marshal(anthropicRequest{
+		Model:       c.model,
+		System:      system,
+		Messages:    []anthropicMessage{{Role: "user", Content: prompt}},
+		MaxTokens:   c.maxTokens,
+		Temperature: c.temperature,
+		TopP:        c.topP,
+	})
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Api-Key", c.apiKey)
+	request.Header.Set("Anthropic-Version", anthropicAPIVersion)
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", TokenUsage{}, fmt.Errorf("could not read response body: %w", err)
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		message := string(responseBody)
+		var apiError anthropicError
+		if json.Unmarshal(responseBody, &apiError) == nil && apiError.Error.Message != "" {
+			message = apiError.Error.Message
+		}
+		// The status code is reported like the other clients do, so that the
+		// rate limited requests are retried.
+		return "", TokenUsage{}, fmt.Errorf("error, status code: %d, message: %s", response.StatusCode, message)
+	}
+
+	var result anthropicResponse
+	if err := json.Unmarshal(responseBody, &result); err != nil {
+		return "", TokenUsage{}, err
+	}
+	var text strings.Builder
+	for _, content := range result.Content {
+		if content.Type == "text" {
+			text.WriteString(content.Text)
+		}
+	}
+	return text.String(), TokenUsage{
+		PromptTokens:     result.Usage.InputTokens,
+		CompletionTokens: result.Usage.OutputTokens,
+	}, nil
+}
+
+func (c *AnthropicClient) GetName() string {
+	return anthropicClientName
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newAnthropicTestClient(t *testing.T, handler http.HandlerFunc) *AnthropicClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := &AnthropicClient{}
+	require.NoError(t, client.Configure(&AIProvider{Password: "key", BaseURL: server.URL}))
+	return client
+}
+
+func TestAnthropicClient_GetCompletionWithSystem(t *testing.T) {
+	var got anthropicRequest
+	client := newAnthropicTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/messages", r.URL.Path)
+		require.Equal(t, "key", r.Header.Get("X-Api-Key"))
+		require.Equal(t, anthropicAPIVersion, r.Header.Get("Anthropic-Version"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"Error: crash"}],"usage":{"input_tokens":12,"output_tokens":3}}`))
+	})
+
+	response, usage, err := GetCompletionWithSystem(context.Background(), client, "Explain in english.\n---user---\ncrash loop")
+	require.NoError(t, err)
+	require.Equal(t, "Error: crash", response)
+	require.Equal(t, TokenUsage{PromptTokens: 12, CompletionTokens: 3}, usage)
+	require.Equal(t, anthropicDefaultModel, got.Model)
+	require.Equal(t, maxToken, got.MaxTokens)
+	require.Equal(t, "Explain in english.", got.System)
+	require.Equal(t, []anthropicMessage{{Role: "user", Content: "crash loop"}}, got.Messages)
+}
+
+func TestAnthropicClient_Error(t *testing.T) {
+	client := newAnthropicTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`))
+	})
+
+	_, err := client.GetCompletion(context.Background(), "crash loop")
+	require.EqualError(t, err, "error, status code: 429, message: slow down")
+}
+
+func TestGetCompletionWithSystem_NotSupported(t *testing.T) {
+	// The clients taking no system instructions get the joined prompt.
+	response, usage, err := GetCompletionWithSystem(context.Background(), &NoOpAIClient{}, "Explain.\n\t---user---\n\tcrash loop")
+	require.NoError(t, err)
+	require.Equal(t, "I am a noop response to the prompt Explain.\ncrash loop", response)
+	require.True(t, usage.Estimated)
+}
+
+func TestSplitSystemPrompt(t *testing.T) {
+	system, user := SplitSystemPrompt("Explain.\n---user---\ncrash\n---user---\nloop")
+	require.Equal(t, "Explain.", system)
+	require.Equal(t, "crash\n---user---\nloop", user)
+
+	system, user = SplitSystemPrompt("Explain crash loop")
+	require.Empty(t, system)
+	require.Equal(t, "Explain crash loop", user)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"net/url"
 
 	"github.com/sashabaranov/go-openai"
 )
@@ -17,6 +16,8 @@ type AzureAIClient struct {
 	client      *openai.Client
 	model       string
 	temperature float32
+	topP        float32
+	maxTokens   int
 	// organizationId string
 }
 
@@ -24,9 +25,11 @@ func (c *AzureAIClient) Configure(config IAIConfig) error {
 	token := config.GetPassword()
 	baseURL := config.GetBaseURL()
 	engine := config.GetEngine()
-	proxyEndpoint := config.GetProxyEndpoint()
 	defaultConfig := openai.DefaultAzureConfig(token, baseURL)
 	orgId := config.GetOrganizationId()
+	if apiVersion := config.GetAPIVersion(); apiVersion != "" {
+		defaultConfig.APIVersion = apiVersion
+	}
 
 	defaultConfig.AzureModelMapperFunc = func(model string) string {
 		// If you use a deployment name different from the model name, you can customize the AzureModelMapperFunc function
@@ -37,18 +40,15 @@ func (c *AzureAIClient) Configure(config IAIConfig) error {
 
 	}
 
-	if proxyEndpoint != "" {
-		proxyUrl, err := url.Parse(proxyEndpoint)
-		if err != nil {
-			return err
-		}
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyUrl),
-		}
-
-		defaultConfig.HTTPClient = &http.Client{
-			Transport: transport,
-		}
+	transport, err := httpTransport(config)
+	if err != nil {
+		return err
+	}
+	defaultConfig.HTTPClient = &http.Client{
+		Transport: &OpenAIHeaderTransport{
+			Origin:  transport,
+			Headers: config.GetCustomHeaders(),
+		},
 	}
 	if orgId != "" {
 		defaultConfig.OrgID = orgId
@@ -60,11 +60,24 @@ func (c *AzureAIClient) Configure(config IAIConfig) error {
 	}
 	c.client = client
 	c.model = config.GetModel()
-	c.temperature = config.GetTemperature()
+	c.temperature = openAITemperature(config)
+	// Only sent when set, as the requests didn't have them before.
+	c.topP, c.maxTokens = 0, 0
+	if generationSet(config, GenerationTopP) {
+		c.topP = config.GetTopP()
+	}
+	if generationSet(config, GenerationMaxTokens) {
+		c.maxTokens = config.GetMaxTokens()
+	}
 	return nil
 }
 
 func (c *AzureAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	response, _, err := c.GetCompletionWithUsage(ctx, prompt)
+	return response, err
+}
+
+func (c *AzureAIClient) GetCompletionWithUsage(ctx context.Context, prompt string) (string, TokenUsage, error) {
 	// Create a completion request
 	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
 		Model: c.model,
@@ -75,11 +88,16 @@ func (c *AzureAIClient) GetCompletion(ctx context.Context, prompt string) (strin
 			},
 		},
 		Temperature: c.temperature,
+		TopP:        c.topP,
+		MaxTokens:   c.maxTokens,
 	})
 	if err != nil {
-		return "", err
+		return "", TokenUsage{}, err
 	}
-	return resp.Choices[0].Message.Content, nil
+	return resp.Choices[0].Message.Content, TokenUsage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}, nil
 }
 
 func (c *AzureAIClient) GetName() string {
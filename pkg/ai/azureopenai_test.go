@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureAIClient_Request(t *testing.T) {
+	var request *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "test"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("azureopenai")
+	require.IsType(t, &AzureAIClient{}, client)
+	err := client.Configure(&AIProvider{
+		Name:          "azureopenai",
+		Model:         "gpt-4o",
+		Password:      "secret",
+		BaseURL:       server.URL,
+		Engine:        "k8sgpt-deployment",
+		APIVersion:    "2024-06-01",
+		CustomHeaders: []http.Header{{"X-Custom-Header": []string{"Value"}}},
+	})
+	require.NoError(t, err)
+
+	response, err := client.GetCompletion(context.Background(), "foo prompt")
+	require.NoError(t, err)
+	assert.Equal(t, "test", response)
+
+	require.NotNil(t, request)
+	assert.Equal(t, "/openai/deployments/k8sgpt-deployment/chat/completions", request.URL.Path)
+	assert.Equal(t, "2024-06-01", request.URL.Query().Get("api-version"))
+	assert.Equal(t, "secret", request.Header.Get("api-key"))
+	assert.Equal(t, "Value", request.Header.Get("X-Custom-Header"))
+}
+
+// Test: the generation parameters set are sent, a zero temperature set for the
+// run included, and the unset or default ones aren't
+func TestAzureAIClient_GenerationParameters(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "test"}}]}`))
+	}))
+	defer server.Close()
+
+	complete := func(provider *AIProvider) {
+		client := NewClient("azureopenai")
+		require.NoError(t, client.Configure(provider))
+		_, err := client.GetCompletion(context.Background(), "foo prompt")
+		require.NoError(t, err)
+	}
+
+	complete(&AIProvider{
+		Name:                "azureopenai",
+		Model:               "gpt-4o",
+		BaseURL:             server.URL,
+		Engine:              "k8sgpt-deployment",
+		TopP:                0.9,
+		MaxTokens:           512,
+		GenerationOverrides: []string{GenerationTemperature},
+	})
+	require.Contains(t, body, "temperature")
+	assert.InDelta(t, 0, body["temperature"], 1e-6)
+	assert.InDelta(t, 0.9, body["top_p"], 1e-6)
+	assert.Equal(t, float64(512), body["max_tokens"])
+
+	// As added by k8sgpt auth add.
+	complete(&AIProvider{
+		Name:      "azureopenai",
+		Model:     "gpt-4o",
+		BaseURL:   server.URL,
+		Engine:    "k8sgpt-deployment",
+		TopP:      DefaultTopP,
+		MaxTokens: DefaultMaxTokens,
+	})
+	require.NotContains(t, body, "temperature")
+	require.NotContains(t, body, "top_p")
+	require.NotContains(t, body, "max_tokens")
+}
@@ -16,6 +16,7 @@ package ai
 import (
 	"context"
 	"errors"
+	"net/http"
 
 	api "github.com/cohere-ai/cohere-go/v2"
 	cohere "github.com/cohere-ai/cohere-go/v2/client"
@@ -45,6 +46,12 @@ func (c *CohereClient) Configure(config IAIConfig) error {
 		opts = append(opts, cohere.WithBaseURL(baseURL))
 	}
 
+	transport, err := httpTransport(config)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, option.WithHTTPClient(&http.Client{Transport: transport}))
+
 	client := cohere.NewClient(opts...)
 	if client == nil {
 		return errors.New("error creating Cohere client")
@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"math"
+)
+
+type confidenceKey struct{}
+
+// WithConfidence asks the clients able to, currently openai, to report their
+// confidence in the completions made with ctx as TokenUsage.Confidence. It's
+// opt-in since it may need more data from the backend, e.g. log-probs.
+func WithConfidence(ctx context.Context) context.Context {
+	return context.WithValue(ctx, confidenceKey{}, true)
+}
+
+func confidenceRequested(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	requested, _ := ctx.Value(confidenceKey{}).(bool)
+	return requested
+}
+
+// confidenceFromLogProbs is the geometric mean of the probabilities of the
+// tokens of a completion, nil without any token.
+func confidenceFromLogProbs(logProbs []float64) *float64 {
+	if len(logProbs) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, logProb := range logProbs {
+		sum += logProb
+	}
+	confidence := math.Exp(sum / float64(len(logProbs)))
+	return &confidence
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIClient_Confidence(t *testing.T) {
+	var logProbsRequested []bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			LogProbs bool `json:"logprobs"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		logProbsRequested = append(logProbsRequested, request.LogProbs)
+		if !request.LogProbs {
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"Error: crash"}}],"usage":{"prompt_tokens":12,"completion_tokens":2}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"Error: crash"},"logprobs":{"content":[{"token":"Error","logprob":-0.1},{"token":": crash","logprob":-0.3}]}}],"usage":{"prompt_tokens":12,"completion_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	client := &OpenAIClient{}
+	require.NoError(t, client.Configure(&mockConfig{baseURL: server.URL}))
+
+	_, usage, err := GetCompletionWithUsage(WithConfidence(context.Background()), client, "crash loop")
+	require.NoError(t, err)
+	require.NotNil(t, usage.Confidence)
+	require.InDelta(t, math.Exp(-0.2), *usage.Confidence, 1e-9)
+
+	// Without a request, no log-probs are asked for and no confidence is set.
+	_, usage, err = GetCompletionWithUsage(context.Background(), client, "crash loop")
+	require.NoError(t, err)
+	require.Nil(t, usage.Confidence)
+	require.Equal(t, []bool{true, false}, logProbsRequested)
+}
@@ -23,6 +23,7 @@ type CustomRestClient struct {
 	temperature float32
 	topP        float32
 	topK        int32
+	language    string
 }
 
 type CustomRestRequest struct {
@@ -59,20 +60,12 @@ func (c *CustomRestClient) Configure(config IAIConfig) error {
 	}
 	c.base = baseClientURL
 
-	proxyEndpoint := config.GetProxyEndpoint()
-	c.client = http.DefaultClient
-	if proxyEndpoint != "" {
-		proxyUrl, err := url.Parse(proxyEndpoint)
-		if err != nil {
-			return err
-		}
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyUrl),
-		}
-
-		c.client = &http.Client{
-			Transport: transport,
-		}
+	transport, err := httpTransport(config)
+	if err != nil {
+		return err
+	}
+	c.client = &http.Client{
+		Transport: transport,
 	}
 
 	c.model = config.GetModel()
@@ -95,6 +88,10 @@ func (c *CustomRestClient) GetCompletion(ctx context.Context, prompt string) (st
 	if err := json.Unmarshal([]byte(prompt), &promptDetail); err != nil {
 		return "", err
 	}
+	// The raw prompt may be customized without the language.
+	if c.language != "" {
+		promptDetail.Language = c.language
+	}
 	generateRequest := &CustomRestRequest{
 		Model:  c.model,
 		Prompt: promptDetail.Prompt,
@@ -142,6 +139,11 @@ func (c *CustomRestClient) GetCompletion(ctx context.Context, prompt string) (st
 	return result.Response, nil
 }
 
+// SetLanguage sets the language option of the requests, see IAILanguageSetter.
+func (c *CustomRestClient) SetLanguage(language string) {
+	c.language = language
+}
+
 func (c *CustomRestClient) GetName() string {
 	return CustomRestClientName
 }
@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test: the language set on the client is sent as an option, even when the
+// raw prompt has none
+func TestCustomRestClient_Language(t *testing.T) {
+	var request CustomRestRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		_, _ = w.Write([]byte(`{"response": "test"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(CustomRestClientName)
+	require.NoError(t, client.Configure(&AIProvider{Name: CustomRestClientName, BaseURL: server.URL}))
+	SetLanguage(client, "french")
+
+	response, err := client.GetCompletion(context.Background(), `{"message": "pod failure", "prompt": "explain"}`)
+	require.NoError(t, err)
+	require.Equal(t, "test", response)
+	require.Equal(t, "french", request.Options["language"])
+	require.Equal(t, "explain", request.Prompt)
+}
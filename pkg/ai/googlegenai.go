@@ -17,10 +17,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
 )
 
 const googleAIClientName = "google"
@@ -33,6 +39,40 @@ type GoogleGenAIClient struct {
 	topP        float32
 	topK        int32
 	maxTokens   int
+
+	safetySettings []*genai.SafetySetting
+}
+
+var (
+	googleSafetyCategories = map[string]genai.HarmCategory{
+		SafetyCategoryHarassment:       genai.HarmCategoryHarassment,
+		SafetyCategoryHateSpeech:       genai.HarmCategoryHateSpeech,
+		SafetyCategorySexuallyExplicit: genai.HarmCategorySexuallyExplicit,
+		SafetyCategoryDangerousContent: genai.HarmCategoryDangerousContent,
+	}
+	googleSafetyThresholds = map[string]genai.HarmBlockThreshold{
+		SafetyThresholdNone:           genai.HarmBlockNone,
+		SafetyThresholdOnlyHigh:       genai.HarmBlockOnlyHigh,
+		SafetyThresholdMediumAndAbove: genai.HarmBlockMediumAndAbove,
+		SafetyThresholdLowAndAbove:    genai.HarmBlockLowAndAbove,
+	}
+)
+
+// googleSafetySettings converts the provider independent safety settings into genai ones.
+func googleSafetySettings(settings map[string]string) ([]*genai.SafetySetting, error) {
+	var out []*genai.SafetySetting
+	for _, category := range sortedSafetyCategories(settings) {
+		c, ok := googleSafetyCategories[category]
+		if !ok {
+			return nil, fmt.Errorf("unsupported safety category %q", category)
+		}
+		t, ok := googleSafetyThresholds[settings[category]]
+		if !ok {
+			return nil, fmt.Errorf("unsupported safety threshold %q for category %q", settings[category], category)
+		}
+		out = append(out, &genai.SafetySetting{Category: c, Threshold: t})
+	}
+	return out, nil
 }
 
 func (c *GoogleGenAIClient) Configure(config IAIConfig) error {
@@ -41,11 +81,37 @@ func (c *GoogleGenAIClient) Configure(config IAIConfig) error {
 	// Access your API key as an environment variable (see "Set up your API key" above)
 	token := config.GetPassword()
 	authOption := option.WithAPIKey(token)
-	if token[0] == '{' {
+	if strings.HasPrefix(token, "{") {
 		authOption = option.WithCredentialsJSON([]byte(token))
 	}
+	opts := []option.ClientOption{authOption}
+	if baseURL := config.GetBaseURL(); baseURL != "" {
+		opts = append(opts, option.WithEndpoint(baseURL))
+	}
+	customHeaders := config.GetCustomHeaders()
+	if len(customHeaders) > 0 || config.GetTransport() != nil || config.GetProxyEndpoint() != "" || config.GetTokenSource() != nil {
+		origin, err := httpTransport(config)
+		if err != nil {
+			return err
+		}
+		// A custom HTTP client replaces the authentication of the SDK, so the
+		// headers are added under an authenticated transport.
+		transport, err := htransport.NewTransport(ctx, &OpenAIHeaderTransport{
+			Origin:  origin,
+			Headers: customHeaders,
+		}, authOption, option.WithScopes(googleAIScopes...))
+		if err != nil {
+			return fmt.Errorf("creating genai Google SDK transport: %w", err)
+		}
+		opts = append(opts, option.WithHTTPClient(&http.Client{Transport: transport}))
+	}
 
-	client, err := genai.NewClient(ctx, authOption)
+	safetySettings, err := googleSafetySettings(config.GetSafetySettings())
+	if err != nil {
+		return err
+	}
+
+	client, err := genai.NewClient(ctx, opts...)
 	if err != nil {
 		return fmt.Errorf("creating genai Google SDK client: %w", err)
 	}
@@ -56,6 +122,7 @@ func (c *GoogleGenAIClient) Configure(config IAIConfig) error {
 	c.topP = config.GetTopP()
 	c.topK = config.GetTopK()
 	c.maxTokens = config.GetMaxTokens()
+	c.safetySettings = safetySettings
 	return nil
 }
 
@@ -66,12 +133,15 @@ func (c *GoogleGenAIClient) GetCompletion(ctx context.Context, prompt string) (s
 	model.SetTopP(c.topP)
 	model.SetTopK(c.topK)
 	model.SetMaxOutputTokens(int32(c.maxTokens))
+	if len(c.safetySettings) > 0 {
+		model.SafetySettings = c.safetySettings
+	}
 
 	// Google AI SDK is capable of different inputs than just text, for now set explicit text prompt type.
 	// Similarly, we could stream the response. For now k8sgpt does not support streaming.
 	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
 	if err != nil {
-		return "", err
+		return "", googleAIError(err)
 	}
 
 	if len(resp.Candidates) == 0 {
@@ -111,6 +181,56 @@ func (c *GoogleGenAIClient) GetCompletion(ctx context.Context, prompt string) (s
 	return output, nil
 }
 
+// googleAIScopes authorize the service account credentials when the SDK
+// transport is replaced.
+var googleAIScopes = []string{
+	"https://www.googleapis.com/auth/cloud-platform",
+	"https://www.googleapis.com/auth/generative-language",
+}
+
+// googleAPIError reports the status code of the Gemini API errors like the
+// other clients do, so that the requests exceeding the quota (429) are retried.
+type googleAPIError struct {
+	err *googleapi.Error
+}
+
+// googleAIError converts the Gemini API errors into googleAPIErrors, other
+// errors are returned unchanged.
+func googleAIError(err error) error {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	return &googleAPIError{err: apiErr}
+}
+
+func (e *googleAPIError) Error() string {
+	return fmt.Sprintf("error, status code: %d, message: %s", e.err.Code, e.err.Message)
+}
+
+func (e *googleAPIError) Unwrap() error {
+	return e.err
+}
+
+// RetryAfter is the delay requested by the Retry-After header, or else by the
+// RetryInfo detail Gemini adds when the quota is exhausted.
+func (e *googleAPIError) RetryAfter() (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(e.err.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	for _, detail := range e.err.Details {
+		info, ok := detail.(map[string]interface{})
+		if !ok || info["@type"] != "type.googleapis.com/google.rpc.RetryInfo" {
+			continue
+		}
+		delay, _ := info["retryDelay"].(string)
+		if duration, err := time.ParseDuration(delay); err == nil {
+			return duration, true
+		}
+	}
+	return 0, false
+}
+
 func (c *GoogleGenAIClient) GetName() string {
 	return googleAIClientName
 }
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newGoogleTestClient(t *testing.T, handler http.HandlerFunc) *GoogleGenAIClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := &GoogleGenAIClient{}
+	require.NoError(t, client.Configure(&AIProvider{
+		Password:      "key",
+		BaseURL:       server.URL,
+		Model:         "gemini-1.5-flash",
+		CustomHeaders: []http.Header{{"X-Team": []string{"platform"}}},
+	}))
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestGoogleGenAIClient_GetCompletion(t *testing.T) {
+	var request struct {
+		Contents []struct {
+			Role  string `json:"role"`
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"contents"`
+	}
+	client := newGoogleTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1beta/models/gemini-1.5-flash:generateContent", r.URL.Path)
+		require.Equal(t, "key", r.URL.Query().Get("key"))
+		require.Equal(t, "platform", r.Header.Get("X-Team"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Error: crash"}]}}]}`))
+	})
+
+	response, err := client.GetCompletion(context.Background(), "crash loop")
+	require.NoError(t, err)
+	require.Equal(t, "Error: crash\n", response)
+	require.Len(t, request.Contents, 1)
+	require.Equal(t, "user", request.Contents[0].Role)
+	require.Equal(t, "crash loop", request.Contents[0].Parts[0].Text)
+}
+
+func TestGoogleGenAIClient_QuotaExceeded(t *testing.T) {
+	client := newGoogleTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED",` +
+			`"details":[{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"39s"}]}}`))
+	})
+
+	_, err := client.GetCompletion(context.Background(), "crash loop")
+	require.EqualError(t, err, "error, status code: 429, message: Resource has been exhausted")
+	var retryErr interface{ RetryAfter() (time.Duration, bool) }
+	require.ErrorAs(t, err, &retryErr)
+	delay, ok := retryErr.RetryAfter()
+	require.True(t, ok)
+	require.Equal(t, 39*time.Second, delay)
+}
@@ -32,6 +32,40 @@ type GoogleVertexAIClient struct {
 	topP        float32
 	topK        int32
 	maxTokens   int
+
+	safetySettings []*genai.SafetySetting
+}
+
+var (
+	vertexAISafetyCategories = map[string]genai.HarmCategory{
+		SafetyCategoryHarassment:       genai.HarmCategoryHarassment,
+		SafetyCategoryHateSpeech:       genai.HarmCategoryHateSpeech,
+		SafetyCategorySexuallyExplicit: genai.HarmCategorySexuallyExplicit,
+		SafetyCategoryDangerousContent: genai.HarmCategoryDangerousContent,
+	}
+	vertexAISafetyThresholds = map[string]genai.HarmBlockThreshold{
+		SafetyThresholdNone:           genai.HarmBlockNone,
+		SafetyThresholdOnlyHigh:       genai.HarmBlockOnlyHigh,
+		SafetyThresholdMediumAndAbove: genai.HarmBlockMediumAndAbove,
+		SafetyThresholdLowAndAbove:    genai.HarmBlockLowAndAbove,
+	}
+)
+
+// vertexAISafetySettings converts the provider independent safety settings into Vertex AI ones.
+func vertexAISafetySettings(settings map[string]string) ([]*genai.SafetySetting, error) {
+	var out []*genai.SafetySetting
+	for _, category := range sortedSafetyCategories(settings) {
+		c, ok := vertexAISafetyCategories[category]
+		if !ok {
+			return nil, fmt.Errorf("unsupported safety category %q", category)
+		}
+		t, ok := vertexAISafetyThresholds[settings[category]]
+		if !ok {
+			return nil, fmt.Errorf("unsupported safety threshold %q for category %q", settings[category], category)
+		}
+		out = append(out, &genai.SafetySetting{Category: c, Threshold: t})
+	}
+	return out, nil
 }
 
 // Vertex AI Gemini supported Regions
@@ -103,6 +137,11 @@ func (g *GoogleVertexAIClient) Configure(config IAIConfig) error {
 	projectId := config.GetProviderId()
 	region := GetVertexAIRegionOrDefault(config.GetProviderRegion())
 
+	safetySettings, err := vertexAISafetySettings(config.GetSafetySettings())
+	if err != nil {
+		return err
+	}
+
 	client, err := genai.NewClient(ctx, projectId, region)
 	if err != nil {
 		return fmt.Errorf("creating genai Google SDK client: %w", err)
@@ -114,6 +153,7 @@ func (g *GoogleVertexAIClient) Configure(config IAIConfig) error {
 	g.topP = config.GetTopP()
 	g.topK = config.GetTopK()
 	g.maxTokens = config.GetMaxTokens()
+	g.safetySettings = safetySettings
 
 	return nil
 }
@@ -125,6 +165,9 @@ func (g *GoogleVertexAIClient) GetCompletion(ctx context.Context, prompt string)
 	model.SetTopP(g.topP)
 	model.SetTopK(g.topK)
 	model.SetMaxOutputTokens(int32(g.maxTokens))
+	if len(g.safetySettings) > 0 {
+		model.SafetySettings = g.safetySettings
+	}
 
 	// Google AI SDK is capable of different inputs than just text, for now set explicit text prompt type.
 	// Similarly, we could stream the response. For now k8sgpt does not support streaming.
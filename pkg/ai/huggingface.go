@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/hupe1980/go-huggingface"
 	"k8s.io/utils/ptr"
@@ -23,7 +24,13 @@ type HuggingfaceClient struct {
 func (c *HuggingfaceClient) Configure(config IAIConfig) error {
 	token := config.GetPassword()
 
-	client := huggingface.NewInferenceClient(token)
+	transport, err := httpTransport(config)
+	if err != nil {
+		return err
+	}
+	client := huggingface.NewInferenceClient(token, func(o *huggingface.InferenceClientOptions) {
+		o.HTTPClient = &http.Client{Transport: transport}
+	})
 
 	c.client = client
 	c.model = config.GetModel()
@@ -15,7 +15,10 @@ package ai
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 )
 
 var (
@@ -34,6 +37,7 @@ var (
 		&OCIGenAIClient{},
 		&CustomRestClient{},
 		&IBMWatsonxAIClient{},
+		&AnthropicClient{},
 	}
 	Backends = []string{
 		openAIClientName,
@@ -50,6 +54,7 @@ var (
 		ociClientName,
 		CustomRestClientName,
 		ibmWatsonxAIClientName,
+		anthropicClientName,
 	}
 )
 
@@ -67,6 +72,20 @@ type IAI interface {
 	Close()
 }
 
+// IAILanguageSetter is implemented by clients whose backend takes the language
+// of the completions as a parameter, besides the prompts asking for it.
+type IAILanguageSetter interface {
+	SetLanguage(language string)
+}
+
+// SetLanguage passes language to the clients taking it as a parameter, see
+// IAILanguageSetter. The others only get it in the prompts.
+func SetLanguage(client IAI, language string) {
+	if setter, ok := client.(IAILanguageSetter); ok {
+		setter.SetLanguage(language)
+	}
+}
+
 type nopCloser struct{}
 
 func (nopCloser) Close() {}
@@ -78,6 +97,7 @@ type IAIConfig interface {
 	GetProxyEndpoint() string
 	GetEndpointName() string
 	GetEngine() string
+	GetAPIVersion() string
 	GetTemperature() float32
 	GetProviderRegion() string
 	GetTopP() float32
@@ -87,6 +107,13 @@ type IAIConfig interface {
 	GetCompartmentId() string
 	GetOrganizationId() string
 	GetCustomHeaders() []http.Header
+	GetSafetySettings() map[string]string
+	// GetTransport is the HTTP transport shared by the clients, nil for the
+	// default one.
+	GetTransport() *http.Transport
+	// GetTokenSource provides the Authorization header of each request, nil
+	// for the static one of the client.
+	GetTokenSource() TokenSource
 }
 
 func NewClient(provider string) IAI {
@@ -100,11 +127,68 @@ func NewClient(provider string) IAI {
 }
 
 type AIConfiguration struct {
-	Providers       []AIProvider			 `mapstructure:"providers"`
-	DefaultProvider string       			 `mapstructure:"defaultprovider"`
-	PromptMap       map[string]string  `mapstructure:"promptmap"`
+	Providers       []AIProvider      `mapstructure:"providers"`
+	DefaultProvider string            `mapstructure:"defaultprovider"`
+	PromptMap       map[string]string `mapstructure:"promptmap"`
+	// PromptDir is a directory of prompt templates named by Kind (e.g. Pod.tmpl)
+	// overriding PromptMap.
+	PromptDir string `mapstructure:"prompt_dir" yaml:"prompt_dir,omitempty"`
+	// PromptVars are the values of the ${name} placeholders of the prompts
+	// of PromptMap and PromptDir, the environment variables filling the
+	// others.
+	PromptVars map[string]string `mapstructure:"prompt_vars" yaml:"prompt_vars,omitempty"`
+	// FallbackProviders is the ordered list of provider names tried when the
+	// selected provider fails to explain a result.
+	FallbackProviders []string `mapstructure:"fallbackproviders" yaml:"fallbackproviders,omitempty"`
+	// BatchSize is the number of results explained by a single completion.
+	BatchSize int `mapstructure:"batch_size" yaml:"batch_size,omitempty"`
+	// ProxyURL, CACertFile and InsecureSkipVerify configure the HTTP transport
+	// of the AI clients, see NewTransport. The proxy endpoint of a provider
+	// takes precedence over ProxyURL.
+	ProxyURL           string `mapstructure:"proxy_url" yaml:"proxy_url,omitempty"`
+	CACertFile         string `mapstructure:"ca_cert_file" yaml:"ca_cert_file,omitempty"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify" yaml:"insecure_skip_verify,omitempty"`
+}
+
+// Validate checks that the names of the Providers are unique and that the
+// DefaultProvider, when set, is one of them, so that the provider used
+// doesn't depend on their order.
+func (c *AIConfiguration) Validate() error {
+	seen := map[string]bool{}
+	var duplicates []string
+	for _, provider := range c.Providers {
+		if seen[provider.Name] && !slices.Contains(duplicates, provider.Name) {
+			duplicates = append(duplicates, provider.Name)
+		}
+		seen[provider.Name] = true
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("ai.providers: duplicate provider names %s, remove the extra ones with k8sgpt auth remove", strings.Join(duplicates, ", "))
+	}
+	if c.DefaultProvider != "" && !seen[c.DefaultProvider] && !IsTestingProvider(c.DefaultProvider) {
+		return fmt.Errorf("ai.defaultprovider: %s is not a configured provider, please run k8sgpt auth add or k8sgpt auth default", c.DefaultProvider)
+	}
+	return nil
 }
 
+// The generation parameters of the providers added by k8sgpt auth add when
+// not given, see IsGenerationSet.
+const (
+	DefaultTemperature float32 = 0.7
+	DefaultTopP        float32 = 0.5
+	DefaultTopK        int32   = 50
+	DefaultMaxTokens           = 2048
+)
+
+// The names of the generation parameters, as in the generation configuration
+// keys.
+const (
+	GenerationTemperature = "temperature"
+	GenerationTopP        = "top_p"
+	GenerationTopK        = "top_k"
+	GenerationMaxTokens   = "max_tokens"
+)
+
 type AIProvider struct {
 	Name           string        `mapstructure:"name"`
 	Model          string        `mapstructure:"model"`
@@ -114,6 +198,7 @@ type AIProvider struct {
 	ProxyPort      string        `mapstructure:"proxyPort" yaml:"proxyPort,omitempty"`
 	EndpointName   string        `mapstructure:"endpointname" yaml:"endpointname,omitempty"`
 	Engine         string        `mapstructure:"engine" yaml:"engine,omitempty"`
+	APIVersion     string        `mapstructure:"apiversion" yaml:"apiversion,omitempty"`
 	Temperature    float32       `mapstructure:"temperature" yaml:"temperature,omitempty"`
 	ProviderRegion string        `mapstructure:"providerregion" yaml:"providerregion,omitempty"`
 	ProviderId     string        `mapstructure:"providerid" yaml:"providerid,omitempty"`
@@ -123,6 +208,49 @@ type AIProvider struct {
 	MaxTokens      int           `mapstructure:"maxtokens" yaml:"maxtokens,omitempty"`
 	OrganizationId string        `mapstructure:"organizationid" yaml:"organizationid,omitempty"`
 	CustomHeaders  []http.Header `mapstructure:"customHeaders"`
+	// SafetySettings holds opt-in content moderation overrides keyed by safety
+	// category (see SafetyCategories). Backends without such controls ignore it.
+	SafetySettings map[string]string `mapstructure:"safetysettings" yaml:"safetysettings,omitempty"`
+	// Transport is set from the AIConfiguration before configuring the
+	// client, it isn't part of the configuration file.
+	Transport *http.Transport `mapstructure:"-" yaml:"-"`
+	// TokenFile and TokenCommand provide a bearer token which rotates, read
+	// from the file or printed by the shell command for each request, see
+	// GetTokenSource. TokenFile takes precedence.
+	TokenFile    string `mapstructure:"tokenfile" yaml:"tokenfile,omitempty"`
+	TokenCommand string `mapstructure:"tokencommand" yaml:"tokencommand,omitempty"`
+	// GenerationOverrides are the generation parameters set for the run, e.g.
+	// GenerationTemperature, see IsGenerationSet. They aren't part of the
+	// configuration file.
+	GenerationOverrides []string `mapstructure:"-" yaml:"-"`
+}
+
+// IsGenerationSet tells whether the generation parameter is overridden for the
+// run, or configured with a value other than zero, which is unset, and other
+// than the default of k8sgpt auth add. Only those are sent by the clients
+// which didn't always send them, and are part of the cache key.
+func (p *AIProvider) IsGenerationSet(param string) bool {
+	if slices.Contains(p.GenerationOverrides, param) {
+		return true
+	}
+	switch param {
+	case GenerationTemperature:
+		return p.Temperature != 0 && p.Temperature != DefaultTemperature
+	case GenerationTopP:
+		return p.TopP != 0 && p.TopP != DefaultTopP
+	case GenerationTopK:
+		return p.TopK != 0 && p.TopK != DefaultTopK
+	case GenerationMaxTokens:
+		return p.MaxTokens != 0 && p.MaxTokens != DefaultMaxTokens
+	}
+	return false
+}
+
+// generationSet tells whether config sets the generation parameter, see
+// AIProvider.IsGenerationSet.
+func generationSet(config IAIConfig, param string) bool {
+	provider, ok := config.(interface{ IsGenerationSet(param string) bool })
+	return ok && provider.IsGenerationSet(param)
 }
 
 func (p *AIProvider) GetBaseURL() string {
@@ -160,6 +288,11 @@ func (p *AIProvider) GetModel() string {
 func (p *AIProvider) GetEngine() string {
 	return p.Engine
 }
+
+func (p *AIProvider) GetAPIVersion() string {
+	return p.APIVersion
+}
+
 func (p *AIProvider) GetTemperature() float32 {
 	return p.Temperature
 }
@@ -184,7 +317,26 @@ func (p *AIProvider) GetCustomHeaders() []http.Header {
 	return p.CustomHeaders
 }
 
-var passwordlessProviders = []string{"localai", "ollama", "amazonsagemaker", "amazonbedrock", "googlevertexai", "oci", "customrest"}
+func (p *AIProvider) GetSafetySettings() map[string]string {
+	return p.SafetySettings
+}
+
+func (p *AIProvider) GetTransport() *http.Transport {
+	return p.Transport
+}
+
+func (p *AIProvider) GetTokenSource() TokenSource {
+	switch {
+	case p.TokenFile != "":
+		return FileTokenSource{Path: p.TokenFile}
+	case p.TokenCommand != "":
+		return CommandTokenSource{Command: p.TokenCommand}
+	default:
+		return nil
+	}
+}
+
+var passwordlessProviders = []string{"localai", "ollama", "amazonsagemaker", "amazonbedrock", "googlevertexai", "oci", "customrest", "noopai"}
 
 func NeedPassword(backend string) bool {
 	for _, b := range passwordlessProviders {
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIConfiguration_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   AIConfiguration
+		expected string
+	}{
+		{
+			name: "unique providers",
+			config: AIConfiguration{
+				Providers:       []AIProvider{{Name: "openai"}, {Name: "ollama"}},
+				DefaultProvider: "ollama",
+			},
+		},
+		{
+			name:   "no default provider",
+			config: AIConfiguration{Providers: []AIProvider{{Name: "openai"}}},
+		},
+		{
+			name:   "testing default provider",
+			config: AIConfiguration{DefaultProvider: "noopai"},
+		},
+		{
+			name: "duplicate providers",
+			config: AIConfiguration{Providers: []AIProvider{
+				{Name: "openai", Password: "first"},
+				{Name: "ollama"},
+				{Name: "openai", Password: "second"},
+				{Name: "ollama"},
+				{Name: "openai"},
+			}},
+			expected: "ai.providers: duplicate provider names openai, ollama, remove the extra ones with k8sgpt auth remove",
+		},
+		{
+			name: "unknown default provider",
+			config: AIConfiguration{
+				Providers:       []AIProvider{{Name: "ollama"}},
+				DefaultProvider: "openai",
+			},
+			expected: "ai.defaultprovider: openai is not a configured provider, please run k8sgpt auth add or k8sgpt auth default",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
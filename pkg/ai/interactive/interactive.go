@@ -2,6 +2,7 @@ package interactive
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
@@ -9,59 +10,70 @@ import (
 	"github.com/pterm/pterm"
 )
 
-type INTERACTIVE_STATE int
-
-const (
-	prompt = "Given the following context: "
-)
-
 const (
-	E_RUNNING INTERACTIVE_STATE = iota
-	E_EXITED                    = iota
+	focusCommand = "focus"
+	allResults   = "all"
 )
 
+// InteractionRunner asks the follow-up questions typed by the user about the
+// results of an analysis until they exit.
 type InteractionRunner struct {
-	config        *analysis.Analysis
-	State         chan INTERACTIVE_STATE
-	contextWindow []byte
+	followUp *analysis.FollowUp
 }
 
-func NewInteractionRunner(config *analysis.Analysis, contextWindow []byte) *InteractionRunner {
+func NewInteractionRunner(config *analysis.Analysis) *InteractionRunner {
 	return &InteractionRunner{
-		config:        config,
-		contextWindow: contextWindow,
-		State:         make(chan INTERACTIVE_STATE),
+		followUp: config.NewFollowUp(),
 	}
 }
 
+// StartInteraction returns once the user typed exit or quit.
 func (a *InteractionRunner) StartInteraction() {
-	a.State <- E_RUNNING
-	pterm.Println("Interactive mode enabled [type exit to close.]")
+	pterm.Println("Interactive mode enabled [type focus <number> to ask about a single result, focus all to go back to all of them, exit to close.]")
 	for {
-
 		query := pterm.DefaultInteractiveTextInput.WithMultiLine(false)
 		queryString, err := query.Show()
 		if err != nil {
 			fmt.Println(err)
+			return
 		}
+		queryString = strings.TrimSpace(queryString)
 		if queryString == "" {
 			continue
 		}
-		if strings.Contains(queryString, "exit") {
-			a.State <- E_EXITED
+		if queryString == "exit" || queryString == "quit" {
+			return
+		}
+		if argument, ok := strings.CutPrefix(queryString, focusCommand+" "); ok {
+			a.focus(strings.TrimSpace(argument))
 			continue
 		}
 		pterm.Println()
-		contextWindow := fmt.Sprintf("%s %s %s", prompt, string(a.contextWindow),
-			queryString)
-
-		response, err := a.config.AIClient.GetCompletion(a.config.Context,
-			contextWindow)
+		response, err := a.followUp.Ask(queryString)
 		if err != nil {
 			color.Red("Error: %v", err)
-			a.State <- E_EXITED
 			continue
 		}
 		pterm.Println(response)
 	}
 }
+
+func (a *InteractionRunner) focus(argument string) {
+	index := -1
+	if argument != allResults {
+		var err error
+		if index, err = strconv.Atoi(argument); err != nil {
+			color.Red("Error: focus takes the number of a result or %s", allResults)
+			return
+		}
+	}
+	if err := a.followUp.Focus(index); err != nil {
+		color.Red("Error: %v", err)
+		return
+	}
+	if result := a.followUp.Focused(); result != nil {
+		pterm.Printf("Asking about %s %s\n", result.Kind, result.Name)
+		return
+	}
+	pterm.Println("Asking about all the results")
+}
@@ -19,6 +19,9 @@ import (
 
 const noopAIClientName = "noopai"
 
+// NoOpAIClient is for testing only: it echoes the prompts after a fixed
+// sentence, deterministically and without network or API key, so that the
+// whole pipeline, caching and output included, runs in CI.
 type NoOpAIClient struct {
 	nopCloser
 }
@@ -35,3 +38,9 @@ func (c *NoOpAIClient) GetCompletion(_ context.Context, prompt string) (string,
 func (c *NoOpAIClient) GetName() string {
 	return noopAIClientName
 }
+
+// IsTestingProvider reports whether backend is the NoOpAIClient, which can be
+// used without being configured with k8sgpt auth.
+func IsTestingProvider(backend string) bool {
+	return backend == noopAIClientName
+}
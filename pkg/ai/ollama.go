@@ -31,6 +31,7 @@ type OllamaClient struct {
 	model       string
 	temperature float32
 	topP        float32
+	maxTokens   int
 }
 
 const (
@@ -48,20 +49,12 @@ func (c *OllamaClient) Configure(config IAIConfig) error {
 		return err
 	}
 
-	proxyEndpoint := config.GetProxyEndpoint()
-	httpClient := http.DefaultClient
-	if proxyEndpoint != "" {
-		proxyUrl, err := url.Parse(proxyEndpoint)
-		if err != nil {
-			return err
-		}
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyUrl),
-		}
-
-		httpClient = &http.Client{
-			Transport: transport,
-		}
+	transport, err := httpTransport(config)
+	if err != nil {
+		return err
+	}
+	httpClient := &http.Client{
+		Transport: transport,
 	}
 
 	c.client = ollama.NewClient(baseClientURL, httpClient)
@@ -74,9 +67,19 @@ func (c *OllamaClient) Configure(config IAIConfig) error {
 	}
 	c.temperature = config.GetTemperature()
 	c.topP = config.GetTopP()
+	// Only sent when set, as the requests didn't have it before.
+	c.maxTokens = 0
+	if generationSet(config, GenerationMaxTokens) {
+		c.maxTokens = config.GetMaxTokens()
+	}
 	return nil
 }
 func (c *OllamaClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	response, _, err := c.GetCompletionWithUsage(ctx, prompt)
+	return response, err
+}
+
+func (c *OllamaClient) GetCompletionWithUsage(ctx context.Context, prompt string) (string, TokenUsage, error) {
 	req := &ollama.GenerateRequest{
 		Model:  c.model,
 		Prompt: prompt,
@@ -86,16 +89,22 @@ func (c *OllamaClient) GetCompletion(ctx context.Context, prompt string) (string
 			"top_p":       c.topP,
 		},
 	}
+	if c.maxTokens > 0 {
+		req.Options["num_predict"] = c.maxTokens
+	}
 	completion := ""
+	var usage TokenUsage
 	respFunc := func(resp ollama.GenerateResponse) error {
 		completion = resp.Response
+		usage.PromptTokens = resp.PromptEvalCount
+		usage.CompletionTokens = resp.EvalCount
 		return nil
 	}
 	err := c.client.Generate(ctx, req, respFunc)
 	if err != nil {
-		return "", err
+		return "", TokenUsage{}, err
 	}
-	return completion, nil
+	return completion, usage, nil
 }
 func (a *OllamaClient) GetName() string {
 	return ollamaClientName
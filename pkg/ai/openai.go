@@ -16,8 +16,10 @@ package ai
 import (
 	"context"
 	"errors"
+	"io"
+	"math"
 	"net/http"
-	"net/url"
+	"strings"
 
 	"github.com/sashabaranov/go-openai"
 )
@@ -31,6 +33,7 @@ type OpenAIClient struct {
 	model       string
 	temperature float32
 	topP        float32
+	maxTokens   int
 	// organizationId string
 }
 
@@ -45,20 +48,15 @@ func (c *OpenAIClient) Configure(config IAIConfig) error {
 	token := config.GetPassword()
 	defaultConfig := openai.DefaultConfig(token)
 	orgId := config.GetOrganizationId()
-	proxyEndpoint := config.GetProxyEndpoint()
 
 	baseURL := config.GetBaseURL()
 	if baseURL != "" {
 		defaultConfig.BaseURL = baseURL
 	}
 
-	transport := &http.Transport{}
-	if proxyEndpoint != "" {
-		proxyUrl, err := url.Parse(proxyEndpoint)
-		if err != nil {
-			return err
-		}
-		transport.Proxy = http.ProxyURL(proxyUrl)
+	transport, err := httpTransport(config)
+	if err != nil {
+		return err
 	}
 
 	if orgId != "" {
@@ -79,14 +77,69 @@ func (c *OpenAIClient) Configure(config IAIConfig) error {
 	}
 	c.client = client
 	c.model = config.GetModel()
-	c.temperature = config.GetTemperature()
+	c.temperature = openAITemperature(config)
 	c.topP = config.GetTopP()
+	c.maxTokens = maxToken
+	if generationSet(config, GenerationMaxTokens) {
+		c.maxTokens = config.GetMaxTokens()
+	}
 	return nil
 }
 
 func (c *OpenAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	response, _, err := c.GetCompletionWithUsage(ctx, prompt)
+	return response, err
+}
+
+func (c *OpenAIClient) GetCompletionWithUsage(ctx context.Context, prompt string) (string, TokenUsage, error) {
 	// Create a completion request
-	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+	request := c.chatCompletionRequest(prompt)
+	request.LogProbs = confidenceRequested(ctx)
+	resp, err := c.client.CreateChatCompletion(ctx, request)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+	usage := TokenUsage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}
+	if logProbs := resp.Choices[0].LogProbs; logProbs != nil {
+		values := make([]float64, 0, len(logProbs.Content))
+		for _, token := range logProbs.Content {
+			values = append(values, token.LogProb)
+		}
+		usage.Confidence = confidenceFromLogProbs(values)
+	}
+	return resp.Choices[0].Message.Content, usage, nil
+}
+
+func (c *OpenAIClient) GetCompletionStream(ctx context.Context, prompt string, onChunk func(chunk string)) (string, error) {
+	stream, err := c.client.CreateChatCompletionStream(ctx, c.chatCompletionRequest(prompt))
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	var response strings.Builder
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return response.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			continue
+		}
+		chunk := resp.Choices[0].Delta.Content
+		response.WriteString(chunk)
+		onChunk(chunk)
+	}
+}
+
+func (c *OpenAIClient) chatCompletionRequest(prompt string) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
 		Model: c.model,
 		Messages: []openai.ChatCompletionMessage{
 			{
@@ -95,15 +148,22 @@ func (c *OpenAIClient) GetCompletion(ctx context.Context, prompt string) (string
 			},
 		},
 		Temperature:      c.temperature,
-		MaxTokens:        maxToken,
+		MaxTokens:        c.maxTokens,
 		PresencePenalty:  presencePenalty,
 		FrequencyPenalty: frequencyPenalty,
 		TopP:             c.topP,
-	})
-	if err != nil {
-		return "", err
 	}
-	return resp.Choices[0].Message.Content, nil
+}
+
+// openAITemperature is the temperature of config. A temperature of zero set
+// for the run is kept in the requests, go-openai omitting it and the API then
+// defaulting to 1, an unset one is left to the API.
+func openAITemperature(config IAIConfig) float32 {
+	temperature := config.GetTemperature()
+	if temperature == 0 && generationSet(config, GenerationTemperature) {
+		return math.SmallestNonzeroFloat32
+	}
+	return temperature
 }
 
 func (c *OpenAIClient) GetName() string {
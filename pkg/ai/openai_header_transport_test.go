@@ -30,6 +30,14 @@ func (m *mockConfig) GetBaseURL() string {
 	return m.baseURL
 }
 
+func (m *mockConfig) GetTransport() *http.Transport {
+	return nil
+}
+
+func (m *mockConfig) GetTokenSource() TokenSource {
+	return nil
+}
+
 func (m *mockConfig) GetCustomHeaders() []http.Header {
 	return []http.Header{
 		{"X-Custom-Header-1": []string{"Value1"}},
@@ -38,6 +46,10 @@ func (m *mockConfig) GetCustomHeaders() []http.Header {
 	}
 }
 
+func (m *mockConfig) GetSafetySettings() map[string]string {
+	return nil
+}
+
 func (m *mockConfig) GetModel() string {
 	return ""
 }
@@ -68,6 +80,10 @@ func (m *mockConfig) GetEngine() string {
 	return ""
 }
 
+func (m *mockConfig) GetAPIVersion() string {
+	return ""
+}
+
 func (m *mockConfig) GetProviderId() string {
 	return ""
 }
@@ -1,10 +1,34 @@
 package ai
 
 const (
-	default_prompt = `Simplify the following Kubernetes error message delimited by triple dashes written in --- %s --- language; --- %s ---.
+	// The system portion, before the ---user--- line, holds the instructions
+	// and the user portion the error message.
+	default_prompt = `Simplify the following Kubernetes error message delimited by triple dashes written in --- %s --- language.
 	Provide the most possible solution in a step by step style in no more than 280 characters. Write the output in the following format:
 	Error: {Explain error here}
 	Solution: {Step by step solution here}
+	---user---
+	--- %s ---
+	`
+
+	// remediation_prompt is the default prompt asking for the commands
+	// applying the solution in a section of their own.
+	remediation_prompt = `Simplify the following Kubernetes error message delimited by triple dashes written in --- %s --- language.
+	Provide the most possible solution in a step by step style in no more than 280 characters, then the kubectl commands applying it, if any. Write the output in the following format:
+	Error: {Explain error here}
+	Solution: {Step by step solution here}
+	Commands:
+	{One kubectl command per line, without any other text}
+	---user---
+	--- %s ---
+	`
+
+	// followup_prompt answers a question about the findings of an analysis,
+	// given the previous questions and answers.
+	followup_prompt = `You are helping to troubleshoot a Kubernetes cluster. Answer the last question below about the findings of k8sgpt, written in --- %s --- language.
+	Take the previous questions and answers into account, reference the resources by name and answer in no more than 500 characters.
+	---user---
+	%s
 	`
 
 	prom_conf_prompt = `Simplify the following Prometheus error message delimited by triple dashes written in --- %s --- language; --- %s ---.
@@ -57,11 +81,25 @@ const (
 	Solution: {kubectl command}
 	`
 	raw_promt = `{"language": "%s","message": "%s","prompt": "%s"}`
+
+	batch_prompt = `Simplify each of the following numbered Kubernetes error messages, written in --- %s --- language.
+	Each error message starts with a line containing only its number in the form ### {number}.
+	For each error message, provide the most possible solution in a step by step style in no more than 280 characters.
+	Answer every error message, in the same order, starting each answer with a line containing only ### {number} followed by the answer in the following format:
+	Error: {Explain error here}
+	Solution: {Step by step solution here}
+	---
+	%s
+	---
+	`
 )
 
 var PromptMap = map[string]string{
 	"raw":                           raw_promt,
+	"batch":                         batch_prompt,
 	"default":                       default_prompt,
+	"remediation":                   remediation_prompt,
+	"followup":                      followup_prompt,
 	"PrometheusConfigValidate":      prom_conf_prompt,
 	"PrometheusConfigRelabelReport": prom_relabel_prompt,
 	"PolicyReport":                  kyverno_prompt,
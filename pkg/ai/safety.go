@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Provider independent names for the content moderation (safety) categories and
+// block thresholds. Support is provider-dependent: backends exposing moderation
+// controls (currently google and googlevertexai) map these onto their own
+// settings, every other backend ignores them.
+const (
+	SafetyCategoryHarassment       = "harassment"
+	SafetyCategoryHateSpeech       = "hate_speech"
+	SafetyCategorySexuallyExplicit = "sexually_explicit"
+	SafetyCategoryDangerousContent = "dangerous_content"
+
+	SafetyThresholdNone           = "none"
+	SafetyThresholdOnlyHigh       = "only_high"
+	SafetyThresholdMediumAndAbove = "medium_and_above"
+	SafetyThresholdLowAndAbove    = "low_and_above"
+)
+
+var (
+	SafetyCategories = []string{
+		SafetyCategoryHarassment,
+		SafetyCategoryHateSpeech,
+		SafetyCategorySexuallyExplicit,
+		SafetyCategoryDangerousContent,
+	}
+	SafetyThresholds = []string{
+		SafetyThresholdNone,
+		SafetyThresholdOnlyHigh,
+		SafetyThresholdMediumAndAbove,
+		SafetyThresholdLowAndAbove,
+	}
+)
+
+// ParseSafetySettings parses "<category>=<threshold>" pairs (e.g. dangerous_content=none)
+// into a safety settings map, validating both sides against the known names.
+func ParseSafetySettings(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	settings := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		category, threshold, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid safety setting %q, expected <category>=<threshold>", pair)
+		}
+		category = strings.ToLower(strings.TrimSpace(category))
+		threshold = strings.ToLower(strings.TrimSpace(threshold))
+		if !slices.Contains(SafetyCategories, category) {
+			return nil, fmt.Errorf("unknown safety category %q, accepted values are '%s'", category, strings.Join(SafetyCategories, ", "))
+		}
+		if !slices.Contains(SafetyThresholds, threshold) {
+			return nil, fmt.Errorf("unknown safety threshold %q, accepted values are '%s'", threshold, strings.Join(SafetyThresholds, ", "))
+		}
+		settings[category] = threshold
+	}
+	return settings, nil
+}
+
+// sortedSafetyCategories returns the configured categories in a stable order so
+// providers always send the settings the same way.
+func sortedSafetyCategories(settings map[string]string) []string {
+	categories := make([]string, 0, len(settings))
+	for category := range settings {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
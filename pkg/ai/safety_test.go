@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSafetySettings(t *testing.T) {
+	settings, err := ParseSafetySettings([]string{"Dangerous_Content=none", " harassment = only_high "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		SafetyCategoryDangerousContent: SafetyThresholdNone,
+		SafetyCategoryHarassment:       SafetyThresholdOnlyHigh,
+	}, settings)
+
+	settings, err = ParseSafetySettings(nil)
+	require.NoError(t, err)
+	assert.Nil(t, settings)
+
+	_, err = ParseSafetySettings([]string{"dangerous_content"})
+	assert.ErrorContains(t, err, "expected <category>=<threshold>")
+
+	_, err = ParseSafetySettings([]string{"violence=none"})
+	assert.ErrorContains(t, err, "unknown safety category")
+
+	_, err = ParseSafetySettings([]string{"harassment=sometimes"})
+	assert.ErrorContains(t, err, "unknown safety threshold")
+}
+
+func TestGoogleSafetySettings(t *testing.T) {
+	out, err := googleSafetySettings(map[string]string{
+		SafetyCategoryHateSpeech:       SafetyThresholdLowAndAbove,
+		SafetyCategoryDangerousContent: SafetyThresholdNone,
+	})
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	// Categories are emitted in a stable, sorted order.
+	assert.Equal(t, googleSafetyCategories[SafetyCategoryDangerousContent], out[0].Category)
+	assert.Equal(t, googleSafetyThresholds[SafetyThresholdNone], out[0].Threshold)
+	assert.Equal(t, googleSafetyCategories[SafetyCategoryHateSpeech], out[1].Category)
+
+	vertexOut, err := vertexAISafetySettings(nil)
+	require.NoError(t, err)
+	assert.Empty(t, vertexOut)
+}
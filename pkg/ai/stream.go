@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import "context"
+
+// IAIStreamer is implemented by clients whose backend can stream a completion
+// while it's generated.
+type IAIStreamer interface {
+	// GetCompletionStream generates text based on prompt, calling onChunk with
+	// each piece of the response as it arrives. It returns the whole response.
+	GetCompletionStream(ctx context.Context, prompt string, onChunk func(chunk string)) (string, error)
+}
+
+// GetCompletionStream streams a completion to onChunk and returns it along with
+// an estimate of the tokens it used. Clients that can't stream, or that take the
+// system portion of the prompt apart, pass the whole completion to onChunk once
+// it's generated.
+func GetCompletionStream(ctx context.Context, client IAI, prompt string, onChunk func(chunk string)) (string, TokenUsage, error) {
+	system, user := SplitSystemPrompt(prompt)
+	if _, ok := client.(IAISystemPrompter); ok && system != "" {
+		response, usage, err := GetCompletionWithSystem(ctx, client, prompt)
+		if err != nil {
+			return "", TokenUsage{}, err
+		}
+		onChunk(response)
+		return response, usage, nil
+	}
+	prompt = JoinSystemPrompt(system, user)
+	if streamer, ok := client.(IAIStreamer); ok {
+		response, err := streamer.GetCompletionStream(ctx, prompt, onChunk)
+		if err != nil {
+			return "", TokenUsage{}, err
+		}
+		return response, estimateUsage(prompt, response), nil
+	}
+	response, usage, err := GetCompletionWithUsage(ctx, client, prompt)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+	onChunk(response)
+	return response, usage, nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type streamingClient struct {
+	NoOpAIClient
+	chunks []string
+}
+
+func (c *streamingClient) GetCompletionStream(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	for _, chunk := range c.chunks {
+		onChunk(chunk)
+	}
+	return strings.Join(c.chunks, ""), nil
+}
+
+func TestGetCompletionStream(t *testing.T) {
+	var got []string
+	client := &streamingClient{chunks: []string{"I am ", "a stream"}}
+	response, usage, err := GetCompletionStream(context.Background(), client, "prompt", func(chunk string) {
+		got = append(got, chunk)
+	})
+	require.NoError(t, err)
+	require.Equal(t, "I am a stream", response)
+	require.Equal(t, []string{"I am ", "a stream"}, got)
+	require.True(t, usage.Estimated)
+}
+
+func TestGetCompletionStream_NotStreaming(t *testing.T) {
+	var got []string
+	response, _, err := GetCompletionStream(context.Background(), &NoOpAIClient{}, "prompt", func(chunk string) {
+		got = append(got, chunk)
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{response}, got)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"strings"
+)
+
+// SystemPromptSeparator is the line separating the system portion of a prompt,
+// the instructions, from the user portion holding the data to explain.
+const SystemPromptSeparator = "---user---"
+
+// IAISystemPrompter is implemented by clients whose backend takes the system
+// instructions apart from the user message.
+type IAISystemPrompter interface {
+	GetCompletionWithSystem(ctx context.Context, system string, prompt string) (string, TokenUsage, error)
+}
+
+// SplitSystemPrompt splits a prompt at its first SystemPromptSeparator line into
+// the system and the user portions. Prompts without one have no system portion.
+func SplitSystemPrompt(prompt string) (system string, user string) {
+	lines := strings.Split(prompt, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == SystemPromptSeparator {
+			return strings.TrimSpace(strings.Join(lines[:i], "\n")), strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+		}
+	}
+	return "", prompt
+}
+
+// JoinSystemPrompt joins the portions of a prompt into the single string sent to
+// the clients taking no system instructions.
+func JoinSystemPrompt(system string, user string) string {
+	if system == "" {
+		return user
+	}
+	return system + "\n" + user
+}
+
+// GetCompletionWithSystem generates a completion with the system instructions
+// of the prompt, if any, sent apart from the user message when the client
+// supports it. Other clients get the joined prompt.
+func GetCompletionWithSystem(ctx context.Context, client IAI, prompt string) (string, TokenUsage, error) {
+	system, user := SplitSystemPrompt(prompt)
+	prompter, ok := client.(IAISystemPrompter)
+	if system == "" || !ok {
+		return GetCompletionWithUsage(ctx, client, JoinSystemPrompt(system, user))
+	}
+	response, usage, err := prompter.GetCompletionWithSystem(ctx, system, user)
+	if err != nil || usage.PromptTokens > 0 || usage.CompletionTokens > 0 {
+		return response, usage, err
+	}
+	return response, estimateUsage(system+user, response), nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// TokenSource provides the bearer token of the requests to an AI provider,
+// e.g. a short-lived token of a model gateway. It's called for each request,
+// so that the token may rotate during a run.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// FileTokenSource reads the token from a file kept up to date by another
+// process, e.g. a projected service account token.
+type FileTokenSource struct {
+	Path string
+}
+
+func (s FileTokenSource) Token(_ context.Context) (string, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return "", err
+	}
+	return nonEmptyToken(string(data))
+}
+
+// CommandTokenSource runs a shell command printing the token.
+type CommandTokenSource struct {
+	Command string
+}
+
+func (s CommandTokenSource) Token(ctx context.Context) (string, error) {
+	var stderr strings.Builder
+	command := exec.CommandContext(ctx, "sh", "-c", s.Command) // #nosec G204 -- configured by the user
+	command.Stderr = &stderr
+	output, err := command.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%w: %s", err, message)
+		}
+		return "", err
+	}
+	return nonEmptyToken(string(output))
+}
+
+func nonEmptyToken(token string) (string, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", errors.New("empty token")
+	}
+	return token, nil
+}
+
+// tokenTransport sets the Authorization header of each request to the
+// current token of its TokenSource, replacing the static one of the client.
+type tokenTransport struct {
+	Origin http.RoundTripper
+	Source TokenSource
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("getting the token of the AI provider: %w", err)
+	}
+	clonedReq := req.Clone(req.Context())
+	clonedReq.Header.Set("Authorization", "Bearer "+token)
+	return t.Origin.RoundTrip(clonedReq)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// NewTransport builds the HTTP transport shared by the AI clients from the
+// ai.proxy_url, ai.ca_cert_file and ai.insecure_skip_verify settings. It's nil
+// when none is set, the clients then use the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY environment variables.
+func (c *AIConfiguration) NewTransport() (*http.Transport, error) {
+	if c.ProxyURL == "" && c.CACertFile == "" && !c.InsecureSkipVerify {
+		return nil, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.ProxyURL != "" {
+		if _, err := url.Parse(c.ProxyURL); err != nil {
+			return nil, fmt.Errorf("ai.proxy_url: %w", err)
+		}
+		// The proxy replaces the one of the environment, NO_PROXY still
+		// applies.
+		proxy := (&httpproxy.Config{
+			HTTPProxy:  c.ProxyURL,
+			HTTPSProxy: c.ProxyURL,
+			NoProxy:    httpproxy.FromEnvironment().NoProxy,
+		}).ProxyFunc()
+		transport.Proxy = func(request *http.Request) (*url.URL, error) {
+			return proxy(request.URL)
+		}
+	}
+	if c.CACertFile != "" || c.InsecureSkipVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify} // #nosec G402 -- opt-in
+		if c.CACertFile != "" {
+			pem, err := os.ReadFile(c.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("ai.ca_cert_file: %w", err)
+			}
+			// The CA certificates are trusted on top of the system ones.
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.New("ai.ca_cert_file: no PEM certificate found")
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport, nil
+}
+
+// httpTransport returns the transport of the HTTP requests of a client, the
+// shared one if any, with the proxy endpoint of the provider taking precedence
+// over the other proxy settings. The requests get their Authorization header
+// from the token source of the provider, if any.
+func httpTransport(config IAIConfig) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if shared := config.GetTransport(); shared != nil {
+		transport = shared.Clone()
+	}
+	if proxyEndpoint := config.GetProxyEndpoint(); proxyEndpoint != "" {
+		proxyUrl, err := url.Parse(proxyEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+	if source := config.GetTokenSource(); source != nil {
+		return &tokenTransport{Origin: transport, Source: source}, nil
+	}
+	return transport, nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func proxyOf(t *testing.T, transport *http.Transport, target string) string {
+	request, err := http.NewRequest(http.MethodGet, target, nil)
+	require.NoError(t, err)
+	proxy, err := transport.Proxy(request)
+	require.NoError(t, err)
+	if proxy == nil {
+		return ""
+	}
+	return proxy.String()
+}
+
+func TestAIConfiguration_NewTransportProxy(t *testing.T) {
+	transport, err := (&AIConfiguration{}).NewTransport()
+	require.NoError(t, err)
+	require.Nil(t, transport)
+
+	t.Setenv("NO_PROXY", "internal.example.com")
+	transport, err = (&AIConfiguration{ProxyURL: "http://proxy:8080"}).NewTransport()
+	require.NoError(t, err)
+	require.Equal(t, "http://proxy:8080", proxyOf(t, transport, "https://api.openai.com/v1"))
+	require.Empty(t, proxyOf(t, transport, "https://internal.example.com/v1"))
+
+	// The proxy endpoint of the provider takes precedence.
+	roundTripper, err := httpTransport(&AIProvider{ProxyEndpoint: "http://provider-proxy:8080", Transport: transport})
+	require.NoError(t, err)
+	require.Equal(t, "http://provider-proxy:8080", proxyOf(t, roundTripper.(*http.Transport), "https://api.openai.com/v1"))
+}
+
+func TestAIConfiguration_NewTransportTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	get := func(transport http.RoundTripper) error {
+		response, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err == nil {
+			response.Body.Close()
+		}
+		return err
+	}
+
+	roundTripper, err := httpTransport(&AIProvider{})
+	require.NoError(t, err)
+	require.Error(t, get(roundTripper))
+
+	caCertFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+	transport, err := (&AIConfiguration{CACertFile: caCertFile}).NewTransport()
+	require.NoError(t, err)
+	require.NoError(t, get(transport))
+
+	transport, err = (&AIConfiguration{InsecureSkipVerify: true}).NewTransport()
+	require.NoError(t, err)
+	require.NoError(t, get(transport))
+
+	require.NoError(t, os.WriteFile(caCertFile, []byte("not a certificate"), 0o600))
+	_, err = (&AIConfiguration{CACertFile: caCertFile}).NewTransport()
+	require.EqualError(t, err, "ai.ca_cert_file: no PEM certificate found")
+}
+
+func TestHTTPTransport_TokenSource(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+	get := func(transport http.RoundTripper) error {
+		request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer static")
+		response, err := (&http.Client{Transport: transport}).Do(request)
+		if err == nil {
+			response.Body.Close()
+		}
+		return err
+	}
+
+	// The token is read again for each request, so that it may rotate.
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("first\n"), 0o600))
+	transport, err := httpTransport(&AIProvider{TokenFile: tokenFile, TokenCommand: "echo ignored"})
+	require.NoError(t, err)
+	require.NoError(t, get(transport))
+	require.NoError(t, os.WriteFile(tokenFile, []byte("second\n"), 0o600))
+	require.NoError(t, get(transport))
+
+	transport, err = httpTransport(&AIProvider{TokenCommand: "echo from-command"})
+	require.NoError(t, err)
+	require.NoError(t, get(transport))
+
+	// Without a token source the static header is kept.
+	transport, err = httpTransport(&AIProvider{})
+	require.NoError(t, err)
+	require.NoError(t, get(transport))
+	require.Equal(t, []string{"Bearer first", "Bearer second", "Bearer from-command", "Bearer static"}, authorizations)
+
+	// The requests fail instead of going out unauthenticated.
+	require.NoError(t, os.WriteFile(tokenFile, nil, 0o600))
+	transport, err = httpTransport(&AIProvider{TokenFile: tokenFile})
+	require.NoError(t, err)
+	require.ErrorContains(t, get(transport), "getting the token of the AI provider: empty token")
+	transport, err = httpTransport(&AIProvider{TokenCommand: "echo expired >&2; exit 1"})
+	require.NoError(t, err)
+	require.ErrorContains(t, get(transport), "getting the token of the AI provider: exit status 1: expired")
+	require.Len(t, authorizations, 4)
+}
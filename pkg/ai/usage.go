@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import "context"
+
+// TokenUsage is the number of tokens consumed by a single completion.
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	// Estimated is set when the backend didn't report usage and the counts were
+	// derived from the text length instead.
+	Estimated bool
+	// Confidence is the confidence of the backend in the completion, between 0
+	// and 1, when it was requested with WithConfidence and the backend reports
+	// it. It's nil otherwise.
+	Confidence *float64
+}
+
+// IAIUsageReporter is implemented by clients whose backend reports the token
+// usage of a completion.
+type IAIUsageReporter interface {
+	GetCompletionWithUsage(ctx context.Context, prompt string) (string, TokenUsage, error)
+}
+
+// EstimateTokens roughly estimates the number of tokens of a text, assuming the
+// common ~4 characters per token ratio of English text.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// GetCompletionWithUsage generates a completion and returns the tokens it used.
+// Clients that don't report usage fall back to an estimate from the prompt and
+// completion lengths.
+func GetCompletionWithUsage(ctx context.Context, client IAI, prompt string) (string, TokenUsage, error) {
+	if reporter, ok := client.(IAIUsageReporter); ok {
+		response, usage, err := reporter.GetCompletionWithUsage(ctx, prompt)
+		// Some OpenAI compatible backends leave the usage empty.
+		if err != nil || usage.PromptTokens > 0 || usage.CompletionTokens > 0 {
+			return response, usage, err
+		}
+		estimated := estimateUsage(prompt, response)
+		estimated.Confidence = usage.Confidence
+		return response, estimated, nil
+	}
+	response, err := client.GetCompletion(ctx, prompt)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+	return response, estimateUsage(prompt, response), nil
+}
+
+func estimateUsage(prompt string, response string) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     EstimateTokens(prompt),
+		CompletionTokens: EstimateTokens(response),
+		Estimated:        true,
+	}
+}
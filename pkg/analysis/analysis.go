@@ -14,14 +14,21 @@ limitations under the License.
 package analysis
 
 import (
+	"cmp"
 	"context"
-	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
 	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
 	openapi_v2 "github.com/google/gnostic/openapiv2"
@@ -34,6 +41,8 @@ import (
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/viper"
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type Analysis struct {
@@ -44,34 +53,284 @@ type Analysis struct {
 	AIClient           ai.IAI
 	PromptMap          map[string]string
 	Results            []common.Result
-	Errors             []string
+	Errors             AnalysisErrors
 	Namespace          string
 	LabelSelector      string
 	Cache              cache.ICache
 	Explain            bool
 	MaxConcurrency     int
 	AnalysisAIProvider string // The name of the AI Provider used for this analysis
+	AIModel            string // The model of the AI Provider, part of the cache key
+	AIBaseURL          string // The base URL of the AI Provider, part of the cache key
+	AIGeneration       string // The non-default generation parameters of the AI Provider, part of the cache key
+	// FallbackAIBackends are tried in order when AIClient fails to explain a result.
+	FallbackAIBackends []AIBackend
 	WithDoc            bool
 	WithStats          bool
 	Stats              []common.AnalysisStats
+	// ExecutionBudget bounds the wall-clock time RunAnalysis spends launching
+	// analyzers. Once exceeded, no new analyzers are started (in-flight ones
+	// finish) and the remaining ones are recorded in SkippedAnalyzers.
+	// Zero means no budget.
+	ExecutionBudget  time.Duration
+	SkippedAnalyzers []string
+	// MaxRetries and RetryBaseDelay control the exponential backoff used when
+	// the AI provider rate limits a request (HTTP 429).
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	// BatchSize is the number of results explained by a single AI request. Values
+	// below 2 disable batching.
+	BatchSize  int
+	cacheStats CacheStats
+	// AnalyzerTimeout bounds the time each analyzer, custom ones included, may
+	// run, read from the analyzer_timeout configuration key. Zero means no
+	// timeout.
+	AnalyzerTimeout time.Duration
+	// MinSeverity drops the results of a lower severity before they are
+	// explained. Empty keeps all results.
+	MinSeverity common.Severity
+	// MaxProblems keeps only the first results, the most severe ones, once they
+	// are deduplicated and prioritized, so that a badly broken cluster doesn't
+	// explain thousands of them. Zero keeps all results.
+	MaxProblems int
+	// MaxResultsPerAnalyzer keeps only the most severe results of each
+	// analyzer across its runs, so that e.g. thousands of failing pods don't
+	// drown the results of the other analyzers. Zero keeps all results.
+	MaxResultsPerAnalyzer int
+	// MaxInputLength caps the characters of the failure texts sent to the AI
+	// provider for a result, read from the ai.max_input_length configuration
+	// key. Longer texts lose their middle. Zero means no limit.
+	MaxInputLength int
+	// ExplainMinSeverity keeps the results of a lower severity unexplained,
+	// read from the explain.min_severity configuration key. Unlike MinSeverity
+	// they stay in the output. Empty explains all results.
+	ExplainMinSeverity common.Severity
+	// Confidence requests the confidence of the AI provider in its
+	// explanations, read from the explain.confidence configuration key.
+	Confidence bool
+	// MinConfidence drops the explanations the AI provider is less confident
+	// in, read from the explain.min_confidence configuration key. It implies
+	// Confidence, explanations without a confidence are kept.
+	MinConfidence float64
+	// ExplainRemediation asks the AI provider for the commands applying its
+	// solution, read from the explain.remediation configuration key. They are
+	// added to the Remediation of the results.
+	ExplainRemediation bool
+	// ExcludeFilters are analyzers which aren't run, even when selected by
+	// Filters, the active filters or by default.
+	ExcludeFilters []string
+	// AnonymizePatterns match the data masked in failure texts when
+	// anonymizing, on top of the sensitive data found by the analyzers.
+	AnonymizePatterns []*regexp.Regexp
+	// patternMasks are the masks of the values matched by AnonymizePatterns.
+	patternMasks map[string]string
+	// pseudonyms replace the names and namespaces of the results when
+	// anonymizing, pseudonymCounts numbers them by kind.
+	pseudonyms      map[string]string
+	pseudonymCounts map[string]int
+	// cutShort are the analyzers which didn't complete because Context was done.
+	cutShort []string
+	// cappedResults are the results dropped by MaxProblems.
+	cappedResults []common.Result
+	// resultAnalyzers maps the resultKey of the results to the analyzer which
+	// found them, truncated counts the ones MaxResultsPerAnalyzer dropped.
+	resultAnalyzers map[string]string
+	truncated       map[string]int
+	// CustomAnalysis runs the custom analyzers as part of Analyze, Anonymize
+	// masks the data sent to the AI provider when Analyze explains the results.
+	CustomAnalysis bool
+	Anonymize      bool
+	// NoProgress suppresses the progress bar of GetAIResults whatever the output
+	// format, e.g. when the output goes to a log collector.
+	NoProgress bool
+	// Stream prints the explanations of GetAIResults as the AI provider
+	// generates them, in place of the progress bar. It only applies to the
+	// text output, and anonymized data is printed masked.
+	Stream bool
+	// onChunk receives the pieces of the completions while streaming.
+	onChunk func(chunk string)
+	// ResultLines receives each result as a JSON line as soon as GetAIResults
+	// explained it, e.g. the standard output of the ndjson output, which then
+	// only has the other results and its summary line. It is set to nil once
+	// a write fails.
+	ResultLines io.Writer
+	// linesWritten are the results written to ResultLines, and explanationErr
+	// the error of the last explanation, reported by the ndjson output.
+	linesWritten   []bool
+	explanationErr error
+	// Metrics records the metrics of the analysis, nil disables them.
+	Metrics MetricsRecorder
+	// Logger receives the debug records of the analysis, e.g. the analyzers
+	// launched with their namespace and duration. Nil writes them as text to
+	// the standard output with the verbose flag, and discards them without.
+	Logger *slog.Logger
+	// statsMutex guards the Stats while explainResults runs its workers.
+	statsMutex *sync.Mutex
+	// NamespaceConcurrency shards the analysis of all namespaces by namespace,
+	// running at most that many analyzers at once in each namespace. Zero
+	// disables sharding.
+	NamespaceConcurrency int
+	// AIConcurrency bounds the results explained at once, read from the
+	// ai.concurrency configuration key. It's independent of MaxConcurrency,
+	// which bounds the analyzers, as AI providers have lower limits than the
+	// API server. Zero means defaultAIConcurrency.
+	AIConcurrency int
+	// Webhook receives the output of the analysis once it completes, nil
+	// disables the delivery.
+	Webhook *Webhook
+	// GroupBy groups the results of the text output, only GroupByNamespace is
+	// supported. Empty lists them as they were found.
+	GroupBy string
+	// Previous is the output of a previous analysis the results are compared
+	// with. The unchanged results reuse its explanations.
+	Previous *JsonOutput
+	// Namespaces are analyzed instead of Namespace, each of them separately,
+	// and their results merged. Empty analyzes Namespace.
+	Namespaces []string
+	// PricePer1K is the price of 1000 prompt tokens, read from the
+	// ai.price_per_1k configuration key, and Budget the maximum estimated cost
+	// of the explanations, read from ai.budget. Zero means no budget.
+	PricePer1K float64
+	Budget     float64
+	// ConfirmBudget is asked whether to explain the results when their
+	// estimated cost exceeds the Budget, e.g. in interactive mode. Nil skips
+	// the explanation.
+	ConfirmBudget func(estimate CostEstimate) bool
+	// Sink receives the output of Analyze, nil leaves it to the caller. See
+	// WriteOutput for the default one.
+	Sink OutputSink
+	// Since restricts the analyzers to the objects created or changed within
+	// that window, e.g. for alerting pipelines. The Log, Security, Storage and
+	// TLSCertificate analyzers, the integrations and the custom analyzers
+	// ignore it. Zero analyzes all objects.
+	Since time.Duration
+	// FieldSelector selects the objects analyzed by their fields (e.g.
+	// status.phase=Running), along with the LabelSelector. The gateway API
+	// analyzers, the integrations and the custom analyzers ignore it.
+	FieldSelector string
+	// PropagateLabels are the keys of the labels, or else annotations, of the
+	// analyzed objects copied to the Labels of their results, read from the
+	// propagate_labels configuration key. The integrations and the custom
+	// analyzers don't copy them.
+	PropagateLabels []string
+	// CacheOnly takes the explanations from the cache only, the AI provider
+	// is never called and the results missing from the cache are left
+	// without Details, e.g. in air-gapped clusters.
+	CacheOnly bool
+	// WithDocBestEffort analyzes without the Kubernetes docs when they can't
+	// be fetched, e.g. without the RBAC to, instead of reporting it in Errors.
+	// It's read from the with_doc_best_effort configuration key.
+	WithDocBestEffort bool
+	// RecheckCount runs the analyzers which found problems that many more
+	// times, RecheckDelay apart, before the results are explained. The
+	// results of the objects cleared in between, e.g. a pod Pending during a
+	// rollout, are dropped. They are read from the recheck.count and
+	// recheck.delay configuration keys. Zero disables the rechecks.
+	RecheckCount int
+	RecheckDelay time.Duration
+	// RequestTimeout bounds each attempt of the AI requests, read from the
+	// ai.request_timeout configuration key. The requests timing out are
+	// retried like the rate limited ones. Zero means no timeout.
+	RequestTimeout time.Duration
+	// RateLimiter spaces all the AI requests of the analysis, retries and
+	// fallbacks included, which wait for it rather than fail. It's made by
+	// NewRateLimiter from the ai.rps configuration key, and may be shared by
+	// several analyses. Nil doesn't limit them.
+	RateLimiter *rate.Limiter
+	// IncludeEvents appends the recent events of the objects of the results
+	// to their failures before they are explained, read from the
+	// include_events configuration key. It lists the events of each result.
+	IncludeEvents bool
+	// IncludeClusterInfo prepends the metadata of their cluster to the
+	// failures of each result before they are explained, read from the
+	// include_cluster_info configuration key. The explanations with and
+	// without it are cached apart.
+	IncludeClusterInfo bool
+	// CompressCache stores the explanations compressed with gzip, read from
+	// the cache.compress configuration key.
+	CompressCache bool
+	// RedactCache redacts the matches of the AnonymizePatterns from the
+	// explanations before caching them, even without anonymizing, read from
+	// the cache.redact configuration key. The explanations of the run are
+	// left as is.
+	RedactCache bool
+	// CheckPermissions reviews with the API server whether the selected
+	// analyzers may list the objects they analyze before launching them. The
+	// ones which may not aren't run, and the missing permissions are reported
+	// in a single error. It's disabled by the no_permission_check
+	// configuration key, sparing the reviews.
+	CheckPermissions bool
+	// Clusters are analyzed instead of Client, each of them separately, and
+	// their results merged, tagged with the name of their cluster. They are
+	// read from the kubecontexts configuration key. Empty analyzes Client.
+	Clusters []Cluster
+	// ShowKinds keeps only the results of these kinds, ignoring case, before
+	// they are explained, e.g. Pod for the results of Pod and Security/Pod.
+	// Unlike Filters they don't select the analyzers run. Empty keeps all the
+	// results.
+	ShowKinds []string
+	// semaphore bounds the analyzers run at once across the Clusters.
+	semaphore chan struct{}
+	// flagged are the runs of the analyzers to recheck.
+	flagged []flaggedRun
+	// clusterInfos holds the metadata of IncludeClusterInfo by cluster name.
+	clusterInfos map[string]string
+	// noAnalyzers is set when none of the Filters exist.
+	noAnalyzers bool
+	// closed makes Close idempotent.
+	closed bool
+}
+
+// AIBackend is a configured AI client along with the configuration pieces which
+// are part of its cache key.
+type AIBackend struct {
+	Client     ai.IAI
+	Model      string
+	BaseURL    string
+	Generation string
 }
 
 type (
 	AnalysisStatus string
-	AnalysisErrors []string
+	AnalysisErrors []*AnalyzerError
 )
 
 const (
 	StateOK              AnalysisStatus = "OK"
 	StateProblemDetected AnalysisStatus = "ProblemDetected"
+	// StateNoAnalyzers tells that no analyzer ran, none of the filters
+	// existing.
+	StateNoAnalyzers AnalysisStatus = "NoAnalyzers"
+	// StatePartialFailure tells that no problem was found but the analysis
+	// is incomplete, e.g. analyzers failed, so the cluster may not be
+	// healthy.
+	StatePartialFailure AnalysisStatus = "PartialFailure"
 )
 
 type JsonOutput struct {
-	Provider string          `json:"provider"`
-	Errors   AnalysisErrors  `json:"errors"`
-	Status   AnalysisStatus  `json:"status"`
-	Problems int             `json:"problems"`
-	Results  []common.Result `json:"results"`
+	Provider         string          `json:"provider"`
+	Errors           AnalysisErrors  `json:"errors"`
+	Status           AnalysisStatus  `json:"status"`
+	Problems         int             `json:"problems"`
+	Results          []common.Result `json:"results"`
+	SkippedAnalyzers []string        `json:"skippedAnalyzers,omitempty"`
+	// TotalResults counts the results before MaxProblems capped them, it is
+	// only set when some were.
+	TotalResults int `json:"totalResults,omitempty"`
+	// TruncatedResults counts the results of each analyzer dropped by
+	// MaxResultsPerAnalyzer.
+	TruncatedResults map[string]int `json:"truncatedResults,omitempty"`
+	Stats            *JsonStats     `json:"stats,omitempty"`
+	Diff             *ResultDiff    `json:"diff,omitempty"`
+}
+
+// JsonStats are the analysis stats included in the JSON output when stats are enabled.
+type JsonStats struct {
+	Analyzers        []common.AnalysisStats `json:"analyzers"`
+	PromptTokens     int                    `json:"promptTokens"`
+	CompletionTokens int                    `json:"completionTokens"`
+	TotalTokens      int                    `json:"totalTokens"`
+	Cache            CacheStats             `json:"cache"`
 }
 
 func NewAnalysis(
@@ -87,101 +346,184 @@ func NewAnalysis(
 	interactiveMode bool,
 	httpHeaders []string,
 	withStats bool,
+) (*Analysis, error) {
+	return NewAnalysisWithContext(context.Background(), backend, language, filters, namespace, labelSelector, noCache, explain, maxConcurrency, withDoc, interactiveMode, httpHeaders, withStats)
+}
+
+// NewAnalysisWithContext is like NewAnalysis, but the analysis stops as soon as
+// ctx is done, e.g. on a deadline or when the user interrupts the run.
+func NewAnalysisWithContext(
+	ctx context.Context,
+	backend string,
+	language string,
+	filters []string,
+	namespace string,
+	labelSelector string,
+	noCache bool,
+	explain bool,
+	maxConcurrency int,
+	withDoc bool,
+	interactiveMode bool,
+	httpHeaders []string,
+	withStats bool,
 ) (*Analysis, error) {
 	// Get kubernetes client from viper.
 	kubecontext := viper.GetString("kubecontext")
 	kubeconfig := viper.GetString("kubeconfig")
-	verbose := viper.GetBool("verbose")
-	client, err := kubernetes.NewClient(kubecontext, kubeconfig)
-	if verbose {
-		fmt.Println("Debug: Checking kubernetes client initialization.")
+	log := NewLogger()
+	var manifestErrors AnalysisErrors
+	var client *kubernetes.Client
+	var clusters []Cluster
+	var err error
+	if manifests := viper.GetString("manifests"); manifests != "" {
+		var ignored []string
+		client, ignored, err = kubernetes.NewOfflineClient(manifests)
+		if err != nil {
+			return nil, fmt.Errorf("loading manifests: %w", err)
+		}
+		for _, manifest := range ignored {
+			manifestErrors = append(manifestErrors, &AnalyzerError{
+				Analyzer: "Manifests",
+				Phase:    PhaseConfiguration,
+				Err:      fmt.Errorf("%s isn't supported offline, ignored", manifest),
+			})
+		}
+		log.Debug("Offline kubernetes client initialized", "manifests", manifests)
+	} else if kubecontexts := viper.GetStringSlice("kubecontexts"); len(kubecontexts) > 0 {
+		clusters, err = newClusters(kubecontexts, kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		client = clusters[0].Client
+	} else {
+		client, err = kubernetes.NewClientWithOptions(kubecontext, kubeconfig, getKubernetesClientOptions())
+		if err != nil {
+			return nil, fmt.Errorf("initialising kubernetes client: %w", err)
+		}
+		log.Debug("Kubernetes client initialized", "server", client.Config.Host)
 	}
+
+	cache, err := loadCache(noCache)
 	if err != nil {
-		return nil, fmt.Errorf("initialising kubernetes client: %w", err)
-	}
-	if verbose {
-		fmt.Printf("Debug: Kubernetes client initialized, server=%s.\n", client.Config.Host)
+		return nil, err
 	}
 
-	// Load remote cache if it is configured.
-	cache, err := cache.GetCacheConfiguration()
-	if verbose {
-		fmt.Println("Debug: Checking cache configuration.")
-	}
+	maxRetries, retryBaseDelay := getRetryConfiguration()
+	anonymizePatterns, err := getAnonymizePatterns()
 	if err != nil {
 		return nil, err
 	}
-	if verbose {
-		fmt.Printf("Debug: Cache configuration loaded, type=%s.\n", cache.GetName())
-	}
-
-	if noCache {
-		cache.DisableCache()
-		if verbose {
-			fmt.Println("Debug: Cache disabled.")
+	var explainMinSeverity common.Severity
+	if name := viper.GetString("explain.min_severity"); name != "" {
+		if explainMinSeverity, err = common.ParseSeverity(name); err != nil {
+			return nil, fmt.Errorf("explain.min_severity: %w", err)
 		}
 	}
-
+	minConfidence := viper.GetFloat64("explain.min_confidence")
+	if minConfidence < 0 || minConfidence > 1 {
+		return nil, fmt.Errorf("explain.min_confidence: %v is not between 0 and 1", minConfidence)
+	}
 	a := &Analysis{
-		Context:        context.Background(),
-		Filters:        filters,
-		Client:         client,
-		Language:       language,
-		Namespace:      namespace,
-		LabelSelector:  labelSelector,
-		Cache:          cache,
-		Explain:        explain,
-		MaxConcurrency: maxConcurrency,
-		WithDoc:        withDoc,
-		WithStats:      withStats,
-	}
-	if verbose {
-		fmt.Print("Debug: Analysis configuration loaded, ")
-		fmt.Printf("filters=%v, language=%s, ", filters, language)
-		if namespace == "" {
-			fmt.Printf("namespace=none, ")
-		} else {
-			fmt.Printf("namespace=%s, ", namespace)
-		}
-		if labelSelector == "" {
-			fmt.Printf("labelSelector=none, ")
-		} else {
-			fmt.Printf("labelSelector=%s, ", labelSelector)
-		}
-		fmt.Printf("explain=%t, maxConcurrency=%d, ", explain, maxConcurrency)
-		fmt.Printf("withDoc=%t, withStats=%t.\n", withDoc, withStats)
+		Context:              ctx,
+		Filters:              filters,
+		Client:               client,
+		Errors:               manifestErrors,
+		Language:             validLanguage(language),
+		Namespace:            namespace,
+		LabelSelector:        labelSelector,
+		Cache:                cache,
+		Explain:              explain,
+		MaxConcurrency:       maxConcurrency,
+		WithDoc:              withDoc,
+		WithStats:            withStats,
+		MaxRetries:           maxRetries,
+		RetryBaseDelay:       retryBaseDelay,
+		BatchSize:            viper.GetInt("ai.batch_size"),
+		AnalyzerTimeout:      viper.GetDuration("analyzer_timeout"),
+		AnonymizePatterns:    anonymizePatterns,
+		NoProgress:           viper.GetBool("no_progress"),
+		NamespaceConcurrency: viper.GetInt("namespace_concurrency"),
+		AIConcurrency:        viper.GetInt("ai.concurrency"),
+		PropagateLabels:      viper.GetStringSlice("propagate_labels"),
+		Webhook:              getWebhookConfiguration(),
+		ExplainMinSeverity:   explainMinSeverity,
+		Confidence:           viper.GetBool("explain.confidence"),
+		MinConfidence:        minConfidence,
+		ExplainRemediation:   viper.GetBool("explain.remediation"),
+		MaxInputLength:       viper.GetInt("ai.max_input_length"),
+		PricePer1K:           viper.GetFloat64("ai.price_per_1k"),
+		Budget:               viper.GetFloat64("ai.budget"),
+		WithDocBestEffort:    viper.GetBool("with_doc_best_effort"),
+		RecheckCount:         viper.GetInt("recheck.count"),
+		RecheckDelay:         viper.GetDuration("recheck.delay"),
+		RequestTimeout:       viper.GetDuration("ai.request_timeout"),
+		RateLimiter:          NewRateLimiter(viper.GetFloat64("ai.rps")),
+		IncludeEvents:        viper.GetBool("include_events"),
+		IncludeClusterInfo:   viper.GetBool("include_cluster_info"),
+		CompressCache:        viper.GetBool("cache.compress"),
+		RedactCache:          viper.GetBool("cache.redact"),
+		CheckPermissions:     !viper.GetBool("no_permission_check"),
+		Clusters:             clusters,
 	}
+	log.Debug("Analysis configuration loaded",
+		"filters", filters,
+		"language", a.Language,
+		"namespace", namespace,
+		"labelSelector", labelSelector,
+		"explain", explain,
+		"maxConcurrency", maxConcurrency,
+		"withDoc", withDoc,
+		"withStats", withStats,
+	)
 	if !explain {
 		// Return early if AI use was not requested.
 		return a, nil
 	}
 
-	var configAI ai.AIConfiguration
-	if verbose {
-		fmt.Println("Debug: Checking AI configuration.")
+	if err := a.configureAI(backend, httpHeaders); err != nil {
+		return nil, err
 	}
-	if err := viper.UnmarshalKey("ai", &configAI); err != nil {
+	return a, nil
+}
+
+// loadCache loads the remote cache if it is configured.
+func loadCache(noCache bool) (cache.ICache, error) {
+	log := NewLogger()
+	cache, err := cache.GetCacheConfiguration()
+	if err != nil {
 		return nil, err
 	}
+	log.Debug("Cache configuration loaded", "type", cache.GetName())
+
+	if noCache {
+		cache.DisableCache()
+		log.Debug("Cache disabled")
+	}
+	return cache, nil
+}
 
-	if len(configAI.Providers) == 0 {
-		return nil, errors.New("AI provider not specified in configuration. Please run k8sgpt auth")
+// configureAI sets up the AI clients and the prompts of the analysis from the
+// ai configuration.
+func (a *Analysis) configureAI(backend string, httpHeaders []string) error {
+	log := a.logger()
+	var configAI ai.AIConfiguration
+	if err := viper.UnmarshalKey("ai", &configAI); err != nil {
+		return err
+	}
+	if err := configAI.Validate(); err != nil {
+		return err
 	}
 
 	// Backend string will have high priority than a default provider
 	// Hence, use the default provider only if the backend is not specified by the user.
 	if configAI.DefaultProvider != "" && backend == "" {
 		backend = configAI.DefaultProvider
-		if verbose {
-			fmt.Printf("Debug: Using default AI provider %s.\n", backend)
-		}
+		log.Debug("Using the default AI provider", "provider", backend)
 	}
 
 	if backend == "" {
 		backend = "openai"
-		if verbose {
-			fmt.Printf("Debug: Using default AI provider %s.\n", backend)
-		}
+		log.Debug("Using the default AI provider", "provider", backend)
 	}
 
 	var aiProvider ai.AIProvider
@@ -192,23 +534,68 @@ func NewAnalysis(
 		}
 	}
 
-	if aiProvider.Name == "" {
-		return nil, fmt.Errorf("AI provider %s not specified in configuration. Please run k8sgpt auth", backend)
+	switch {
+	case aiProvider.Name != "":
+	case ai.IsTestingProvider(backend):
+		// It needs no configuration, e.g. in CI.
+		aiProvider.Name = backend
+	case len(configAI.Providers) == 0:
+		return errors.New("AI provider not specified in configuration. Please run k8sgpt auth")
+	default:
+		return fmt.Errorf("AI provider %s not specified in configuration. Please run k8sgpt auth", backend)
+	}
+
+	if err := applyGenerationOverrides(&aiProvider); err != nil {
+		return err
 	}
+	log.Debug("AI configuration loaded", "provider", backend, "baseURL", aiProvider.BaseURL, "model", aiProvider.Model)
 
-	if verbose {
-		fmt.Printf("Debug: AI configuration loaded, provider=%s, ", backend)
-		fmt.Printf("baseUrl=%s, model=%s.\n", aiProvider.BaseURL, aiProvider.Model)
+	transport, err := configAI.NewTransport()
+	if err != nil {
+		return err
 	}
 
 	aiClient := ai.NewClient(aiProvider.Name)
 	customHeaders := util.NewHeaders(httpHeaders)
 	aiProvider.CustomHeaders = customHeaders
-	if verbose {
-		fmt.Println("Debug: Checking AI client initialization.")
-	}
+	aiProvider.Transport = transport
 	if err := aiClient.Configure(&aiProvider); err != nil {
-		return nil, err
+		return err
+	}
+	ai.SetLanguage(aiClient, a.language())
+
+	// Fallback providers are only used to explain results, when the primary one fails.
+	for _, fallback := range configAI.FallbackProviders {
+		if fallback == aiProvider.Name {
+			continue
+		}
+		var fallbackProvider ai.AIProvider
+		for _, provider := range configAI.Providers {
+			if fallback == provider.Name {
+				fallbackProvider = provider
+				break
+			}
+		}
+		if fallbackProvider.Name == "" {
+			return fmt.Errorf("fallback AI provider %s not specified in configuration. Please run k8sgpt auth", fallback)
+		}
+		if err := applyGenerationOverrides(&fallbackProvider); err != nil {
+			return err
+		}
+		fallbackProvider.CustomHeaders = customHeaders
+		fallbackProvider.Transport = transport
+		fallbackClient := ai.NewClient(fallbackProvider.Name)
+		if err := fallbackClient.Configure(&fallbackProvider); err != nil {
+			return fmt.Errorf("configuring fallback AI provider %s: %w", fallback, err)
+		}
+		ai.SetLanguage(fallbackClient, a.language())
+		a.FallbackAIBackends = append(a.FallbackAIBackends, AIBackend{
+			Client:     fallbackClient,
+			Model:      fallbackProvider.Model,
+			BaseURL:    fallbackProvider.BaseURL,
+			Generation: generationKey(fallbackProvider),
+		})
+		log.Debug("Fallback AI provider initialized", "provider", fallback)
 	}
 	// Initialize prompt map with default prompts
 	promptMap := make(map[string]string)
@@ -216,17 +603,35 @@ func NewAnalysis(
 		promptMap[promptType] = promptTemplate
 	}
 	for promptType, customPrompt := range configAI.PromptMap {
-		if promptType != "raw" {
-			promptMap[promptType] = customPrompt
+		customPrompt, err := interpolatePrompt(customPrompt, configAI.PromptVars)
+		if err != nil {
+			return fmt.Errorf("ai.promptmap.%s: %w", promptType, err)
 		}
+		if promptType == rawPromptType {
+			if err := validateRawPromptTemplate(customPrompt); err != nil {
+				return fmt.Errorf("ai.promptmap.%s: %w", rawPromptType, err)
+			}
+		}
+		promptMap[promptType] = customPrompt
 	}
-	if verbose {
-		fmt.Println("Debug: AI client initialized.")
+	if configAI.PromptDir != "" {
+		templates, err := loadPromptTemplates(configAI.PromptDir, configAI.PromptVars)
+		if err != nil {
+			return err
+		}
+		for promptType, template := range templates {
+			promptMap[promptType] = template
+		}
+		log.Debug("Prompt templates loaded", "templates", len(templates), "directory", configAI.PromptDir)
 	}
+	log.Debug("AI client initialized", "provider", aiProvider.Name)
 	a.AIClient = aiClient
 	a.AnalysisAIProvider = aiProvider.Name
+	a.AIModel = aiProvider.Model
+	a.AIBaseURL = aiProvider.BaseURL
+	a.AIGeneration = generationKey(aiProvider)
 	a.PromptMap = promptMap
-	return a, nil
+	return nil
 }
 
 func (a *Analysis) CustomAnalyzersAreAvailable() bool {
@@ -234,324 +639,1163 @@ func (a *Analysis) CustomAnalyzersAreAvailable() bool {
 	if err := viper.UnmarshalKey("custom_analyzers", &customAnalyzers); err != nil {
 		return false
 	}
-	return len(customAnalyzers) > 0
+	return len(a.validCustomAnalyzers(customAnalyzers)) > 0
+}
+
+// customAnalyzerNameRegex matches the lowercase RFC 1123 subdomains, e.g.
+// 'example.com'. The names of the custom analyzers must match it since they
+// become the Kind of their results.
+var customAnalyzerNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// validCustomAnalyzers drops the custom analyzers with an invalid name, which
+// are reported in a.Errors.
+func (a *Analysis) validCustomAnalyzers(customAnalyzers []custom.CustomAnalyzer) []custom.CustomAnalyzer {
+	var valid []custom.CustomAnalyzer
+	for _, cAnalyzer := range customAnalyzers {
+		if customAnalyzerNameRegex.MatchString(cAnalyzer.Name) {
+			valid = append(valid, cAnalyzer)
+			continue
+		}
+		err := errors.New("invalid custom analyzer name, it must be a lowercase RFC 1123 subdomain (e.g. 'example.com'), analyzer skipped")
+		// CustomAnalyzersAreAvailable and RunCustomAnalysis both validate them.
+		if !slices.ContainsFunc(a.Errors, func(e *AnalyzerError) bool {
+			return e.Analyzer == cAnalyzer.Name && e.Err.Error() == err.Error()
+		}) {
+			a.addError(cAnalyzer.Name, PhaseConfiguration, err)
+		}
+	}
+	return valid
+}
+
+// customAnalyzerNames returns the names of the configured custom analyzers,
+// which are valid filters too.
+func customAnalyzerNames() map[string]bool {
+	var customAnalyzers []custom.CustomAnalyzer
+	// A malformed configuration is reported by RunCustomAnalysis.
+	_ = viper.UnmarshalKey("custom_analyzers", &customAnalyzers)
+	names := map[string]bool{}
+	for _, cAnalyzer := range customAnalyzers {
+		names[cAnalyzer.Name] = true
+	}
+	return names
+}
+
+// selectCustomAnalyzers keeps the custom analyzers selected by the Filters, or
+// by the active filters when there are none, and not excluded. All of them
+// are kept when no filter is set.
+func (a *Analysis) selectCustomAnalyzers(customAnalyzers []custom.CustomAnalyzer) []custom.CustomAnalyzer {
+	filters := a.Filters
+	if len(filters) == 0 {
+		filters = viper.GetStringSlice("active_filters")
+	}
+	selected := map[string]bool{}
+	for _, filter := range filters {
+		selected[filter] = true
+	}
+	excluded := map[string]bool{}
+	for _, filter := range a.ExcludeFilters {
+		excluded[filter] = true
+	}
+
+	var kept []custom.CustomAnalyzer
+	for _, cAnalyzer := range customAnalyzers {
+		if excluded[cAnalyzer.Name] || (len(filters) != 0 && !selected[cAnalyzer.Name]) {
+			a.logger().Debug("Custom analyzer not selected by the filters", "analyzer", cAnalyzer.Name)
+			continue
+		}
+		kept = append(kept, cAnalyzer)
+	}
+	return kept
 }
 
 func (a *Analysis) RunCustomAnalysis() {
 	var customAnalyzers []custom.CustomAnalyzer
 	if err := viper.UnmarshalKey("custom_analyzers", &customAnalyzers); err != nil {
-		a.Errors = append(a.Errors, err.Error())
+		a.addError("CustomAnalyzers", PhaseConfiguration, err)
 		return
 	}
+	customAnalyzers = a.validCustomAnalyzers(customAnalyzers)
 
-	semaphore := make(chan struct{}, a.MaxConcurrency)
+	semaphore := make(chan struct{}, a.concurrency())
 	var wg sync.WaitGroup
-	var mutex sync.Mutex
-	verbose := viper.GetBool("verbose")
-	if verbose {
-		if len(customAnalyzers) == 0 {
-			fmt.Println("Debug: No custom analyzers found.")
-		} else {
-			cAnalyzerNames := make([]string, len(customAnalyzers))
-			for i, cAnalyzer := range customAnalyzers {
-				cAnalyzerNames[i] = cAnalyzer.Name
-			}
-			fmt.Printf("Debug: Found custom analyzers %v.\n", cAnalyzerNames)
+	log := a.logger()
+	if len(customAnalyzers) == 0 {
+		log.Debug("No custom analyzers found")
+	} else {
+		cAnalyzerNames := make([]string, len(customAnalyzers))
+		for i, cAnalyzer := range customAnalyzers {
+			cAnalyzerNames[i] = cAnalyzer.Name
 		}
+		log.Debug("Found custom analyzers", "analyzers", cAnalyzerNames)
 	}
+	customAnalyzers = a.selectCustomAnalyzers(customAnalyzers)
+	ctx := a.contextOrBackground()
+	defer a.recordCutShort()
+	defer a.finishResults()
+	c := a.startCollector()
 	for _, cAnalyzer := range customAnalyzers {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			c.report(analyzerReport{label: cAnalyzer.Name, cutShort: true})
+			continue
+		}
 		wg.Add(1)
-		semaphore <- struct{}{}
 		go func(analyzer custom.CustomAnalyzer, wg *sync.WaitGroup, semaphore chan struct{}) {
 			defer wg.Done()
+			defer func() { <-semaphore }()
 			canClient, err := custom.NewClient(cAnalyzer.Connection)
 			if err != nil {
-				mutex.Lock()
-				a.Errors = append(a.Errors, fmt.Sprintf("Client creation error for %s analyzer", cAnalyzer.Name))
-				mutex.Unlock()
+				c.report(analyzerReport{name: cAnalyzer.Name, err: &AnalyzerError{Analyzer: cAnalyzer.Name, Phase: PhaseAnalysis, Err: fmt.Errorf("client creation error: %w", err)}})
 				return
 			}
-			if verbose {
-				fmt.Printf("Debug: %s launched.\n", cAnalyzer.Name)
-			}
+			defer canClient.Close()
+			log.Debug("Analyzer launched", "analyzer", cAnalyzer.Name)
+			startTime := time.Now()
 
-			result, err := canClient.Run()
+			analyzerCtx := ctx
+			if a.AnalyzerTimeout > 0 {
+				var cancel context.CancelFunc
+				analyzerCtx, cancel = context.WithTimeout(ctx, a.AnalyzerTimeout)
+				defer cancel()
+			}
+			result, err := canClient.Run(analyzerCtx)
+			if ctx.Err() != nil {
+				c.report(analyzerReport{label: cAnalyzer.Name, cutShort: true})
+				return
+			}
+			if errors.Is(analyzerCtx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s", a.AnalyzerTimeout)
+			}
 			if result.Kind == "" {
-				// for custom analyzer name, we must use a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.',
-				//and must start and end with an alphanumeric character (e.g. 'example.com',
-				//regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')
+				// The name was validated against customAnalyzerNameRegex.
 				result.Kind = cAnalyzer.Name
 			}
 			if err != nil {
-				mutex.Lock()
-				a.Errors = append(a.Errors, fmt.Sprintf("[%s] %s", cAnalyzer.Name, err))
-				mutex.Unlock()
-				if verbose {
-					fmt.Printf("Debug: %s completed with errors.\n", cAnalyzer.Name)
-				}
+				c.report(analyzerReport{name: cAnalyzer.Name, err: &AnalyzerError{Analyzer: cAnalyzer.Name, Phase: PhaseAnalysis, Err: err}})
+				log.Debug("Analyzer completed with errors", "analyzer", cAnalyzer.Name, "duration", time.Since(startTime), "error", err)
 			} else {
-				mutex.Lock()
-				a.Results = append(a.Results, result)
-				mutex.Unlock()
-				if verbose {
-					fmt.Printf("Debug: %s completed without errors.\n", cAnalyzer.Name)
-				}
+				c.report(analyzerReport{name: cAnalyzer.Name, results: []common.Result{result}})
+				log.Debug("Analyzer completed", "analyzer", cAnalyzer.Name, "duration", time.Since(startTime))
 			}
-			<-semaphore
 		}(cAnalyzer, &wg, semaphore)
 	}
 	wg.Wait()
+	c.wait()
 }
 
 func (a *Analysis) RunAnalysis() {
-	activeFilters := viper.GetStringSlice("active_filters")
-	verbose := viper.GetBool("verbose")
+	defer a.metrics().AnalysisCompleted()
+	if len(a.Clusters) > 0 {
+		a.runClusters()
+		return
+	}
+	a.runAnalysis()
+}
 
-	coreAnalyzerMap, analyzerMap := analyzer.GetAnalyzerMap()
+// runAnalysis runs the analyzers against a.Client.
+func (a *Analysis) runAnalysis() {
+	log := a.logger()
+
+	_, analyzerMap := analyzer.GetAnalyzerMap()
 
 	// we get the openapi schema from the server only if required by the flag "with-doc"
 	openapiSchema := &openapi_v2.Document{}
 	if a.WithDoc {
 		var openApiErr error
 
-		if verbose {
-			fmt.Println("Debug: Fetching Kubernetes docs.")
-		}
+		log.Debug("Fetching the Kubernetes docs")
 		openapiSchema, openApiErr = a.Client.Client.Discovery().OpenAPISchema()
-		if verbose {
-			fmt.Println("Debug: Checking Kubernetes docs.")
-		}
 		if openApiErr != nil {
-			a.Errors = append(a.Errors, fmt.Sprintf("[KubernetesDoc] %s", openApiErr))
+			openapiSchema = &openapi_v2.Document{}
+			if !a.WithDocBestEffort {
+				a.addError("KubernetesDoc", PhaseDocumentation, openApiErr)
+			} else {
+				log.Debug("Kubernetes docs unavailable, analyzing without them", "error", openApiErr)
+			}
 		}
 	}
 
 	analyzerConfig := common.Analyzer{
-		Client:        a.Client,
-		Context:       a.Context,
-		Namespace:     a.Namespace,
-		LabelSelector: a.LabelSelector,
-		AIClient:      a.AIClient,
-		OpenapiSchema: openapiSchema,
+		Client:          a.Client,
+		Context:         a.Context,
+		Namespace:       a.Namespace,
+		LabelSelector:   a.LabelSelector,
+		FieldSelector:   a.FieldSelector,
+		AIClient:        a.AIClient,
+		OpenapiSchema:   openapiSchema,
+		PropagateLabels: a.PropagateLabels,
+	}
+	if a.Since > 0 {
+		analyzerConfig.Since = time.Now().Add(-a.Since)
 	}
 
-	// Set a reasonable maximum for concurrency to prevent excessive memory allocation
-	const maxAllowedConcurrency = 100
-	concurrency := a.MaxConcurrency
-	if concurrency <= 0 {
-		concurrency = 10 // Default value if not set
-	} else if concurrency > maxAllowedConcurrency {
-		concurrency = maxAllowedConcurrency // Cap at a reasonable maximum
+	// The clusters share the semaphore, bounding the analyzers run at once
+	// across all of them.
+	semaphore := a.semaphore
+	if semaphore == nil {
+		semaphore = make(chan struct{}, a.concurrency())
+	}
+	names := a.resolveAnalyzers()
+	if a.Client != nil && a.Client.Offline {
+		for _, name := range names {
+			a.warnOffline(name)
+		}
+	}
+	namespaces := a.shardNamespaces()
+	var denied map[string]bool
+	if a.CheckPermissions && a.Client != nil && !a.Client.Offline {
+		denied = a.checkPermissions(names, namespaces)
 	}
 
-	semaphore := make(chan struct{}, concurrency)
 	var wg sync.WaitGroup
-	var mutex sync.Mutex
-	// if there are no filters selected and no active_filters then run coreAnalyzer
-	if len(a.Filters) == 0 && len(activeFilters) == 0 {
-		if verbose {
-			fmt.Println("Debug: No filters selected and no active filters found, run all core analyzers.")
+	c := a.startCollector()
+	startTime := time.Now()
+	ctx := a.contextOrBackground()
+	// launch runs the analyzer with config, label names it in the skipped and
+	// cut short analyzers. release is called once the analyzer is done or
+	// isn't started.
+	launch := func(analyzer common.IAnalyzer, name string, label string, config common.Analyzer, release func()) {
+		if denied[label] {
+			release()
+			return
 		}
-		for name, analyzer := range coreAnalyzerMap {
-			wg.Add(1)
-			semaphore <- struct{}{}
-			go a.executeAnalyzer(analyzer, name, analyzerConfig, semaphore, &wg, &mutex)
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			// Stop launching analyzers once the run is cancelled.
+			c.report(analyzerReport{label: label, cutShort: true})
+			release()
+			return
+		}
+		if a.ExecutionBudget > 0 && time.Since(startTime) > a.ExecutionBudget {
+			<-semaphore
+			c.report(analyzerReport{label: label, skipped: true})
+			log.Debug("Analyzer skipped, execution budget exceeded", "analyzer", label, "budget", a.ExecutionBudget)
+			release()
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer release()
+			a.executeAnalyzer(analyzer, name, config, semaphore, &wg, c)
+		}()
+	}
+	defer func() { sort.Strings(a.SkippedAnalyzers) }()
+	defer a.recordCutShort()
+	defer a.finishResults()
 
+	var shards sync.WaitGroup
+	for _, namespace := range namespaces {
+		shards.Add(1)
+		go func(namespace string) {
+			defer shards.Done()
+			// Bounds the analyzers in flight for the namespace, on top of the
+			// global concurrency.
+			inflight := make(chan struct{}, a.namespaceConcurrency())
+			for _, name := range names {
+				if clusterScopedAnalyzers[name] {
+					continue
+				}
+				label := fmt.Sprintf("%s (%s)", name, namespace)
+				select {
+				case inflight <- struct{}{}:
+				case <-ctx.Done():
+					c.report(analyzerReport{label: label, cutShort: true})
+					continue
+				}
+				shardConfig := analyzerConfig
+				shardConfig.Namespace = namespace
+				launch(analyzerMap[name], name, label, shardConfig, func() { <-inflight })
+			}
+		}(namespace)
+	}
+	for _, name := range names {
+		if namespaces != nil && !clusterScopedAnalyzers[name] {
+			continue
 		}
-		wg.Wait()
-		return
+		launch(analyzerMap[name], name, name, analyzerConfig, func() {})
+	}
+	shards.Wait()
+	wg.Wait()
+	c.wait()
+	if a.RecheckCount > 0 {
+		a.recheckResults()
+	}
+}
+
+// clusterScopedAnalyzers analyze cluster-scoped objects, so they run once when
+// the analysis is sharded by namespace.
+var clusterScopedAnalyzers = map[string]bool{
+	"Node":                           true,
+	"ValidatingWebhookConfiguration": true,
+	"MutatingWebhookConfiguration":   true,
+	"GatewayClass":                   true,
+	"Storage":                        true,
+}
+
+// shardNamespaces returns the namespaces the analyzers run in separately: the
+// Namespaces, or all namespaces when NamespaceConcurrency is set and all
+// namespaces are analyzed, nil otherwise.
+func (a *Analysis) shardNamespaces() []string {
+	if len(a.Namespaces) > 0 {
+		return a.Namespaces
+	}
+	if a.NamespaceConcurrency <= 0 || a.Namespace != "" {
+		return nil
+	}
+	list, err := a.Client.GetClient().CoreV1().Namespaces().List(a.contextOrBackground(), metav1.ListOptions{})
+	if err != nil {
+		a.addError("Namespaces", PhaseAnalysis, fmt.Errorf("listing namespaces, analysis not sharded by namespace: %w", err))
+		return nil
+	}
+	namespaces := []string{}
+	for _, namespace := range list.Items {
+		namespaces = append(namespaces, namespace.Name)
+	}
+	return namespaces
+}
+
+// namespaceConcurrency is the number of analyzers run at once in a namespace,
+// only bounded by the global concurrency when NamespaceConcurrency isn't set.
+func (a *Analysis) namespaceConcurrency() int {
+	if a.NamespaceConcurrency > 0 {
+		return a.NamespaceConcurrency
+	}
+	return a.concurrency()
+}
+
+// resolveAnalyzers returns the names of the analyzers selected by the filters
+// flag, or else the active filters, or else the core analyzers, minus the
+// ExcludeFilters. Filters which don't exist are reported in a.Errors, along
+// with ErrNoAnalyzers when none of them do.
+func (a *Analysis) resolveAnalyzers() []string {
+	a.noAnalyzers = false
+	activeFilters := viper.GetStringSlice("active_filters")
+	log := a.logger()
+	coreAnalyzerMap, analyzerMap := analyzer.GetAnalyzerMap()
+	// The filters naming custom analyzers select them in RunCustomAnalysis.
+	customNames := customAnalyzerNames()
+
+	excluded := map[string]bool{}
+	for _, filter := range a.ExcludeFilters {
+		if _, ok := analyzerMap[filter]; !ok {
+			if !customNames[filter] {
+				a.addError(filter, PhaseConfiguration, &unknownFilterError{filter})
+			}
+			continue
+		}
+		excluded[filter] = true
+	}
+	var names []string
+	selectAnalyzer := func(name string) {
+		if excluded[name] {
+			log.Debug("Analyzer excluded", "analyzer", name)
+			return
+		}
+		names = append(names, name)
+	}
+
+	// if there are no filters selected and no active_filters then run coreAnalyzer
+	if len(a.Filters) == 0 && len(activeFilters) == 0 {
+		log.Debug("No filters selected and no active filters found, running all core analyzers")
+		// In a stable order, unlike the map's.
+		for _, name := range slices.Sorted(maps.Keys(coreAnalyzerMap)) {
+			selectAnalyzer(name)
+		}
+		return names
 	}
 	// if the filters flag is specified
 	if len(a.Filters) != 0 {
-		if verbose {
-			fmt.Printf("Debug: Filter flags %v specified, run selected core analyzers.\n", a.Filters)
-		}
+		log.Debug("Filter flags specified, running the selected core analyzers", "filters", a.Filters)
+		unknown := 0
 		for _, filter := range a.Filters {
-			if analyzer, ok := analyzerMap[filter]; ok {
-				semaphore <- struct{}{}
-				wg.Add(1)
-				go a.executeAnalyzer(analyzer, filter, analyzerConfig, semaphore, &wg, &mutex)
-			} else {
-				a.Errors = append(a.Errors, fmt.Sprintf("\"%s\" filter does not exist. Please run k8sgpt filters list.", filter))
+			if _, ok := analyzerMap[filter]; ok {
+				selectAnalyzer(filter)
+			} else if !customNames[filter] {
+				a.addError(filter, PhaseConfiguration, &unknownFilterError{filter})
+				unknown++
 			}
 		}
-		wg.Wait()
-		return
+		if unknown == len(a.Filters) {
+			a.noAnalyzers = true
+			a.addError("Filters", PhaseConfiguration, ErrNoAnalyzers)
+		}
+		return names
 	}
 
 	// use active_filters
-	if len(activeFilters) > 0 && verbose {
-		fmt.Printf("Debug: Found active filters %v, run selected core analyzers.\n", activeFilters)
+	if len(activeFilters) > 0 {
+		log.Debug("Found active filters, running the selected core analyzers", "filters", activeFilters)
 	}
 	for _, filter := range activeFilters {
-		if analyzer, ok := analyzerMap[filter]; ok {
-			semaphore <- struct{}{}
-			wg.Add(1)
-			go a.executeAnalyzer(analyzer, filter, analyzerConfig, semaphore, &wg, &mutex)
+		if _, ok := analyzerMap[filter]; ok {
+			selectAnalyzer(filter)
 		}
 	}
-	wg.Wait()
+	return names
+}
+
+// defaultAIConcurrency is the number of results explained at once without
+// AIConcurrency, low enough for the rate limits of most AI providers.
+const defaultAIConcurrency = 3
+
+// aiConcurrency returns the number of results explained at once.
+func (a *Analysis) aiConcurrency() int {
+	const maxAllowedConcurrency = 100
+	if a.AIConcurrency <= 0 {
+		return defaultAIConcurrency
+	}
+	return min(a.AIConcurrency, maxAllowedConcurrency)
+}
+
+// concurrency returns the number of analyzers run at once.
+func (a *Analysis) concurrency() int {
+	// Set a reasonable maximum for concurrency to prevent excessive memory allocation
+	const maxAllowedConcurrency = 100
+	concurrency := a.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = 10 // Default value if not set
+	} else if concurrency > maxAllowedConcurrency {
+		concurrency = maxAllowedConcurrency // Cap at a reasonable maximum
+	}
+	return concurrency
 }
 
-func (a *Analysis) executeAnalyzer(analyzer common.IAnalyzer, filter string, analyzerConfig common.Analyzer, semaphore chan struct{}, wg *sync.WaitGroup, mutex *sync.Mutex) {
+// executeAnalyzer runs analyzer as filter and reports the outcome to c.
+func (a *Analysis) executeAnalyzer(analyzer common.IAnalyzer, filter string, analyzerConfig common.Analyzer, semaphore chan struct{}, wg *sync.WaitGroup, c *collector) {
 	defer wg.Done()
+	defer func() { <-semaphore }()
 
-	var startTime time.Time
 	var elapsedTime time.Duration
 
 	// Start the timer
-	if a.WithStats {
-		startTime = time.Now()
-	}
+	startTime := time.Now()
 
 	// Run the analyzer
-	verbose := viper.GetBool("verbose")
-	if verbose {
-		fmt.Printf("Debug: %s launched.\n", reflect.TypeOf(analyzer).Name())
+	log := a.logger().With("analyzer", reflect.TypeOf(analyzer).Name())
+	if analyzerConfig.Namespace != "" {
+		log = log.With("namespace", analyzerConfig.Namespace)
 	}
-	results, err := analyzer.Analyze(analyzerConfig)
-	if err != nil {
-		fmt.Println(err)
+	log.Debug("Analyzer launched")
+	ctx := a.contextOrBackground()
+	analyzerCtx := ctx
+	if a.AnalyzerTimeout > 0 {
+		var cancel context.CancelFunc
+		analyzerCtx, cancel = context.WithTimeout(ctx, a.AnalyzerTimeout)
+		defer cancel()
+	}
+	// analyzerConfig is a copy, the deadline and count only apply to this analyzer.
+	analyzerConfig.Context = analyzerCtx
+	analyzerConfig.Scanned = &common.ScanCount{}
+	results, err := runUntilDone(analyzerCtx, func() ([]common.Result, error) {
+		return analyzer.Analyze(analyzerConfig)
+	})
+	if ctx.Err() != nil {
+		c.report(analyzerReport{label: filter, cutShort: true})
+		log.Debug("Analyzer interrupted", "duration", time.Since(startTime))
+		return
+	}
+	if errors.Is(analyzerCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", a.AnalyzerTimeout)
 	}
 	// Measure the time taken
+	duration := time.Since(startTime)
+	a.metrics().AnalyzerCompleted(filter, duration, err != nil)
 	if a.WithStats {
-		elapsedTime = time.Since(startTime)
+		elapsedTime = duration
 	}
 	stat := common.AnalysisStats{
 		Analyzer:     filter,
 		DurationTime: elapsedTime,
 	}
+	if objects, ok := analyzerConfig.Scanned.Objects(); ok {
+		stat.ObjectsScanned = &objects
+	}
+	for _, result := range results {
+		stat.Problems += len(result.Error)
+	}
 
-	mutex.Lock()
-	defer mutex.Unlock()
-
+	report := analyzerReport{name: filter, results: results, analyzer: analyzer, config: analyzerConfig}
+	if a.WithStats {
+		report.stat = &stat
+	}
 	if err != nil {
-		if a.WithStats {
-			a.Stats = append(a.Stats, stat)
-		}
-		a.Errors = append(a.Errors, fmt.Sprintf("[%s] %s", filter, err))
-		if verbose {
-			fmt.Printf("Debug: %s completed with errors.\n", reflect.TypeOf(analyzer).Name())
+		report.err = &AnalyzerError{
+			Analyzer:  filter,
+			Phase:     PhaseAnalysis,
+			Namespace: analyzerConfig.Namespace,
+			Partial:   len(results) > 0,
+			Err:       err,
 		}
+	}
+	c.report(report)
+	if err != nil {
+		log.Debug("Analyzer completed with errors", "duration", duration, "results", len(results), "error", err)
 	} else {
-		if a.WithStats {
-			a.Stats = append(a.Stats, stat)
+		log.Debug("Analyzer completed", "duration", duration, "results", len(results))
+	}
+}
+
+// finishResults deduplicates, prioritizes and caps the results once the
+// analyzers of a run returned. The results capped by a previous run are
+// considered again along with the new ones.
+func (a *Analysis) finishResults() {
+	a.Results = append(a.Results, a.cappedResults...)
+	a.cappedResults = nil
+	// Collapse duplicates before prioritizing, so they are explained only once.
+	a.deduplicateResults()
+	a.prioritizeResults()
+	// Once sorted, so the most severe results of each analyzer are kept.
+	a.capAnalyzerResults()
+	a.capResults()
+}
+
+// capResults keeps the MaxProblems first results and sets aside the others.
+func (a *Analysis) capResults() {
+	if a.MaxProblems <= 0 || len(a.Results) <= a.MaxProblems {
+		return
+	}
+	a.cappedResults = slices.Clone(a.Results[a.MaxProblems:])
+	a.Results = a.Results[:a.MaxProblems]
+	a.logger().Debug("Results capped, --max-problems reached", "shown", len(a.Results), "total", a.totalResults())
+}
+
+// totalResults counts the results, including the ones capped by MaxProblems.
+func (a *Analysis) totalResults() int {
+	return len(a.Results) + len(a.cappedResults)
+}
+
+// deduplicateResults collapses the results of the same Kind and Name reporting
+// the same failures, e.g. found by both a core and a custom analyzer, into the
+// first of them. DetectedBy counts the collapsed results.
+func (a *Analysis) deduplicateResults() {
+	seen := map[string]int{}
+	results := a.Results[:0]
+	for _, result := range a.Results {
+		key := resultKey(result)
+		index, ok := seen[key]
+		if !ok {
+			seen[key] = len(results)
+			results = append(results, result)
+			continue
+		}
+		first := &results[index]
+		if first.DetectedBy == 0 {
+			first.DetectedBy = 1
+		}
+		first.DetectedBy++
+		if result.Severity.Rank() > first.Severity.Rank() {
+			first.Severity = result.Severity
+		}
+		for key, value := range result.Labels {
+			if _, ok := first.Labels[key]; ok {
+				continue
+			}
+			if first.Labels == nil {
+				first.Labels = map[string]string{}
+			}
+			first.Labels[key] = value
+		}
+	}
+	a.Results = results
+}
+
+// resultKey identifies a result by its Cluster, Kind, Name and normalized
+// failure texts, without the events of IncludeEvents.
+func resultKey(result common.Result) string {
+	texts := make([]string, 0, len(result.Error))
+	for _, failure := range result.Error {
+		text, _, _ := strings.Cut(failure.Text, eventsHeader)
+		texts = append(texts, normalizeFailureText(text))
+	}
+	sort.Strings(texts)
+	texts = slices.Compact(texts)
+	return strings.Join(append([]string{result.Cluster, result.Kind, result.Name}, texts...), "\x00")
+}
+
+// normalizeFailureText ignores case and whitespace differences.
+func normalizeFailureText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// prioritizeResults defaults the severity of the results to Warning, drops the
+// ones below MinSeverity or not of ShowKinds and sorts the others by
+// descending severity, then by Cluster, Kind, namespace and name, so that the
+// output of repeated runs is the same whatever order the analyzers completed
+// in.
+func (a *Analysis) prioritizeResults() {
+	results := a.Results[:0]
+	for _, result := range a.Results {
+		if result.Severity == "" {
+			result.Severity = common.SeverityWarning
+		}
+		if result.Severity.Rank() < a.MinSeverity.Rank() || !a.showsKind(result.Kind) {
+			continue
+		}
+		results = append(results, result)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return compareResults(results[i], results[j]) < 0
+	})
+	a.Results = results
+}
+
+// showsKind tells whether the results of kind are kept by ShowKinds, either
+// the whole kind or the kind of object after its last slash matching.
+func (a *Analysis) showsKind(kind string) bool {
+	if len(a.ShowKinds) == 0 {
+		return true
+	}
+	object := kind[strings.LastIndex(kind, "/")+1:]
+	for _, shown := range a.ShowKinds {
+		if strings.EqualFold(shown, kind) || strings.EqualFold(shown, object) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareResults(first common.Result, second common.Result) int {
+	return cmp.Or(
+		cmp.Compare(second.Severity.Rank(), first.Severity.Rank()),
+		cmp.Compare(first.Cluster, second.Cluster),
+		cmp.Compare(first.Kind, second.Kind),
+		cmp.Compare(resultNamespace(first), resultNamespace(second)),
+		// The namespace being the same, so is the prefix of the names.
+		cmp.Compare(first.Name, second.Name),
+		// The same object may have distinct results, e.g. by distinct analyzers.
+		cmp.Compare(resultKey(first), resultKey(second)),
+	)
+}
+
+// runUntilDone returns the outcome of fn, or the error of ctx when it is done
+// first. In that case fn is left running in the background and its outcome is
+// dropped.
+func runUntilDone[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	type outcome struct {
+		value T
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := fn()
+		done <- outcome{value, err}
+	}()
+	select {
+	case o := <-done:
+		return o.value, o.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// recordCutShort reports the analyzers which didn't complete because the
+// context of the analysis was done. It must be called once all of them returned.
+func (a *Analysis) recordCutShort() {
+	if len(a.cutShort) == 0 {
+		return
+	}
+	sort.Strings(a.cutShort)
+	a.addError("Analysis", PhaseAnalysis, fmt.Errorf("run cut short (%w), analyzers not completed: %s", a.contextOrBackground().Err(), strings.Join(a.cutShort, ", ")))
+	a.cutShort = nil
+}
+
+// Analyze runs the custom analyzers when CustomAnalysis is set and the core
+// analyzers, explains the results when Explain is set and returns the output.
+// Unlike the CLI it doesn't draw a progress bar, formatting is up to the caller.
+// When explaining the results fails, the output is returned with the error.
+func (a *Analysis) Analyze() (JsonOutput, error) {
+	if a.CustomAnalysis {
+		a.RunCustomAnalysis()
+	}
+	a.RunAnalysis()
+
+	if a.Explain {
+		if err := a.explainResults(false, a.Anonymize); err != nil {
+			// The results explained before the failure are returned too.
+			return a.getJsonOutput(), err
 		}
-		a.Results = append(a.Results, results...)
-		if verbose {
-			fmt.Printf("Debug: %s completed without errors.\n", reflect.TypeOf(analyzer).Name())
+	}
+	a.SendWebhook()
+	output := a.getJsonOutput()
+	if a.Sink != nil {
+		if err := a.Sink.Write(output); err != nil {
+			return output, err
 		}
 	}
-	<-semaphore
+	return output, nil
 }
 
+// GetAIResults explains the results for output, the comma-separated formats
+// written to the console. The progress bar is only drawn when none of them is
+// machine readable, e.g. for the text output alone.
 func (a *Analysis) GetAIResults(output string, anonymize bool) error {
+	return a.explainResults(!isMachineReadableOutput(output), anonymize)
+}
+
+// explainResults fills the Details of the results from the AI provider,
+// drawing a progress bar when showProgress is set. When the AI provider fails,
+// it returns an *ExplanationError and the results explained before the
+// failure keep their Details.
+func (a *Analysis) explainResults(showProgress bool, anonymize bool) error {
 	if len(a.Results) == 0 {
 		return nil
 	}
 
 	verbose := viper.GetBool("verbose")
-	if verbose {
-		fmt.Println("Debug: Generating AI analysis.")
+	log := a.logger()
+	log.Debug("Generating the AI analysis", "results", len(a.Results))
+	a.explanationErr = nil
+	if a.IncludeEvents {
+		a.addEvents()
+	}
+	if a.IncludeClusterInfo {
+		a.addClusterInfo(anonymize)
+	}
+	// Cached explanations cost nothing.
+	if !a.CacheOnly && !a.withinBudget() {
+		return nil
 	}
 
 	var bar *progressbar.ProgressBar
-	if output != "json" {
-		bar = progressbar.Default(int64(len(a.Results)))
+	streaming := showProgress && a.Stream
+	if streaming {
+		a.onChunk = func(chunk string) { fmt.Print(chunk) }
+		defer func() { a.onChunk = nil }()
+	} else if showProgress {
+		if a.NoProgress {
+			fmt.Printf("Analyzing %d results\n", len(a.Results))
+		} else {
+			bar = progressbar.Default(int64(len(a.Results)))
+		}
 	}
 
-	for index, analysis := range a.Results {
-		var texts []string
+	// The unchanged results keep the explanations of the previous analysis.
+	reused := a.previousDetails()
+	batched := a.getBatchedAIResults(anonymize, reused)
 
-		if bar != nil && verbose {
-			bar.Describe(fmt.Sprintf("Analyzing %s", analysis.Kind))
+	// The failures are masked before the workers start, so the pseudonyms are
+	// numbered in the order of the results.
+	texts := make([][]string, len(a.Results))
+	for index, analysis := range a.Results {
+		_, isBatched := batched[index]
+		_, isReused := reused[index]
+		if !isBatched && !isReused && a.explained(analysis) {
+			texts[index] = a.sanitizedFailureTexts(analysis, anonymize)
 		}
+	}
 
-		for _, failure := range analysis.Error {
-			if anonymize {
-				for _, s := range failure.Sensitive {
-					failure.Text = util.ReplaceIfMatch(failure.Text, s.Unmasked, s.Masked)
-				}
+	// The first failure cancels the requests of the other workers.
+	ctx, cancel := context.WithCancel(a.contextOrBackground())
+	defer cancel()
+	parentCtx, parentCache := a.Context, a.Cache
+	a.Context, a.Cache = ctx, &syncCache{ICache: parentCache}
+	defer func() { a.Context, a.Cache = parentCtx, parentCache }()
+
+	concurrency := a.aiConcurrency()
+	if streaming {
+		// Streamed explanations would interleave.
+		concurrency = 1
+	}
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	a.statsMutex = &mutex
+	defer func() { a.statsMutex = nil }()
+
+	details := make([]string, len(a.Results))
+	providers := make([]string, len(a.Results))
+	confidences := make([]*float64, len(a.Results))
+	cached := make([]bool, len(a.Results))
+	// Results are written back as they complete, so that they are streamed to
+	// ResultLines, including the ones explained before a failure.
+	writeBack := func(index int) {
+		if providers[index] == "" {
+			return
+		}
+		result := details[index]
+		if _, ok := reused[index]; anonymize && !ok {
+			result = a.unmaskDetails(a.Results[index], result)
+		}
+		a.Results[index].Provider = providers[index]
+		a.Results[index].Confidence = confidences[index]
+		a.Results[index].Cached = cached[index]
+		if a.belowMinConfidence(confidences[index]) {
+			log.Debug("Explanation dropped, confidence below the minimum", "kind", a.Results[index].Kind, "name", a.Results[index].Name, "confidence", *confidences[index], "minConfidence", a.MinConfidence)
+			return
+		}
+		a.Results[index].Details = a.setRemediation(&a.Results[index], result)
+	}
+	var firstErr error
+	completed := func(index int, result string, provider string, confidence *float64, fromCache bool, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+			return
+		}
+		details[index] = result
+		providers[index] = provider
+		confidences[index] = confidence
+		cached[index] = fromCache
+		writeBack(index)
+		a.writeResultLine(index)
+		if bar != nil {
+			if verbose {
+				bar.Describe(fmt.Sprintf("Analyzing %s", a.Results[index].Kind))
 			}
-			texts = append(texts, failure.Text)
+			_ = bar.Add(1)
 		}
+	}
 
-		promptTemplate := a.PromptMap["default"]
-		// If the resource `Kind` comes from an "integration plugin",
-		// maybe a customized prompt template will be involved.
-		if prompt, ok := a.PromptMap[analysis.Kind]; ok {
-			promptTemplate = prompt
+launch:
+	for index, analysis := range a.Results {
+		if !a.explained(analysis) {
+			// Left without Details, and before the cache lookup.
+			completed(index, "", "", nil, false, nil)
+			continue
 		}
-		result, err := a.getAIResultForSanitizedFailures(texts, promptTemplate)
-		if err != nil {
-			// FIXME: can we avoid checking if output is json multiple times?
-			//   maybe implement the progress bar better?
-			if output != "json" {
-				_ = bar.Exit()
+		if prior, ok := reused[index]; ok {
+			completed(index, prior.Details, prior.Provider, prior.Confidence, prior.Cached, nil)
+			continue
+		}
+		if result, ok := batched[index]; ok {
+			// The answers of a batch have no confidence of their own.
+			completed(index, result, a.AIClient.GetName(), nil, false, nil)
+			continue
+		}
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			completed(index, "", "", nil, false, ctx.Err())
+			break launch
+		}
+		wg.Add(1)
+		go func(index int, analysis common.Result) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			if streaming {
+				fmt.Printf("%s %s:\n", analysis.Kind, analysis.Name)
 			}
+			result, provider, confidence, fromCache, err := a.getAIResultForSanitizedFailures(analysis.Kind, texts[index], a.promptTemplate(analysis.Kind))
+			if streaming {
+				fmt.Println()
+			}
+			completed(index, result, provider, confidence, fromCache, err)
+		}(index, analysis)
+	}
+	wg.Wait()
 
-			// Check for exhaustion.
-			if strings.Contains(err.Error(), "status code: 429") {
-				return fmt.Errorf("exhausted API quota for AI provider %s: %v", a.AIClient.GetName(), err)
+	if firstErr != nil {
+		if bar != nil {
+			_ = bar.Exit()
+		}
+
+		explanationErr := &ExplanationError{Err: fmt.Errorf("failed while calling AI provider %s: %v", a.AIClient.GetName(), firstErr)}
+		// Check for exhaustion.
+		if strings.Contains(firstErr.Error(), "status code: 429") {
+			explanationErr.Err = fmt.Errorf("exhausted API quota for AI provider %s: %v", a.AIClient.GetName(), firstErr)
+		} else if errors.Is(firstErr, errRequestTimeout) {
+			explanationErr.Err = fmt.Errorf("timed out waiting for AI provider %s: %v", a.AIClient.GetName(), firstErr)
+		}
+		a.explanationErr = explanationErr
+		for index, result := range a.Results {
+			switch {
+			case !a.explained(result):
+			case providers[index] != "":
+				explanationErr.Explained++
+			default:
+				explanationErr.Unexplained++
+			}
+		}
+		return explanationErr
+	}
+	if !a.Cache.IsCacheDisabled() {
+		stats := a.CacheStats()
+		log.Debug("Cache used", "hits", stats.Hits, "misses", stats.Misses)
+	}
+	if a.CacheOnly {
+		uncached := 0
+		for index := range a.Results {
+			if texts[index] != nil && providers[index] == "" {
+				uncached++
 			}
-			return fmt.Errorf("failed while calling AI provider %s: %v", a.AIClient.GetName(), err)
 		}
+		log.Debug("Results without a cached explanation", "results", uncached)
+	}
+	return nil
+}
+
+// unmaskDetails restores the data of result masked by sanitizedFailureTexts in
+// its explanation.
+func (a *Analysis) unmaskDetails(result common.Result, details string) string {
+	for _, failure := range result.Error {
+		for _, s := range failure.Sensitive {
+			details = strings.ReplaceAll(details, s.Masked, s.Unmasked)
+		}
+	}
+	return a.unmaskPseudonyms(a.unmaskPatterns(details))
+}
 
+// sanitizedFailureTexts returns the failure texts of a result, after the
+// metadata of its cluster with IncludeClusterInfo. When anonymize is set its
+// sensitive data, the matches of the AnonymizePatterns and its name and
+// namespace are masked.
+func (a *Analysis) sanitizedFailureTexts(result common.Result, anonymize bool) []string {
+	var texts []string
+	if info := a.clusterInfos[result.Cluster]; info != "" {
+		texts = append(texts, info)
+	}
+	for _, failure := range result.Error {
 		if anonymize {
-			for _, failure := range analysis.Error {
-				for _, s := range failure.Sensitive {
-					result = strings.ReplaceAll(result, s.Masked, s.Unmasked)
-				}
+			masked := make([]string, 0, len(failure.Sensitive))
+			for _, s := range failure.Sensitive {
+				failure.Text = util.ReplaceIfMatch(failure.Text, s.Unmasked, s.Masked)
+				masked = append(masked, s.Masked)
 			}
+			failure.Text = a.mask(failure.Text, masked, a.nameMaskers(result))
 		}
+		texts = append(texts, failure.Text)
+	}
+	return texts
+}
 
-		analysis.Details = result
-		if output != "json" {
-			_ = bar.Add(1)
+// explained reports whether result is explained, i.e. it isn't below the
+// ExplainMinSeverity.
+func (a *Analysis) explained(result common.Result) bool {
+	return result.Severity.Rank() >= a.ExplainMinSeverity.Rank()
+}
+
+func (a *Analysis) promptTemplate(kind string) string {
+	// If the resource `Kind` comes from an "integration plugin",
+	// maybe a customized prompt template will be involved.
+	if prompt, ok := a.PromptMap[kind]; ok {
+		return prompt
+	}
+	if a.asksRemediation(kind) {
+		return a.PromptMap["remediation"]
+	}
+	return a.PromptMap["default"]
+}
+
+// asksRemediation tells whether the explanations of kind ask for the commands
+// applying the solution. Only the built-in default prompt is replaced by the
+// remediation one, the custom prompts are sent as they are.
+func (a *Analysis) asksRemediation(kind string) bool {
+	if !a.ExplainRemediation {
+		return false
+	}
+	if _, custom := a.PromptMap[kind]; custom {
+		return false
+	}
+	_, ok := a.PromptMap["remediation"]
+	return ok && a.PromptMap["default"] == ai.PromptMap["default"]
+}
+
+// rawPrompt wraps the prompts sent to the custom REST provider, the built-in
+// one unless overridden by the PromptMap.
+func (a *Analysis) rawPrompt() string {
+	if prompt, ok := a.PromptMap[rawPromptType]; ok {
+		return prompt
+	}
+	return ai.PromptMap[rawPromptType]
+}
+
+// getAIResultForSanitizedFailures explains the failures with the primary AI
+// backend, falling back to the FallbackAIBackends in order when it fails. It
+// returns the explanation, the name of the provider which produced it, its
+// confidence, when requested and reported, and whether it was cached. With
+// CacheOnly, the results missing from the caches of all the backends get no
+// explanation nor provider.
+func (a *Analysis) getAIResultForSanitizedFailures(kind string, texts []string, promptTmpl string) (string, string, *float64, bool, error) {
+	inputKey := a.failureInput(kind, texts)
+	backends := append([]AIBackend{a.primaryAIBackend()}, a.FallbackAIBackends...)
+
+	var err error
+	for i, backend := range backends {
+		var response string
+		var confidence *float64
+		var cached bool
+		response, confidence, cached, err = a.getAIResultFromBackend(backend, kind, inputKey, promptTmpl)
+		if err == nil {
+			return response, backend.Client.GetName(), confidence, cached, nil
+		}
+		if !shouldFallback(err) {
+			break
+		}
+		if i+1 < len(backends) {
+			a.logger().Debug("AI provider failed, falling back", "provider", backend.Client.GetName(), "fallback", backends[i+1].Client.GetName(), "error", err)
 		}
-		a.Results[index] = analysis
 	}
-	return nil
+	if errors.Is(err, errCacheMiss) {
+		return "", "", nil, false, nil
+	}
+	return "", "", nil, false, err
+}
+
+// errCacheMiss is returned by getAIResultFromBackend for the results missing
+// from the cache with CacheOnly.
+var errCacheMiss = errors.New("no cached explanation")
+
+// truncatedMarker replaces the middle of the failure texts longer than the
+// MaxInputLength.
+const truncatedMarker = "\n[truncated]\n"
+
+// failureInput joins the failure texts of a result of kind into the input sent
+// to the AI provider, truncated to the MaxInputLength. It is also the cache
+// key, so truncated and full inputs are cached separately.
+func (a *Analysis) failureInput(kind string, texts []string) string {
+	input := strings.Join(texts, " ")
+	truncated := a.truncateInput(input)
+	if len(truncated) != len(input) {
+		a.logger().Debug("Failure texts truncated", "kind", kind, "characters", utf8.RuneCountInString(input), "truncated", utf8.RuneCountInString(truncated))
+	}
+	return truncated
+}
+
+// truncateInput keeps the head and the tail of an input longer than the
+// MaxInputLength, which usually hold the most signal, around truncatedMarker.
+func (a *Analysis) truncateInput(input string) string {
+	runes := []rune(input)
+	if a.MaxInputLength <= 0 || len(runes) <= a.MaxInputLength {
+		return input
+	}
+	kept := max(a.MaxInputLength-len(truncatedMarker), 0)
+	head := (kept + 1) / 2
+	return string(runes[:head]) + truncatedMarker + string(runes[len(runes)-(kept-head):])
+}
+
+func (a *Analysis) primaryAIBackend() AIBackend {
+	return AIBackend{Client: a.AIClient, Model: a.AIModel, BaseURL: a.AIBaseURL, Generation: a.AIGeneration}
+}
+
+func (a *Analysis) cacheKey(backend AIBackend, inputKey string) string {
+	if backend.Generation != "" {
+		inputKey = backend.Generation + " " + inputKey
+	}
+	return util.GetCacheKey(backend.Client.GetName(), backend.Model, backend.BaseURL, a.Language, inputKey)
+}
+
+// shouldFallback reports whether another AI backend should be tried after err:
+// the explanation isn't cached, or the provider was rate limited, timed out or
+// is unavailable. The other errors, e.g. an invalid prompt or configuration,
+// would only repeat with the other backends, and the cancellation of the
+// analysis itself stops the fallback chain.
+func shouldFallback(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return errors.Is(err, errCacheMiss) || isRateLimitError(err) || errors.Is(err, errRequestTimeout) || isUnavailableError(err)
 }
 
-func (a *Analysis) getAIResultForSanitizedFailures(texts []string, promptTmpl string) (string, error) {
-	inputKey := strings.Join(texts, " ")
+// getAIResultFromBackend explains inputKey with backend, or takes its
+// explanation from the cache. It returns the explanation, its confidence and
+// whether it was cached.
+func (a *Analysis) getAIResultFromBackend(backend AIBackend, kind string, inputKey string, promptTmpl string) (string, *float64, bool, error) {
 	// Check for cached data.
-	// TODO(bwplotka): This might depend on model too (or even other client configuration pieces), fix it in later PRs.
-	cacheKey := util.GetCacheKey(a.AIClient.GetName(), a.Language, inputKey)
+	cacheInput := inputKey
+	if a.asksRemediation(kind) && promptTmpl == a.PromptMap["remediation"] {
+		// The answers listing commands are cached apart.
+		cacheInput = "remediation " + inputKey
+	}
+	cacheKey := a.cacheKey(backend, cacheInput)
 
-	if !a.Cache.IsCacheDisabled() && a.Cache.Exists(cacheKey) {
-		response, err := a.Cache.Load(cacheKey)
-		if err != nil {
-			return "", err
-		}
+	if !a.Cache.IsCacheDisabled() {
+		if !a.Cache.Exists(cacheKey) {
+			a.recordCacheMiss()
+		} else {
+			response, err := a.Cache.Load(cacheKey)
+			if err != nil {
+				return "", nil, false, err
+			}
 
-		if response != "" {
-			output, err := base64.StdEncoding.DecodeString(response)
-			if err == nil {
-				return string(output), nil
+			if response == "" {
+				a.recordCacheMiss()
+			} else {
+				output, err := decodeCacheValue(response)
+				if err == nil {
+					a.recordCacheHit()
+					if a.onChunk != nil {
+						a.onChunk(output)
+					}
+					return output, a.loadConfidence(cacheKey), true, nil
+				}
+				a.recordCacheCorrupt()
+				color.Red("error decoding cached data; ignoring cache item: %v", err)
 			}
-			color.Red("error decoding cached data; ignoring cache item: %v", err)
 		}
 	}
 
+	if a.CacheOnly {
+		return "", nil, false, errCacheMiss
+	}
+
 	// Process template.
-	prompt := fmt.Sprintf(strings.TrimSpace(promptTmpl), a.Language, inputKey)
-	if a.AIClient.GetName() == ai.CustomRestClientName {
-		prompt = fmt.Sprintf(ai.PromptMap["raw"], a.Language, inputKey, prompt)
+	prompt := fmt.Sprintf(strings.TrimSpace(promptTmpl), a.language(), inputKey)
+	if backend.Client.GetName() == ai.CustomRestClientName {
+		prompt = fmt.Sprintf(a.rawPrompt(), a.language(), inputKey, ai.JoinSystemPrompt(ai.SplitSystemPrompt(prompt)))
 	}
-	response, err := a.AIClient.GetCompletion(a.Context, prompt)
+	response, usage, err := a.getCompletionWithRetry(backend.Client, prompt)
 	if err != nil {
-		return "", err
+		return "", nil, false, err
 	}
+	a.recordTokenUsage(kind, usage)
+
+	a.storeExplanation(cacheKey, kind, response)
+	a.storeConfidence(cacheKey, usage.Confidence)
+	return response, usage.Confidence, false, nil
+}
 
-	if err = a.Cache.Store(cacheKey, base64.StdEncoding.EncodeToString([]byte(response))); err != nil {
-		color.Red("error storing value to cache; value won't be cached: %v", err)
+// recordTokenUsage attributes the tokens used by a completion to the stats of
+// the analyzer which produced the explained result.
+func (a *Analysis) recordTokenUsage(kind string, usage ai.TokenUsage) {
+	if !a.WithStats {
+		return
+	}
+	if a.statsMutex != nil {
+		a.statsMutex.Lock()
+		defer a.statsMutex.Unlock()
+	}
+	for i := range a.Stats {
+		if a.Stats[i].Analyzer == kind {
+			a.Stats[i].PromptTokens += usage.PromptTokens
+			a.Stats[i].CompletionTokens += usage.CompletionTokens
+			a.Stats[i].TokensEstimated = a.Stats[i].TokensEstimated || usage.Estimated
+			return
+		}
 	}
-	return response, nil
+	// e.g. results of custom analyzers which have no timing stats.
+	a.Stats = append(a.Stats, common.AnalysisStats{
+		Analyzer:         kind,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TokensEstimated:  usage.Estimated,
+	})
 }
 
+// Close flushes the cache and closes the AI clients. Only the first call has an
+// effect.
 func (a *Analysis) Close() {
+	if a.closed {
+		return
+	}
+	a.closed = true
+	if a.Cache != nil {
+		if err := a.Cache.Close(); err != nil {
+			color.Red("error closing the cache; pending values may not be cached: %v", err)
+		}
+	}
+	for _, fallback := range a.FallbackAIBackends {
+		fallback.Client.Close()
+	}
 	if a.AIClient == nil {
 		return
 	}
@@ -15,27 +15,43 @@ package analysis
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	schemav1grpc "buf.build/gen/go/k8sgpt-ai/k8sgpt/grpc/go/schema/v1/schemav1grpc"
+	schemav1 "buf.build/gen/go/k8sgpt-ai/k8sgpt/protocolbuffers/go/schema/v1"
 	"github.com/agiledragon/gomonkey/v2"
+	openapi_v2 "github.com/google/gnostic/openapiv2"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/custom"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
 	"github.com/magiconair/properties/assert"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	v1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 // helper function: get type name of an analyzer
@@ -130,6 +146,64 @@ func TestAnalysis_RunAnalysisWithFilter(t *testing.T) {
 	assert.Equal(t, len(results), 2)
 }
 
+// Test: the results of the core analyzers are prioritized along with the ones found before
+func TestAnalysis_RunAnalysisPrioritizesResults(t *testing.T) {
+	newAnalysis := func(minSeverity common.Severity) *Analysis {
+		clientset := fake.NewSimpleClientset(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+			Status:     v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}}},
+		})
+		return &Analysis{
+			Context:        context.Background(),
+			Client:         &kubernetes.Client{Client: clientset},
+			Namespace:      "default",
+			Filters:        []string{"Pod"},
+			MaxConcurrency: 1,
+			MinSeverity:    minSeverity,
+			// e.g. found by a custom analyzer.
+			Results: []common.Result{{Kind: "Custom", Name: "default/custom", Severity: common.SeverityInfo}},
+		}
+	}
+
+	a := newAnalysis(common.SeverityInfo)
+	a.RunAnalysis()
+	require.Len(t, a.Results, 2)
+	require.Equal(t, "default/example", a.Results[0].Name)
+	require.Equal(t, common.SeverityWarning, a.Results[0].Severity)
+	require.Equal(t, "default/custom", a.Results[1].Name)
+
+	a = newAnalysis(common.SeverityCritical)
+	a.RunAnalysis()
+	require.Empty(t, a.Results)
+}
+
+// Test: the results of the core analyzers are collapsed with the same ones found before
+func TestAnalysis_RunAnalysisDeduplicatesResults(t *testing.T) {
+	newAnalysis := func(results []common.Result) *Analysis {
+		clientset := fake.NewSimpleClientset(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+			Status:     v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}}},
+		})
+		return &Analysis{
+			Context:        context.Background(),
+			Client:         &kubernetes.Client{Client: clientset},
+			Namespace:      "default",
+			Filters:        []string{"Pod"},
+			MaxConcurrency: 1,
+			Results:        results,
+		}
+	}
+	a := newAnalysis(nil)
+	a.RunAnalysis()
+	require.Len(t, a.Results, 1)
+
+	// e.g. a custom analyzer reported the same problem.
+	a = newAnalysis(a.Results)
+	a.RunAnalysis()
+	require.Len(t, a.Results, 1)
+	require.Equal(t, 2, a.Results[0].DetectedBy)
+}
+
 // Test:  Filter logic with Active Filter
 func TestAnalysis_RunAnalysisActiveFilter(t *testing.T) {
 
@@ -403,7 +477,7 @@ func TestGetAIResultForSanitizedFailures(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			output, err := tt.a.getAIResultForSanitizedFailures(tt.texts, tt.promptTmpl)
+			output, _, _, _, err := tt.a.getAIResultForSanitizedFailures("", tt.texts, tt.promptTmpl)
 			if tt.expectedErr == "" {
 				require.NoError(t, err)
 				require.Equal(t, tt.expectedOutput, output)
@@ -422,8 +496,8 @@ func TestVerbose_NewAnalysisWithoutExplain(t *testing.T) {
 	viper.Set("kubecontext", "dummy")
 	viper.Set("kubeconfig", "dummy")
 
-	// Patch kubernetes.NewClient to return a dummy client.
-	patches := gomonkey.ApplyFunc(kubernetes.NewClient, func(kubecontext, kubeconfig string) (*kubernetes.Client, error) {
+	// Patch kubernetes.NewClientWithOptions to return a dummy client.
+	patches := gomonkey.ApplyFunc(kubernetes.NewClientWithOptions, func(kubecontext, kubeconfig string, options kubernetes.ClientOptions) (*kubernetes.Client, error) {
 		return &kubernetes.Client{
 			Config: &rest.Config{Host: "fake-server"},
 		}, nil
@@ -441,12 +515,10 @@ func TestVerbose_NewAnalysisWithoutExplain(t *testing.T) {
 	})
 
 	expectedOutputs := []string{
-		"Debug: Checking kubernetes client initialization.",
-		"Debug: Kubernetes client initialized, server=fake-server.",
-		"Debug: Checking cache configuration.",
-		"Debug: Cache configuration loaded, type=file.",
-		"Debug: Cache disabled.",
-		"Debug: Analysis configuration loaded, filters=[Pod], language=english, namespace=default, labelSelector=none, explain=false, maxConcurrency=10, withDoc=false, withStats=false.",
+		`level=DEBUG msg="Kubernetes client initialized" server=fake-server`,
+		`msg="Cache configuration loaded" type=file`,
+		`msg="Cache disabled"`,
+		`msg="Analysis configuration loaded" filters=[Pod] language=english namespace=default labelSelector="" explain=false maxConcurrency=10 withDoc=false withStats=false`,
 	}
 	for _, expected := range expectedOutputs {
 		if !util.Contains(output, expected) {
@@ -475,8 +547,8 @@ func TestVerbose_NewAnalysisWithExplain(t *testing.T) {
 	}
 	viper.Set("ai", dummyAIConfig)
 
-	// Patch kubernetes.NewClient to return a dummy client.
-	patches := gomonkey.ApplyFunc(kubernetes.NewClient, func(kubecontext, kubeconfig string) (*kubernetes.Client, error) {
+	// Patch kubernetes.NewClientWithOptions to return a dummy client.
+	patches := gomonkey.ApplyFunc(kubernetes.NewClientWithOptions, func(kubecontext, kubeconfig string, options kubernetes.ClientOptions) (*kubernetes.Client, error) {
 		return &kubernetes.Client{
 			Config: &rest.Config{Host: "fake-server"},
 		}, nil
@@ -500,11 +572,9 @@ func TestVerbose_NewAnalysisWithExplain(t *testing.T) {
 	})
 
 	expectedOutputs := []string{
-		"Debug: Checking AI configuration.",
-		"Debug: Using default AI provider dummy.",
-		"Debug: AI configuration loaded, provider=dummy, baseUrl=http://dummy, model=dummy-model.",
-		"Debug: Checking AI client initialization.",
-		"Debug: AI client initialized.",
+		`msg="Using the default AI provider" provider=dummy`,
+		`msg="AI configuration loaded" provider=dummy baseURL=http://dummy model=dummy-model`,
+		`msg="AI client initialized" provider=dummy`,
 	}
 	for _, expected := range expectedOutputs {
 		if !util.Contains(output, expected) {
@@ -522,9 +592,9 @@ func TestVerbose_RunAnalysisWithFilter(t *testing.T) {
 	})
 
 	expectedOutputs := []string{
-		"Debug: Filter flags [Pod] specified, run selected core analyzers.",
-		"Debug: PodAnalyzer launched.",
-		"Debug: PodAnalyzer completed without errors.",
+		`msg="Filter flags specified, running the selected core analyzers" filters=[Pod]`,
+		`msg="Analyzer launched" analyzer=PodAnalyzer`,
+		`msg="Analyzer completed" analyzer=PodAnalyzer`,
 	}
 
 	for _, expected := range expectedOutputs {
@@ -543,9 +613,9 @@ func TestVerbose_RunAnalysisWithActiveFilter(t *testing.T) {
 	})
 
 	expectedOutputs := []string{
-		"Debug: Found active filters [Ingress], run selected core analyzers.",
-		"Debug: IngressAnalyzer launched.",
-		"Debug: IngressAnalyzer completed without errors.",
+		`msg="Found active filters, running the selected core analyzers" filters=[Ingress]`,
+		`msg="Analyzer launched" analyzer=IngressAnalyzer`,
+		`msg="Analyzer completed" analyzer=IngressAnalyzer`,
 	}
 
 	for _, expected := range expectedOutputs {
@@ -565,7 +635,7 @@ func TestVerbose_RunAnalysisWithoutFilter(t *testing.T) {
 	})
 
 	// Check for debug message indicating no filters.
-	expectedNoFilter := "Debug: No filters selected and no active filters found, run all core analyzers."
+	expectedNoFilter := `msg="No filters selected and no active filters found, running all core analyzers"`
 	if !util.Contains(output, expectedNoFilter) {
 		t.Errorf("Expected output to contain: '%s', but got output: '%s'", expectedNoFilter, output)
 	}
@@ -574,8 +644,8 @@ func TestVerbose_RunAnalysisWithoutFilter(t *testing.T) {
 	coreAnalyzerMap, _ := analyzer.GetAnalyzerMap()
 	for _, analyzerInstance := range coreAnalyzerMap {
 		analyzerType := getTypeName(analyzerInstance)
-		expectedLaunched := fmt.Sprintf("Debug: %s launched.", analyzerType)
-		expectedCompleted := fmt.Sprintf("Debug: %s completed without errors.", analyzerType)
+		expectedLaunched := fmt.Sprintf(`msg="Analyzer launched" analyzer=%s`, analyzerType)
+		expectedCompleted := fmt.Sprintf(`msg="Analyzer completed" analyzer=%s`, analyzerType)
 		if !util.Contains(output, expectedLaunched) {
 			t.Errorf("Expected output to contain: '%s', but got output: '%s'", expectedLaunched, output)
 		}
@@ -596,8 +666,8 @@ func TestVerbose_RunCustomAnalysisWithoutCustomAnalyzer(t *testing.T) {
 	output := util.CaptureOutput(func() {
 		analysisObj.RunCustomAnalysis()
 	})
-	expected := "Debug: No custom analyzers found."
-	if !util.Contains(output, "Debug: No custom analyzers found.") {
+	expected := `msg="No custom analyzers found"`
+	if !util.Contains(output, expected) {
 		t.Errorf("Expected output to contain: '%s', but got output: '%s'", expected, output)
 	}
 }
@@ -608,7 +678,7 @@ func TestVerbose_RunCustomAnalysisWithCustomAnalyzer(t *testing.T) {
 	// Set custom_analyzers with one custom analyzer using "fake" connection.
 	viper.Set("custom_analyzers", []map[string]interface{}{
 		{
-			"name":       "TestCustomAnalyzer",
+			"name":       "test-custom-analyzer",
 			"connection": map[string]interface{}{"url": "127.0.0.1", "port": "2333"},
 		},
 	})
@@ -622,9 +692,9 @@ func TestVerbose_RunCustomAnalysisWithCustomAnalyzer(t *testing.T) {
 	assert.Equal(t, 1, len(analysisObj.Errors)) // connection error
 
 	expectedOutputs := []string{
-		"Debug: Found custom analyzers [TestCustomAnalyzer].",
-		"Debug: TestCustomAnalyzer launched.",
-		"Debug: TestCustomAnalyzer completed with errors.",
+		`msg="Found custom analyzers" analyzers=[test-custom-analyzer]`,
+		`msg="Analyzer launched" analyzer=test-custom-analyzer`,
+		`msg="Analyzer completed with errors" analyzer=test-custom-analyzer`,
 	}
 
 	for _, expected := range expectedOutputs {
@@ -634,6 +704,104 @@ func TestVerbose_RunCustomAnalysisWithCustomAnalyzer(t *testing.T) {
 	}
 }
 
+// Test: custom analysis completes when MaxConcurrency isn't set
+func TestAnalysis_RunCustomAnalysisDefaultConcurrency(t *testing.T) {
+	viper.Set("verbose", false)
+	viper.Set("custom_analyzers", []map[string]interface{}{
+		{
+			"name":       "my-analyzer",
+			"connection": map[string]interface{}{"url": "127.0.0.1", "port": "2333"},
+		},
+	})
+	defer viper.Set("custom_analyzers", nil)
+
+	a := &Analysis{Context: context.Background()}
+	done := make(chan struct{})
+	go func() {
+		a.RunCustomAnalysis()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("RunCustomAnalysis didn't complete")
+	}
+	require.Len(t, a.Errors, 1) // connection error
+}
+
+// Test: the custom analyzers with a name which isn't a lowercase RFC 1123 subdomain are reported and skipped
+func TestAnalysis_ValidCustomAnalyzers(t *testing.T) {
+	names := []string{"my-analyzer", "example.com", "a", "0day", "MyAnalyzer", "my_analyzer", "-analyzer", "analyzer.", ""}
+	var customAnalyzers []custom.CustomAnalyzer
+	for _, name := range names {
+		customAnalyzers = append(customAnalyzers, custom.CustomAnalyzer{Name: name})
+	}
+
+	a := &Analysis{}
+	var valid []string
+	for _, cAnalyzer := range a.validCustomAnalyzers(customAnalyzers) {
+		valid = append(valid, cAnalyzer.Name)
+	}
+	require.Equal(t, []string{"my-analyzer", "example.com", "a", "0day"}, valid)
+	require.Len(t, a.Errors, 5)
+	require.Equal(t, "[MyAnalyzer] invalid custom analyzer name, it must be a lowercase RFC 1123 subdomain (e.g. 'example.com'), analyzer skipped", a.Errors[0].Error())
+}
+
+// Test: an invalid custom analyzer name is reported once by CustomAnalyzersAreAvailable and RunCustomAnalysis
+func TestAnalysis_RunCustomAnalysisInvalidName(t *testing.T) {
+	viper.Set("verbose", false)
+	viper.Set("custom_analyzers", []map[string]interface{}{
+		{
+			"name":       "Invalid_Analyzer",
+			"connection": map[string]interface{}{"url": "127.0.0.1", "port": "2333"},
+		},
+	})
+	defer viper.Set("custom_analyzers", nil)
+
+	a := &Analysis{Context: context.Background()}
+	require.False(t, a.CustomAnalyzersAreAvailable())
+	a.RunCustomAnalysis()
+	require.Equal(t, []string{"[Invalid_Analyzer] invalid custom analyzer name, it must be a lowercase RFC 1123 subdomain (e.g. 'example.com'), analyzer skipped"}, a.Errors.Strings())
+	require.Empty(t, a.Results)
+}
+
+// hungCustomAnalyzer is a custom analyzer server which never answers.
+type hungCustomAnalyzer struct {
+	schemav1grpc.UnimplementedCustomAnalyzerServiceServer
+}
+
+func (hungCustomAnalyzer) Run(ctx context.Context, _ *schemav1.RunRequest) (*schemav1.RunResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// Test: a custom analyzer running past the analyzer timeout is reported and releases its slot
+func TestAnalysis_RunCustomAnalysisTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	schemav1grpc.RegisterCustomAnalyzerServiceServer(server, hungCustomAnalyzer{})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	viper.Set("verbose", false)
+	viper.Set("custom_analyzers", []map[string]interface{}{
+		{"name": "hung", "connection": map[string]interface{}{"url": "127.0.0.1", "port": port}},
+		{"name": "also-hung", "connection": map[string]interface{}{"url": "127.0.0.1", "port": port}},
+	})
+	defer viper.Set("custom_analyzers", nil)
+
+	a := &Analysis{
+		Context:         context.Background(),
+		MaxConcurrency:  1,
+		AnalyzerTimeout: 50 * time.Millisecond,
+	}
+	a.RunCustomAnalysis()
+	require.ElementsMatch(t, []string{"[hung] timed out after 50ms", "[also-hung] timed out after 50ms"}, a.Errors.Strings())
+}
+
 // Test: Verbose output in GetAIResults
 func TestVerbose_GetAIResults(t *testing.T) {
 	viper.Set("verbose", true)
@@ -658,8 +826,1680 @@ func TestVerbose_GetAIResults(t *testing.T) {
 		_ = analysisObj.GetAIResults("json", false)
 	})
 
-	expected := "Debug: Generating AI analysis."
+	expected := `msg="Generating the AI analysis"`
 	if !util.Contains(output, expected) {
 		t.Errorf("Expected output to contain: '%s', but got output: '%s'", expected, output)
 	}
 }
+
+// Test: analyzers launched after the execution budget is exceeded are skipped and reported
+func TestAnalysis_RunAnalysisExecutionBudget(t *testing.T) {
+	viper.Set("verbose", false)
+	viper.SetDefault("active_filters", []string{})
+	clientset := fake.NewSimpleClientset()
+	analysis := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Pod", "Service", "Ingress"},
+		MaxConcurrency: 1,
+		Client: &kubernetes.Client{
+			Client: clientset,
+		},
+		ExecutionBudget: time.Nanosecond,
+	}
+	analysis.RunAnalysis()
+
+	// The budget is exceeded before any analyzer gets launched.
+	require.Equal(t, []string{"Ingress", "Pod", "Service"}, analysis.SkippedAnalyzers)
+	require.Empty(t, analysis.Stats)
+
+	output, err := analysis.PrintOutput("json")
+	require.NoError(t, err)
+	got := JsonOutput{}
+	require.NoError(t, json.Unmarshal(output, &got))
+	require.Equal(t, analysis.SkippedAnalyzers, got.SkippedAnalyzers)
+}
+
+// memoryCache is an in-memory cache.ICache used to observe cache interactions.
+type memoryCache struct {
+	data     map[string]string
+	disabled bool
+	closes   int
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{data: map[string]string{}}
+}
+
+func (m *memoryCache) Configure(cache.CacheProvider) error {
+	return nil
+}
+
+func (m *memoryCache) Store(key string, data string) error {
+	m.data[key] = data
+	return nil
+}
+
+func (m *memoryCache) Load(key string) (string, error) {
+	return m.data[key], nil
+}
+
+func (m *memoryCache) List() ([]cache.CacheObjectDetails, error) {
+	return nil, nil
+}
+
+func (m *memoryCache) Remove(key string) error {
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memoryCache) Exists(key string) bool {
+	_, ok := m.data[key]
+	return ok
+}
+
+func (m *memoryCache) IsCacheDisabled() bool {
+	return m.disabled
+}
+
+func (m *memoryCache) GetName() string {
+	return "memory"
+}
+
+func (m *memoryCache) DisableCache() {
+	m.disabled = true
+}
+
+func (m *memoryCache) SetTTL(time.Duration) {}
+
+func (m *memoryCache) Close() error {
+	m.closes++
+	return nil
+}
+
+// Test: identical inputs explained by different models don't share cache entries
+func TestGetAIResultForSanitizedFailures_CacheKeyPerModel(t *testing.T) {
+	sharedCache := newMemoryCache()
+	texts := []string{"identical failure"}
+
+	for _, model := range []string{"gpt-3.5-turbo", "gpt-4o"} {
+		a := Analysis{
+			AIClient: &ai.NoOpAIClient{},
+			Cache:    sharedCache,
+			Language: "english",
+			AIModel:  model,
+		}
+		_, _, _, _, err := a.getAIResultForSanitizedFailures("Pod", texts, "%s %s")
+		require.NoError(t, err)
+	}
+	require.Len(t, sharedCache.data, 2)
+}
+
+// Test: token usage is attributed to the analyzer stats and totalled in the JSON output
+func TestGetAIResults_TokenUsageStats(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	a := Analysis{
+		AIClient:  &ai.NoOpAIClient{},
+		Cache:     disabledCache,
+		WithStats: true,
+		PromptMap: map[string]string{"default": "%s %s"},
+		Stats:     []common.AnalysisStats{{Analyzer: "Pod", DurationTime: time.Second}},
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "pod failure"}}},
+			{Kind: "CustomKind", Name: "custom", Error: []common.Failure{{Text: "custom failure"}}},
+		},
+	}
+	require.NoError(t, a.GetAIResults("json", false))
+
+	require.Len(t, a.Stats, 2)
+	require.Equal(t, "Pod", a.Stats[0].Analyzer)
+	require.Equal(t, time.Second, a.Stats[0].DurationTime)
+	require.Greater(t, a.Stats[0].PromptTokens, 0)
+	require.Greater(t, a.Stats[0].CompletionTokens, 0)
+	// NoOp AI client doesn't report usage.
+	require.True(t, a.Stats[0].TokensEstimated)
+	require.Equal(t, "CustomKind", a.Stats[1].Analyzer)
+
+	output, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	got := JsonOutput{}
+	require.NoError(t, json.Unmarshal(output, &got))
+	require.NotNil(t, got.Stats)
+	require.Equal(t, a.Stats[0].PromptTokens+a.Stats[1].PromptTokens, got.Stats.PromptTokens)
+	require.Equal(t, got.Stats.PromptTokens+got.Stats.CompletionTokens, got.Stats.TotalTokens)
+
+	require.Contains(t, string(a.PrintStats()), "(estimated)")
+}
+
+// Test: the fallback provider explains the results when the primary one fails
+func TestGetAIResults_FallbackProvider(t *testing.T) {
+	primary := &rateLimitedAIClient{failures: 1, err: fmt.Errorf("connection refused")}
+	a := Analysis{
+		Context:            context.Background(),
+		AIClient:           primary,
+		FallbackAIBackends: []AIBackend{{Client: &ai.NoOpAIClient{}}},
+		Cache:              newMemoryCache(),
+		Language:           "english",
+		PromptMap:          map[string]string{"default": "%s %s"},
+		Results:            []common.Result{{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "pod failure"}}}},
+	}
+	require.NoError(t, a.GetAIResults("json", false))
+	require.Equal(t, 1, primary.calls)
+	require.Equal(t, "I am a noop response to the prompt english pod failure", a.Results[0].Details)
+	require.Equal(t, "noopai", a.Results[0].Provider)
+}
+
+// Test: a cancelled analysis doesn't fall back to the next provider
+func TestGetAIResultForSanitizedFailures_NoFallbackOnCancel(t *testing.T) {
+	primary := &rateLimitedAIClient{failures: 1, err: context.Canceled}
+	fallback := &rateLimitedAIClient{}
+	a := Analysis{
+		AIClient:           primary,
+		FallbackAIBackends: []AIBackend{{Client: fallback}},
+		Cache:              newMemoryCache(),
+	}
+	_, _, _, _, err := a.getAIResultForSanitizedFailures("Pod", []string{"pod failure"}, "%s %s")
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 0, fallback.calls)
+}
+
+// Test: the errors which would repeat with the other providers don't fall back
+func TestGetAIResultForSanitizedFailures_NoFallbackOnInvalidRequest(t *testing.T) {
+	primary := &rateLimitedAIClient{failures: 1, err: errors.New("error, status code: 400, message: invalid prompt")}
+	fallback := &rateLimitedAIClient{}
+	a := Analysis{
+		AIClient:           primary,
+		FallbackAIBackends: []AIBackend{{Client: fallback}},
+		Cache:              newMemoryCache(),
+	}
+	_, _, _, _, err := a.getAIResultForSanitizedFailures("Pod", []string{"pod failure"}, "%s %s")
+	require.ErrorContains(t, err, "invalid prompt")
+	require.Equal(t, 0, fallback.calls)
+}
+
+// Test: the rate limited, timed out and unavailable providers fall back
+func TestShouldFallback(t *testing.T) {
+	require.True(t, shouldFallback(errors.New("error, status code: 429, message: slow down")))
+	require.True(t, shouldFallback(errors.New("error, status code: 503, message: overloaded")))
+	require.True(t, shouldFallback(fmt.Errorf("%w after 1s (ai.request_timeout)", errRequestTimeout)))
+	require.True(t, shouldFallback(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	require.True(t, shouldFallback(errCacheMiss))
+	require.False(t, shouldFallback(errors.New("error, status code: 400, message: invalid prompt")))
+	require.False(t, shouldFallback(errors.New("unsupported model")))
+	require.False(t, shouldFallback(context.Canceled))
+}
+
+// Test: cache hits, misses and corrupt entries are counted and reported in the JSON stats
+func TestGetAIResultForSanitizedFailures_CacheStats(t *testing.T) {
+	a := Analysis{
+		AIClient:  &ai.NoOpAIClient{},
+		Cache:     newMemoryCache(),
+		Language:  "english",
+		WithStats: true,
+	}
+	for i := 0; i < 2; i++ {
+		_, _, _, _, err := a.getAIResultForSanitizedFailures("Pod", []string{"pod failure"}, "%s %s")
+		require.NoError(t, err)
+	}
+	require.NoError(t, a.Cache.Store(a.cacheKey(a.primaryAIBackend(), "corrupt failure"), "not base64!"))
+	_, _, _, _, err := a.getAIResultForSanitizedFailures("Pod", []string{"corrupt failure"}, "%s %s")
+	require.NoError(t, err)
+
+	require.Equal(t, CacheStats{Hits: 1, Misses: 1, Corrupt: 1}, a.CacheStats())
+	require.Equal(t, a.CacheStats(), a.getJsonStats().Cache)
+	require.Contains(t, string(a.PrintStats()), "Cache: 1 hits, 1 misses, 1 corrupt")
+}
+
+// Test: a cancelled analysis doesn't launch analyzers and records why
+func TestAnalysis_RunAnalysisCancelled(t *testing.T) {
+	viper.Set("verbose", false)
+	viper.SetDefault("active_filters", []string{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	analysis := Analysis{
+		Context:        ctx,
+		Filters:        []string{"Pod", "Service"},
+		MaxConcurrency: 1,
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(),
+		},
+	}
+	analysis.RunAnalysis()
+
+	require.Empty(t, analysis.Results)
+	require.Equal(t, []string{"[Analysis] run cut short (context canceled), analyzers not completed: Pod, Service"}, analysis.Errors.Strings())
+}
+
+// Test: runUntilDone returns as soon as the context is done
+func TestRunUntilDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	release := make(chan struct{})
+	defer close(release)
+
+	_, err := runUntilDone(ctx, func() ([]common.Result, error) {
+		<-release
+		return nil, nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	results, err := runUntilDone(context.Background(), func() ([]common.Result, error) {
+		return []common.Result{{Kind: "Pod"}}, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+}
+
+// docFailingClientset fails to fetch the OpenAPI schema, like a client without
+// the RBAC to.
+type docFailingClientset struct {
+	*fake.Clientset
+}
+
+func (c docFailingClientset) Discovery() discovery.DiscoveryInterface {
+	return docFailingDiscovery{c.Clientset.Discovery()}
+}
+
+type docFailingDiscovery struct {
+	discovery.DiscoveryInterface
+}
+
+func (docFailingDiscovery) OpenAPISchema() (*openapi_v2.Document, error) {
+	return nil, errors.New("the server does not allow access to the requested resource")
+}
+
+// Test: the docs failing to be fetched is an error, unless WithDocBestEffort
+func TestAnalysis_RunAnalysisWithDocBestEffort(t *testing.T) {
+	viper.Reset()
+	newAnalysis := func() *Analysis {
+		clientset := docFailingClientset{fake.NewSimpleClientset(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+			Status:     v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}}},
+		})}
+		return &Analysis{
+			Context:        context.Background(),
+			Filters:        []string{"Pod"},
+			Namespace:      "default",
+			MaxConcurrency: 1,
+			Client:         &kubernetes.Client{Client: clientset},
+			WithDoc:        true,
+		}
+	}
+
+	a := newAnalysis()
+	a.RunAnalysis()
+	require.Len(t, a.Results, 1)
+	require.Equal(t, []string{"[KubernetesDoc] the server does not allow access to the requested resource"}, a.Errors.Strings())
+	require.Equal(t, PhaseDocumentation, a.Errors[0].Phase)
+
+	viper.Set("verbose", true)
+	defer viper.Reset()
+	a = newAnalysis()
+	a.WithDocBestEffort = true
+	output := util.CaptureOutput(a.RunAnalysis)
+	require.Len(t, a.Results, 1)
+	require.Empty(t, a.Errors)
+	require.Contains(t, output, `msg="Kubernetes docs unavailable, analyzing without them" error="the server does not allow access to the requested resource"`)
+}
+
+type widgetAnalyzer struct{}
+
+func (widgetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+	return []common.Result{{Kind: "Widget", Name: a.Namespace + "/widget", Error: []common.Failure{{Text: "widget broken"}}}}, nil
+}
+
+// Test: the analyzers registered from outside of k8sgpt are selected by the filters
+func TestAnalysis_RunAnalysisRegisteredAnalyzer(t *testing.T) {
+	viper.Reset()
+	require.NoError(t, analyzer.Register("AnalysisTestWidget", widgetAnalyzer{}))
+	t.Cleanup(func() { analyzer.Unregister("AnalysisTestWidget") })
+	a := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"AnalysisTestWidget"},
+		Namespace:      "default",
+		MaxConcurrency: 1,
+		Client:         &kubernetes.Client{Client: fake.NewSimpleClientset()},
+	}
+	a.RunAnalysis()
+	require.Empty(t, a.Errors)
+	require.Len(t, a.Results, 1)
+	require.Equal(t, "default/widget", a.Results[0].Name)
+}
+
+// Test: a run whose filters all don't exist has a status of its own
+func TestAnalysis_RunAnalysisAllFiltersUnknown(t *testing.T) {
+	viper.Reset()
+	a := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Bogus", "Pods"},
+		MaxConcurrency: 1,
+		Client:         &kubernetes.Client{Client: fake.NewSimpleClientset()},
+	}
+	a.RunAnalysis()
+
+	require.Equal(t, []string{
+		"\"Bogus\" filter does not exist. Please run k8sgpt filters list.",
+		"\"Pods\" filter does not exist. Please run k8sgpt filters list.",
+		"[Filters] none of the filters exist, no analyzer ran",
+	}, a.Errors.Strings())
+	require.ErrorIs(t, a.Errors[2], ErrNoAnalyzers)
+	require.Equal(t, StateNoAnalyzers, a.Status())
+	output, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	require.Contains(t, string(output), `"status": "NoAnalyzers"`)
+	output, err = a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "No analyzer ran, none of the filters exist")
+	require.NotContains(t, string(output), "No problems detected")
+}
+
+// Test: a run with some filters existing is a partial failure, the others are reported
+func TestAnalysis_RunAnalysisSomeFiltersUnknown(t *testing.T) {
+	viper.Reset()
+	a := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Bogus", "Pod"},
+		MaxConcurrency: 1,
+		Client:         &kubernetes.Client{Client: fake.NewSimpleClientset()},
+	}
+	a.RunAnalysis()
+
+	require.Equal(t, []string{"\"Bogus\" filter does not exist. Please run k8sgpt filters list."}, a.Errors.Strings())
+	require.Equal(t, StatePartialFailure, a.Status())
+
+	// The next run starts afresh.
+	a.Filters = []string{"Bogus"}
+	a.Errors = nil
+	a.RunAnalysis()
+	require.Equal(t, StateNoAnalyzers, a.Status())
+	a.Filters = []string{"Pod"}
+	a.Errors = nil
+	a.RunAnalysis()
+	require.Equal(t, StateOK, a.Status())
+}
+
+// Test: excluded analyzers aren't run and unknown exclusions are reported
+func TestAnalysis_RunAnalysisExcludeFilters(t *testing.T) {
+	viper.Set("verbose", false)
+	viper.SetDefault("active_filters", []string{})
+	analysis := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Pod", "Service"},
+		ExcludeFilters: []string{"Service", "Bogus"},
+		MaxConcurrency: 1,
+		WithStats:      true,
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(),
+		},
+	}
+	analysis.RunAnalysis()
+
+	require.Len(t, analysis.Stats, 1)
+	require.Equal(t, "Pod", analysis.Stats[0].Analyzer)
+	require.Equal(t, []string{"\"Bogus\" filter does not exist. Please run k8sgpt filters list."}, analysis.Errors.Strings())
+
+	// Without filters, all core analyzers but the excluded ones run.
+	coreAnalyzerMap, _ := analyzer.GetAnalyzerMap()
+	analysis.Filters = nil
+	analysis.ExcludeFilters = []string{"Service"}
+	analysis.Stats = nil
+	analysis.Errors = nil
+	analysis.RunAnalysis()
+	require.Len(t, analysis.Stats, len(coreAnalyzerMap)-1)
+	for _, stat := range analysis.Stats {
+		require.NotEqual(t, "Service", stat.Analyzer)
+	}
+}
+
+// Test: the filters select custom analyzers by name, and only them when no core analyzer is named
+func TestAnalysis_FiltersSelectCustomAnalyzers(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("custom_analyzers", []map[string]interface{}{
+		{"name": "first-custom", "connection": map[string]interface{}{"url": "127.0.0.1", "port": "2333"}},
+		{"name": "second-custom", "connection": map[string]interface{}{"url": "127.0.0.1", "port": "2334"}},
+	})
+	customAnalyzers := []custom.CustomAnalyzer{{Name: "first-custom"}, {Name: "second-custom"}}
+
+	a := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"second-custom"},
+		MaxConcurrency: 1,
+		WithStats:      true,
+		Client:         &kubernetes.Client{Client: fake.NewSimpleClientset()},
+	}
+	require.Equal(t, []custom.CustomAnalyzer{{Name: "second-custom"}}, a.selectCustomAnalyzers(customAnalyzers))
+	a.RunAnalysis()
+	require.Empty(t, a.Stats)
+	require.Empty(t, a.Errors)
+
+	// Core filters skip the custom analyzers they don't name.
+	a.Filters = []string{"Pod"}
+	require.Empty(t, a.selectCustomAnalyzers(customAnalyzers))
+
+	// So do the active filters when no filter is given.
+	a.Filters = nil
+	viper.Set("active_filters", []string{"Pod", "first-custom"})
+	require.Equal(t, []custom.CustomAnalyzer{{Name: "first-custom"}}, a.selectCustomAnalyzers(customAnalyzers))
+
+	// Without any filter, all but the excluded ones run.
+	viper.Set("active_filters", []string{})
+	a.ExcludeFilters = []string{"first-custom"}
+	require.Equal(t, []custom.CustomAnalyzer{{Name: "second-custom"}}, a.selectCustomAnalyzers(customAnalyzers))
+	a.RunAnalysis()
+	require.Empty(t, a.Errors)
+}
+
+// slowAnalyzer blocks until its context is done.
+type slowAnalyzer struct{}
+
+func (slowAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+	<-a.Context.Done()
+	return nil, a.Context.Err()
+}
+
+// Test: an analyzer running past the analyzer timeout is reported and releases its slot
+func TestExecuteAnalyzer_Timeout(t *testing.T) {
+	viper.Set("verbose", false)
+	a := Analysis{
+		Context:         context.Background(),
+		AnalyzerTimeout: 10 * time.Millisecond,
+		WithStats:       true,
+	}
+	semaphore := make(chan struct{}, 1)
+	semaphore <- struct{}{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	c := a.startCollector()
+	a.executeAnalyzer(slowAnalyzer{}, "Slow", common.Analyzer{}, semaphore, &wg, c)
+	c.wait()
+	wg.Wait()
+
+	require.Equal(t, []string{"[Slow] timed out after 10ms"}, a.Errors.Strings())
+	require.Len(t, a.Stats, 1)
+	require.Equal(t, "Slow", a.Stats[0].Analyzer)
+	require.Empty(t, semaphore)
+}
+
+// Test: the stats count the objects scanned and the problems found by each analyzer
+func TestExecuteAnalyzer_ObjectsScanned(t *testing.T) {
+	viper.Set("verbose", false)
+	clientset := fake.NewSimpleClientset(
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "default"},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"},
+			Status: v1.PodStatus{
+				Phase:      v1.PodPending,
+				Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}},
+			},
+		},
+	)
+	a := Analysis{
+		Context:         context.Background(),
+		AnalyzerTimeout: 100 * time.Millisecond,
+		WithStats:       true,
+	}
+	config := common.Analyzer{
+		Client:    &kubernetes.Client{Client: clientset},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+	semaphore := make(chan struct{}, 1)
+	var wg sync.WaitGroup
+	semaphore <- struct{}{}
+	wg.Add(1)
+	c := a.startCollector()
+	a.executeAnalyzer(analyzer.PodAnalyzer{}, "Pod", config, semaphore, &wg, c)
+	c.wait()
+	wg.Wait()
+	semaphore <- struct{}{}
+	wg.Add(1)
+	c = a.startCollector()
+	a.executeAnalyzer(slowAnalyzer{}, "Slow", config, semaphore, &wg, c)
+	c.wait()
+	wg.Wait()
+
+	require.Len(t, a.Stats, 2)
+	require.Equal(t, "Pod", a.Stats[0].Analyzer)
+	require.NotNil(t, a.Stats[0].ObjectsScanned)
+	require.Equal(t, 2, *a.Stats[0].ObjectsScanned)
+	require.Equal(t, 1, a.Stats[0].Problems)
+	require.Nil(t, a.Stats[1].ObjectsScanned)
+
+	stats := string(a.PrintStats())
+	require.Contains(t, stats, "OBJECTS SCANNED")
+	require.Contains(t, stats, "n/a")
+}
+
+// Test: the analyzers skip the objects which didn't change within the Since window
+func TestAnalysis_RunAnalysisSince(t *testing.T) {
+	old := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	clientset := fake.NewSimpleClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: "default", CreationTimestamp: old},
+		Status: v1.PodStatus{
+			Phase:      v1.PodPending,
+			Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available", LastTransitionTime: old}},
+		},
+	})
+	a := Analysis{
+		Context:        context.Background(),
+		Namespace:      "default",
+		MaxConcurrency: 1,
+		Client:         &kubernetes.Client{Client: clientset},
+		Filters:        []string{"Pod"},
+	}
+	a.RunAnalysis()
+	require.Len(t, a.Results, 1)
+
+	a.Results = nil
+	a.Since = time.Hour
+	a.RunAnalysis()
+	require.Empty(t, a.Results)
+}
+
+// Test: results default to Warning, are filtered by the minimum severity and sorted by severity
+func TestAnalysis_PrioritizeResults(t *testing.T) {
+	a := Analysis{
+		MinSeverity: common.SeverityWarning,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/info", Severity: common.SeverityInfo},
+			{Kind: "Pod", Name: "default/first"},
+			{Kind: "Node", Name: "node", Severity: common.SeverityCritical},
+			{Kind: "Service", Name: "default/second"},
+		},
+	}
+	a.prioritizeResults()
+
+	require.Equal(t, []common.Result{
+		{Kind: "Node", Name: "node", Severity: common.SeverityCritical},
+		{Kind: "Pod", Name: "default/first", Severity: common.SeverityWarning},
+		{Kind: "Service", Name: "default/second", Severity: common.SeverityWarning},
+	}, a.Results)
+}
+
+// Test: only the results of ShowKinds are kept, ignoring case, and explained
+func TestAnalysis_ShowKinds(t *testing.T) {
+	viper.Reset()
+	a := Analysis{
+		AIClient:  &ai.NoOpAIClient{},
+		Cache:     newMemoryCache(),
+		Language:  "english",
+		PromptMap: map[string]string{"default": "%s %s"},
+		ShowKinds: []string{"pod"},
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "pending"}}},
+			{Kind: "Service", Name: "default/web", Error: []common.Failure{{Text: "no endpoints"}}},
+			{Kind: "Security/Pod", Name: "default/api", Error: []common.Failure{{Text: "privileged"}}},
+		},
+	}
+	a.finishResults()
+	require.NoError(t, a.GetAIResults("json", false))
+
+	require.Len(t, a.Results, 2)
+	require.Equal(t, "Pod", a.Results[0].Kind)
+	require.Equal(t, "Security/Pod", a.Results[1].Kind)
+	require.Equal(t, CacheStats{Misses: 2}, a.CacheStats())
+
+	a.ShowKinds = []string{"Node"}
+	a.finishResults()
+	require.Empty(t, a.Results)
+	output, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "0 matching results")
+}
+
+// Test: the results of a severity are sorted by Kind, namespace and name
+func TestAnalysis_PrioritizeResultsOrder(t *testing.T) {
+	a := Analysis{
+		Results: []common.Result{
+			{Kind: "Service", Name: "default/web"},
+			{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "pending"}}},
+			{Kind: "Pod", Name: "default-a/web"},
+			{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "crash loop"}}},
+			{Kind: "Node", Name: "node"},
+			{Kind: "Pod", Name: "default/api", Severity: common.SeverityInfo},
+		},
+	}
+	a.prioritizeResults()
+
+	var order []string
+	for _, result := range a.Results {
+		order = append(order, result.Kind+" "+result.Name)
+	}
+	require.Equal(t, []string{"Node node", "Pod default/web", "Pod default/web", "Pod default-a/web", "Service default/web", "Pod default/api"}, order)
+	require.Equal(t, "crash loop", a.Results[1].Error[0].Text)
+}
+
+// Test: repeated runs on the same objects list the results in the same order
+func TestAnalysis_RunAnalysisStableOrder(t *testing.T) {
+	viper.Reset()
+	var objects []runtime.Object
+	for _, namespace := range []string{"default", "kube-system", "monitoring"} {
+		for _, name := range []string{"web", "api", "worker"} {
+			objects = append(objects,
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+					Status:     v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}}},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+					Spec:       v1.ServiceSpec{Selector: map[string]string{"app": name}},
+				},
+				&v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+			)
+		}
+	}
+	run := func() []string {
+		a := Analysis{
+			Context:        context.Background(),
+			MaxConcurrency: 10,
+			Client:         &kubernetes.Client{Client: fake.NewSimpleClientset(objects...)},
+		}
+		a.RunAnalysis()
+		var order []string
+		for _, result := range a.Results {
+			order = append(order, result.Kind+" "+result.Name)
+		}
+		return order
+	}
+
+	first := run()
+	require.Len(t, first, 18)
+	require.Equal(t, "Pod default/api", first[0])
+	for i := 0; i < 10; i++ {
+		require.Equal(t, first, run())
+	}
+}
+
+// Test: results with the same Kind, Name and failures are collapsed before explaining
+func TestAnalysis_DeduplicateResults(t *testing.T) {
+	a := Analysis{
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "Back-off pulling image"}}},
+			{Kind: "Pod", Name: "default/other", Error: []common.Failure{{Text: "Back-off pulling image"}}},
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "back-off  pulling image "}}, Severity: common.SeverityCritical},
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "another failure"}}},
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "Back-off pulling image"}}},
+		},
+	}
+	a.deduplicateResults()
+
+	require.Len(t, a.Results, 3)
+	require.Equal(t, "default/pod", a.Results[0].Name)
+	require.Equal(t, 3, a.Results[0].DetectedBy)
+	require.Equal(t, common.SeverityCritical, a.Results[0].Severity)
+	require.Zero(t, a.Results[1].DetectedBy)
+	require.Equal(t, "another failure", a.Results[2].Error[0].Text)
+
+	output, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "(detected by 3 analyzers)")
+}
+
+// Test: the collapsed results keep the labels of all the duplicates
+func TestAnalysis_DeduplicateResultsLabels(t *testing.T) {
+	a := Analysis{
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "crash loop"}}},
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "crash loop"}}, Labels: map[string]string{"team": "payments"}},
+		},
+	}
+	a.deduplicateResults()
+
+	require.Len(t, a.Results, 1)
+	require.Equal(t, map[string]string{"team": "payments"}, a.Results[0].Labels)
+}
+
+// Test: the labels of the analyzed objects are propagated to the JSON output
+func TestAnalysis_PropagateLabels(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default", Labels: map[string]string{"team": "payments"}},
+		Status:     v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}}},
+	})
+	a := Analysis{
+		Context:         context.Background(),
+		Client:          &kubernetes.Client{Client: clientset},
+		Namespace:       "default",
+		Filters:         []string{"Pod"},
+		MaxConcurrency:  1,
+		PropagateLabels: []string{"team"},
+	}
+	a.RunAnalysis()
+
+	output, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	var jsonOutput JsonOutput
+	require.NoError(t, json.Unmarshal(output, &jsonOutput))
+	require.Len(t, jsonOutput.Results, 1)
+	require.Equal(t, map[string]string{"team": "payments"}, jsonOutput.Results[0].Labels)
+}
+
+// Test: MaxProblems keeps the most severe results once duplicates are collapsed
+func TestAnalysis_MaxProblems(t *testing.T) {
+	viper.Reset()
+	a := Analysis{
+		MaxProblems: 2,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/first", Error: []common.Failure{{Text: "crash loop"}}},
+			{Kind: "Pod", Name: "default/first", Error: []common.Failure{{Text: "crash loop"}}},
+			{Kind: "Pod", Name: "default/second", Error: []common.Failure{{Text: "crash loop"}}},
+			{Kind: "Node", Name: "node", Error: []common.Failure{{Text: "not ready"}}, Severity: common.SeverityCritical},
+		},
+	}
+	a.finishResults()
+
+	require.Len(t, a.Results, 2)
+	require.Equal(t, "node", a.Results[0].Name)
+	require.Equal(t, "default/first", a.Results[1].Name)
+	require.Equal(t, 3, a.getJsonOutput().TotalResults)
+	output, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "Showing 2 of 3 problems")
+
+	// The capped results compete again with the results of a later run.
+	a.Results = append(a.Results, common.Result{Kind: "Service", Name: "default/service", Error: []common.Failure{{Text: "no endpoints"}}, Severity: common.SeverityCritical})
+	a.finishResults()
+	require.Equal(t, []string{"node", "default/service"}, []string{a.Results[0].Name, a.Results[1].Name})
+	require.Equal(t, 4, a.getJsonOutput().TotalResults)
+
+	// Without a cap, the total isn't reported.
+	a = Analysis{Results: []common.Result{{Kind: "Pod", Name: "default/first"}}}
+	a.finishResults()
+	require.Zero(t, a.getJsonOutput().TotalResults)
+}
+
+// Test: Analyze runs the analyzers and explains the results without printing
+func TestAnalysis_Analyze(t *testing.T) {
+	viper.Reset()
+	viper.SetDefault("active_filters", []string{})
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	clientset := fake.NewSimpleClientset(
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "example",
+				Namespace: "default",
+			},
+			Status: v1.PodStatus{
+				Phase: v1.PodPending,
+				Conditions: []v1.PodCondition{
+					{
+						Type:    v1.PodScheduled,
+						Reason:  "Unschedulable",
+						Message: "0/1 nodes are available",
+					},
+				},
+			},
+		},
+	)
+	a := Analysis{
+		Context:            context.Background(),
+		Filters:            []string{"Pod"},
+		Namespace:          "default",
+		MaxConcurrency:     1,
+		Client:             &kubernetes.Client{Client: clientset},
+		AIClient:           &ai.NoOpAIClient{},
+		AnalysisAIProvider: "noopai",
+		Cache:              disabledCache,
+		PromptMap:          map[string]string{"default": "%s %s %s"},
+		Explain:            true,
+		Sink:               &recordingSink{},
+	}
+
+	output, err := a.Analyze()
+	require.NoError(t, err)
+	require.Equal(t, StateProblemDetected, output.Status)
+	require.Equal(t, "noopai", output.Provider)
+	require.Len(t, output.Results, 1)
+	require.Equal(t, "Pod", output.Results[0].Kind)
+	require.NotEmpty(t, output.Results[0].Details)
+	require.Equal(t, []JsonOutput{output}, a.Sink.(*recordingSink).outputs)
+}
+
+// Test: NoProgress replaces the progress bar of the text output with a single line
+func TestGetAIResults_NoProgress(t *testing.T) {
+	viper.Reset()
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	a := Analysis{
+		AIClient:   &ai.NoOpAIClient{},
+		Cache:      disabledCache,
+		PromptMap:  map[string]string{"default": "%s %s %s"},
+		NoProgress: true,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "pod failure"}}},
+		},
+	}
+	var err error
+	output := util.CaptureOutput(func() {
+		err = a.GetAIResults("text", false)
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Analyzing 1 results\n", output)
+	require.NotEmpty(t, a.Results[0].Details)
+}
+
+// Test: the results below ExplainMinSeverity are kept unexplained, without a cache lookup
+func TestGetAIResults_ExplainMinSeverity(t *testing.T) {
+	viper.Reset()
+	a := Analysis{
+		AIClient:           &ai.NoOpAIClient{},
+		Cache:              newMemoryCache(),
+		Language:           "english",
+		PromptMap:          map[string]string{"default": "%s %s"},
+		ExplainMinSeverity: common.SeverityCritical,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/warning", Severity: common.SeverityWarning, Error: []common.Failure{{Text: "warning failure"}}},
+			{Kind: "Pod", Name: "default/critical", Severity: common.SeverityCritical, Error: []common.Failure{{Text: "critical failure"}}},
+		},
+	}
+	require.NoError(t, a.GetAIResults("json", false))
+
+	require.Empty(t, a.Results[0].Details)
+	require.Empty(t, a.Results[0].Provider)
+	require.Equal(t, "I am a noop response to the prompt english critical failure", a.Results[1].Details)
+	require.Equal(t, CacheStats{Misses: 1}, a.CacheStats())
+
+	output, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "default/warning")
+}
+
+// Test: the explanations taken from the cache are flagged as cached in the output
+func TestGetAIResults_Cached(t *testing.T) {
+	viper.Reset()
+	a := Analysis{
+		AIClient:  &ai.NoOpAIClient{},
+		Cache:     newMemoryCache(),
+		Language:  "english",
+		PromptMap: map[string]string{"default": "%s %s"},
+		Explain:   true,
+		Results:   []common.Result{{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "pending"}}}},
+	}
+	require.NoError(t, a.GetAIResults("json", false))
+	require.False(t, a.Results[0].Cached)
+	output, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.NotContains(t, string(output), "(cached)")
+
+	a.Results[0].Details = ""
+	require.NoError(t, a.GetAIResults("json", false))
+	require.True(t, a.Results[0].Cached)
+	require.Equal(t, "I am a noop response to the prompt english pending", a.Results[0].Details)
+
+	output, err = a.PrintOutput("json")
+	require.NoError(t, err)
+	require.Contains(t, string(output), `"cached": true`)
+	output, err = a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "default/web() (cached)")
+}
+
+// systemAIClient records the system and user portions of the prompts.
+type systemAIClient struct {
+	ai.NoOpAIClient
+	systems []string
+	prompts []string
+}
+
+func (c *systemAIClient) GetCompletionWithSystem(_ context.Context, system string, prompt string) (string, ai.TokenUsage, error) {
+	c.systems = append(c.systems, system)
+	c.prompts = append(c.prompts, prompt)
+	return "explanation", ai.TokenUsage{}, nil
+}
+
+// Test: the instructions of the default prompt are sent as the system prompt
+func TestGetAIResultForSanitizedFailures_SystemPrompt(t *testing.T) {
+	viper.Reset()
+	client := &systemAIClient{}
+	a := Analysis{
+		Context:  context.Background(),
+		AIClient: client,
+		Cache:    newMemoryCache(),
+		Language: "english",
+	}
+	response, _, _, _, err := a.getAIResultForSanitizedFailures("Pod", []string{"crash loop"}, ai.PromptMap["default"])
+	require.NoError(t, err)
+	require.Equal(t, "explanation", response)
+	require.Len(t, client.systems, 1)
+	require.Contains(t, client.systems[0], "--- english --- language")
+	require.NotContains(t, client.systems[0], "crash loop")
+	require.Equal(t, []string{"--- crash loop ---"}, client.prompts)
+
+	// The other providers get the instructions and the failures in one prompt.
+	echo := &echoAIClient{}
+	a.AIClient = echo
+	a.Cache = newMemoryCache()
+	_, _, _, _, err = a.getAIResultForSanitizedFailures("Pod", []string{"crash loop"}, ai.PromptMap["default"])
+	require.NoError(t, err)
+	require.Equal(t, []string{client.systems[0] + "\n--- crash loop ---"}, echo.prompts)
+}
+
+// customRestAIClient echoes the prompts as the custom REST provider.
+type customRestAIClient struct {
+	echoAIClient
+}
+
+func (c *customRestAIClient) GetName() string {
+	return ai.CustomRestClientName
+}
+
+// Test: the raw prompt of the configuration wraps the prompts of the custom REST provider
+func TestGetAIResultForSanitizedFailures_CustomRawPrompt(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("ai", map[string]interface{}{
+		"providers": []map[string]interface{}{{"name": "noopai"}},
+		"promptmap": map[string]string{"raw": `{"lang": "%s", "input": "%s", "instructions": "%s"}`},
+	})
+	a := Analysis{Context: context.Background(), Cache: newMemoryCache(), Language: "english"}
+	require.NoError(t, a.configureAI("noopai", nil))
+
+	client := &customRestAIClient{}
+	a.AIClient = client
+	_, _, _, _, err := a.getAIResultForSanitizedFailures("Pod", []string{"crash loop"}, "%s %s")
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"lang": "english", "input": "crash loop", "instructions": "english crash loop"}`}, client.prompts)
+
+	viper.Set("ai.promptmap", map[string]string{"raw": `{"input": "%s"}`})
+	err = a.configureAI("noopai", nil)
+	require.ErrorContains(t, err, "ai.promptmap.raw: expected 3 %s placeholders")
+}
+
+// Test: the AI configuration is validated before picking the provider
+func TestConfigureAI_InvalidConfiguration(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("ai", map[string]interface{}{
+		"providers": []map[string]interface{}{{"name": "openai", "password": "first"}, {"name": "openai", "password": "second"}},
+	})
+	a := Analysis{Context: context.Background(), Cache: newMemoryCache()}
+	require.ErrorContains(t, a.configureAI("openai", nil), "duplicate provider names openai")
+
+	viper.Set("ai", map[string]interface{}{
+		"providers":       []map[string]interface{}{{"name": "noopai"}},
+		"defaultprovider": "ollama",
+	})
+	require.ErrorContains(t, a.configureAI("", nil), "ai.defaultprovider: ollama is not a configured provider")
+}
+
+// Test: the custom prompts are interpolated with the prompt variables
+func TestConfigureAI_PromptVariables(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("ai", map[string]interface{}{
+		"providers":   []map[string]interface{}{{"name": "noopai"}},
+		"promptmap":   map[string]interface{}{"default": "Explain in %s for ${cluster}: %s"},
+		"prompt_vars": map[string]interface{}{"cluster": "prod-eu"},
+	})
+	a := Analysis{Context: context.Background(), Cache: newMemoryCache()}
+	require.NoError(t, a.configureAI("noopai", nil))
+	require.Equal(t, "Explain in %s for prod-eu: %s", a.PromptMap["default"])
+
+	viper.Set("ai.prompt_vars", map[string]interface{}{})
+	require.EqualError(t, a.configureAI("noopai", nil), "ai.promptmap.default: undefined prompt variables cluster, set them in ai.prompt_vars or in the environment")
+}
+
+// remediationAIClient answers with a commands section when asked for one.
+type remediationAIClient struct {
+	ai.NoOpAIClient
+}
+
+func (c *remediationAIClient) GetCompletion(_ context.Context, prompt string) (string, error) {
+	if !strings.Contains(prompt, "Commands:") {
+		return "Error: crash\nSolution: restart", nil
+	}
+	return "Error: crash\nSolution: restart\nCommands:\n```bash\n$ kubectl rollout restart deployment/web -n default\nkubectl logs web -n default --previous\n```", nil
+}
+
+// Test: the commands of the AI provider are split off its explanation
+func TestAnalysis_ExplainRemediation(t *testing.T) {
+	viper.Reset()
+	results := []common.Result{{
+		Kind:        "Deployment",
+		Name:        "default/web",
+		Error:       []common.Failure{{Text: "not available"}},
+		Remediation: []common.Remediation{{Command: "kubectl rollout restart deployment/web -n default", Description: "Restart the pods of the deployment"}},
+	}}
+	a := Analysis{
+		Context:            context.Background(),
+		AIClient:           &remediationAIClient{},
+		Cache:              newMemoryCache(),
+		Language:           "english",
+		PromptMap:          ai.PromptMap,
+		Explain:            true,
+		ExplainRemediation: true,
+		Results:            append([]common.Result(nil), results...),
+	}
+	require.NoError(t, a.explainResults(false, false))
+	require.Equal(t, "Error: crash\nSolution: restart", a.Results[0].Details)
+	require.Equal(t, []common.Remediation{
+		{Command: "kubectl rollout restart deployment/web -n default", Description: "Restart the pods of the deployment"},
+		{Command: "kubectl logs web -n default --previous"},
+	}, a.Results[0].Remediation)
+
+	output, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "Remediation:\n- kubectl rollout restart deployment/web -n default")
+
+	// Without it, the default prompt is used and the cached answer isn't reused.
+	a.ExplainRemediation = false
+	a.Results = append([]common.Result(nil), results...)
+	require.NoError(t, a.explainResults(false, false))
+	require.Equal(t, "Error: crash\nSolution: restart", a.Results[0].Details)
+	require.Len(t, a.Results[0].Remediation, 1)
+}
+
+// Test: the custom prompts aren't replaced by the remediation one
+func TestAnalysis_ExplainRemediationCustomPrompt(t *testing.T) {
+	viper.Reset()
+	promptMap := maps.Clone(ai.PromptMap)
+	promptMap["default"] = "Explain in %s, then list the Commands: %s"
+	a := Analysis{
+		Context:            context.Background(),
+		AIClient:           &remediationAIClient{},
+		Cache:              newMemoryCache(),
+		Language:           "english",
+		PromptMap:          promptMap,
+		Explain:            true,
+		ExplainRemediation: true,
+		Results:            []common.Result{{Kind: "Deployment", Name: "default/web", Error: []common.Failure{{Text: "not available"}}}},
+	}
+	require.Equal(t, promptMap["default"], a.promptTemplate("Deployment"))
+	require.NoError(t, a.explainResults(false, false))
+	require.Contains(t, a.Results[0].Details, "Commands:")
+	require.Empty(t, a.Results[0].Remediation)
+}
+
+// Test: the commands section is split off in its usual forms
+func TestExtractRemediation(t *testing.T) {
+	details, remediation := extractRemediation("Error: crash\nSolution: restart\ncommands: kubectl get pods\n- `kubectl describe pod web`\n")
+	require.Equal(t, "Error: crash\nSolution: restart", details)
+	require.Equal(t, []common.Remediation{{Command: "kubectl get pods"}, {Command: "kubectl describe pod web"}}, remediation)
+
+	details, remediation = extractRemediation("Error: crash\nCommands:\nNone")
+	require.Equal(t, "Error: crash", details)
+	require.Empty(t, remediation)
+
+	details, remediation = extractRemediation("Error: crash\nSolution: restart")
+	require.Equal(t, "Error: crash\nSolution: restart", details)
+	require.Nil(t, remediation)
+}
+
+// closingAIClient counts its closes.
+type closingAIClient struct {
+	ai.NoOpAIClient
+	closes int
+}
+
+func (c *closingAIClient) Close() {
+	c.closes++
+}
+
+// Test: Close flushes the cache and closes the AI clients once
+func TestAnalysis_Close(t *testing.T) {
+	cache := newMemoryCache()
+	client, fallback := &closingAIClient{}, &closingAIClient{}
+	a := Analysis{
+		Cache:              cache,
+		AIClient:           client,
+		FallbackAIBackends: []AIBackend{{Client: fallback}},
+	}
+	a.Close()
+	a.Close()
+	require.Equal(t, 1, cache.closes)
+	require.Equal(t, 1, client.closes)
+	require.Equal(t, 1, fallback.closes)
+
+	// Without an AI client, or a cache, e.g. when not explaining.
+	(&Analysis{}).Close()
+}
+
+// confidenceAIClient is confident in the explanations of the crash loops only,
+// when asked for its confidence.
+type confidenceAIClient struct {
+	ai.NoOpAIClient
+	calls int
+}
+
+func (c *confidenceAIClient) GetCompletionWithUsage(ctx context.Context, prompt string) (string, ai.TokenUsage, error) {
+	c.calls++
+	confidence := 0.2
+	if strings.Contains(prompt, "crash loop") {
+		confidence = 0.9
+	}
+	return "explanation of " + prompt, ai.TokenUsage{PromptTokens: 1, Confidence: &confidence}, nil
+}
+
+// Test: CacheOnly explains the cached results only, without calling the AI provider
+func TestAnalysis_CacheOnly(t *testing.T) {
+	viper.Reset()
+	client := &confidenceAIClient{}
+	a := Analysis{
+		Context:   context.Background(),
+		AIClient:  client,
+		Cache:     newMemoryCache(),
+		Language:  "english",
+		PromptMap: map[string]string{"default": "%s %s"},
+		Results:   []common.Result{{Kind: "Pod", Name: "default/crash", Error: []common.Failure{{Text: "crash loop"}}}},
+	}
+	require.NoError(t, a.explainResults(false, false))
+	require.Equal(t, 1, client.calls)
+
+	viper.Set("verbose", true)
+	defer viper.Reset()
+	a.CacheOnly = true
+	a.Results = []common.Result{
+		{Kind: "Pod", Name: "default/crash", Error: []common.Failure{{Text: "crash loop"}}},
+		{Kind: "Pod", Name: "default/pending", Error: []common.Failure{{Text: "pending"}}},
+	}
+	output := util.CaptureOutput(func() {
+		require.NoError(t, a.explainResults(false, false))
+	})
+	require.Equal(t, 1, client.calls)
+	require.Equal(t, "explanation of english crash loop", a.Results[0].Details)
+	require.Empty(t, a.Results[1].Details)
+	require.Empty(t, a.Results[1].Provider)
+	require.Contains(t, output, `msg="Results without a cached explanation" results=1`)
+}
+
+// Test: the explanations below MinConfidence are dropped, from the cache too
+func TestAnalysis_MinConfidence(t *testing.T) {
+	viper.Reset()
+	client := &confidenceAIClient{}
+	a := Analysis{
+		Context:       context.Background(),
+		AIClient:      client,
+		Cache:         newMemoryCache(),
+		Language:      "english",
+		MinConfidence: 0.5,
+		PromptMap:     map[string]string{"default": "%s %s"},
+	}
+	results := []common.Result{
+		{Kind: "Pod", Name: "default/crash", Error: []common.Failure{{Text: "crash loop"}}},
+		{Kind: "Pod", Name: "default/pending", Error: []common.Failure{{Text: "pending"}}},
+	}
+
+	for run := 0; run < 2; run++ {
+		a.Results = append([]common.Result(nil), results...)
+		require.NoError(t, a.explainResults(false, false))
+		require.Equal(t, "explanation of english crash loop", a.Results[0].Details)
+		require.InDelta(t, 0.9, *a.Results[0].Confidence, 1e-9)
+		require.Empty(t, a.Results[1].Details)
+		require.InDelta(t, 0.2, *a.Results[1].Confidence, 1e-9)
+	}
+	require.Equal(t, 2, client.calls)
+
+	// The explanations without a confidence are kept.
+	a = Analysis{
+		Context:       context.Background(),
+		AIClient:      &ai.NoOpAIClient{},
+		Cache:         newMemoryCache(),
+		MinConfidence: 0.5,
+		PromptMap:     map[string]string{"default": "%s %s"},
+		Results:       results[1:],
+	}
+	require.NoError(t, a.explainResults(false, false))
+	require.NotEmpty(t, a.Results[0].Details)
+	require.Nil(t, a.Results[0].Confidence)
+}
+
+// Test: the failure texts longer than MaxInputLength keep their head and tail
+func TestAnalysis_TruncateInput(t *testing.T) {
+	a := Analysis{MaxInputLength: 23}
+	require.Equal(t, "short", a.truncateInput("short"))
+	require.Equal(t, "héadx\n[truncated]\nxtail", a.truncateInput("héad"+strings.Repeat("x", 100)+"tail"))
+
+	a.MaxInputLength = 0
+	long := strings.Repeat("x", 100)
+	require.Equal(t, long, a.truncateInput(long))
+}
+
+// Test: the truncated failure texts are sent to the AI provider and cached apart from the full ones
+func TestGetAIResultForSanitizedFailures_MaxInputLength(t *testing.T) {
+	viper.Reset()
+	client := &echoAIClient{}
+	a := Analysis{
+		Context:        context.Background(),
+		AIClient:       client,
+		Cache:          newMemoryCache(),
+		MaxInputLength: 23,
+	}
+	text := "head" + strings.Repeat("x", 100) + "tail"
+	_, _, _, _, err := a.getAIResultForSanitizedFailures("Pod", []string{text}, "%s %s")
+	require.NoError(t, err)
+	require.Equal(t, []string{"english headx\n[truncated]\nxtail"}, client.prompts)
+	require.True(t, a.Cache.Exists(a.cacheKey(a.primaryAIBackend(), "headx\n[truncated]\nxtail")))
+
+	a.MaxInputLength = 0
+	_, _, _, _, err = a.getAIResultForSanitizedFailures("Pod", []string{text}, "%s %s")
+	require.NoError(t, err)
+	require.Len(t, client.prompts, 2)
+	require.Equal(t, CacheStats{Misses: 2}, a.CacheStats())
+}
+
+// streamingAIClient streams its response in words, the first failures are
+// rate limited after the first word.
+type streamingAIClient struct {
+	ai.NoOpAIClient
+	failures int
+}
+
+func (c *streamingAIClient) GetCompletionStream(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	for _, word := range []string{"streamed ", "answer"} {
+		onChunk(word)
+		if c.failures > 0 {
+			c.failures--
+			return "", errors.New("error, status code: 429, message: rate limited")
+		}
+	}
+	return "streamed answer", nil
+}
+
+// Test: Stream prints the explanations as they arrive and caches the whole response
+func TestGetAIResults_Stream(t *testing.T) {
+	viper.Reset()
+	c := newMemoryCache()
+	a := Analysis{
+		AIClient:  &streamingAIClient{},
+		Cache:     c,
+		PromptMap: map[string]string{"default": "%s %s %s"},
+		Stream:    true,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "pod failure"}}},
+		},
+	}
+	var err error
+	output := util.CaptureOutput(func() {
+		err = a.GetAIResults("text", false)
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Pod default/pod:\nstreamed answer\n", output)
+	require.Equal(t, "streamed answer", a.Results[0].Details)
+
+	key := a.cacheKey(a.primaryAIBackend(), "pod failure")
+	cached, err := c.Load(key)
+	require.NoError(t, err)
+	require.Equal(t, base64.StdEncoding.EncodeToString([]byte("streamed answer")), cached)
+}
+
+// Test: the chunks streamed before a retry are marked as discarded
+func TestGetAIResults_StreamRetried(t *testing.T) {
+	viper.Reset()
+	a := Analysis{
+		AIClient:       &streamingAIClient{failures: 1},
+		Cache:          newMemoryCache(),
+		PromptMap:      map[string]string{"default": "%s %s %s"},
+		Stream:         true,
+		MaxRetries:     1,
+		RetryBaseDelay: time.Millisecond,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "pod failure"}}},
+		},
+	}
+	var err error
+	output := util.CaptureOutput(func() {
+		err = a.GetAIResults("text", false)
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Pod default/pod:\nstreamed "+streamInterruptedMarker+"streamed answer\n", output)
+	require.Equal(t, "streamed answer", a.Results[0].Details)
+}
+
+// partialAnalyzer returns a result along with an error.
+type partialAnalyzer struct{}
+
+func (partialAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+	return []common.Result{{Kind: "Pod", Name: "default/pod"}}, errors.New("listing events: forbidden")
+}
+
+// Test: analyzer failures are reported as structured errors, keeping partial results
+func TestExecuteAnalyzer_PartialError(t *testing.T) {
+	viper.Set("verbose", false)
+	a := Analysis{Context: context.Background()}
+	semaphore := make(chan struct{}, 1)
+	semaphore <- struct{}{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	c := a.startCollector()
+	a.executeAnalyzer(partialAnalyzer{}, "Pod", common.Analyzer{Namespace: "default"}, semaphore, &wg, c)
+	c.wait()
+	wg.Wait()
+
+	require.Equal(t, []string{"[Pod] namespace default: listing events: forbidden (partial results)"}, a.Errors.Strings())
+	require.Equal(t, "Pod", a.Errors[0].Analyzer)
+	require.Equal(t, PhaseAnalysis, a.Errors[0].Phase)
+	require.True(t, a.Errors[0].Partial)
+	require.EqualError(t, errors.Unwrap(a.Errors[0]), "listing events: forbidden")
+	require.Len(t, a.Results, 1)
+
+	output, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	var got struct {
+		Errors []map[string]interface{} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(output, &got))
+	require.Equal(t, []map[string]interface{}{
+		{"analyzer": "Pod", "phase": "analysis", "namespace": "default", "partial": true, "error": "listing events: forbidden"},
+	}, got.Errors)
+
+	// The errors of the outputs of older versions were strings.
+	var previous JsonOutput
+	require.NoError(t, json.Unmarshal([]byte(`{"errors":["[Pod] forbidden"]}`), &previous))
+	require.Equal(t, []string{"[Pod] forbidden"}, previous.Errors.Strings())
+	require.NoError(t, json.Unmarshal(output, &previous))
+	require.Equal(t, a.Errors.Strings(), previous.Errors.Strings())
+}
+
+// Test: the stats of failed analyzers are written to the stats file, durations in milliseconds
+func TestAnalysis_WriteStats(t *testing.T) {
+	viper.Set("verbose", false)
+	a := Analysis{Context: context.Background(), WithStats: true}
+	semaphore := make(chan struct{}, 1)
+	semaphore <- struct{}{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	c := a.startCollector()
+	a.executeAnalyzer(partialAnalyzer{}, "Pod", common.Analyzer{Namespace: "default"}, semaphore, &wg, c)
+	c.wait()
+	wg.Wait()
+	require.Len(t, a.Stats, 1)
+	a.Stats[0].DurationTime = 1500 * time.Microsecond
+
+	path := filepath.Join(t.TempDir(), "stats.json")
+	require.NoError(t, a.WriteStats(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got struct {
+		Analyzers []map[string]interface{} `json:"analyzers"`
+	}
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Len(t, got.Analyzers, 1)
+	require.Equal(t, "Pod", got.Analyzers[0]["analyzer"])
+	require.Equal(t, 1.5, got.Analyzers[0]["durationMs"])
+	require.Contains(t, got.Analyzers[0], "problems")
+	require.NotContains(t, got.Analyzers[0], "durationTime")
+
+	var stats JsonStats
+	require.NoError(t, json.Unmarshal(data, &stats))
+	require.Equal(t, a.Stats, stats.Analyzers)
+
+	require.ErrorContains(t, a.WriteStats(filepath.Join(path, "stats.json")), "writing stats to")
+}
+
+// Test: analyzers missing live data offline are reported, the others run unchanged
+func TestAnalysis_RunAnalysisOffline(t *testing.T) {
+	viper.Reset()
+	a := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Pod", "Deployment"},
+		MaxConcurrency: 1,
+		Client:         &kubernetes.Client{Client: fake.NewSimpleClientset(), Offline: true},
+	}
+	a.RunAnalysis()
+	require.Equal(t, []string{"[Pod] analyzing manifests offline, events aren't available, containers stuck creating aren't explained"}, a.Errors.Strings())
+}
+
+// Test: sharded by namespace, at most NamespaceConcurrency analyzers query each namespace at once
+func TestAnalysis_RunAnalysisNamespaceConcurrency(t *testing.T) {
+	viper.Reset()
+	clientset := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "first"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "second"}},
+	)
+	var mutex sync.Mutex
+	inflight := map[string]int{}
+	maxInflight := map[string]int{}
+	var nodeLists int
+	clientset.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		namespace := action.GetNamespace()
+		if action.GetResource().Resource == "namespaces" {
+			return false, nil, nil
+		}
+		mutex.Lock()
+		if action.GetResource().Resource == "nodes" {
+			nodeLists++
+		}
+		inflight[namespace]++
+		maxInflight[namespace] = max(maxInflight[namespace], inflight[namespace])
+		mutex.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mutex.Lock()
+		inflight[namespace]--
+		mutex.Unlock()
+		return false, nil, nil
+	})
+
+	a := Analysis{
+		Context:              context.Background(),
+		Filters:              []string{"Deployment", "ReplicaSet", "StatefulSet", "CronJob", "Node"},
+		MaxConcurrency:       10,
+		NamespaceConcurrency: 1,
+		Client:               &kubernetes.Client{Client: clientset},
+		WithStats:            true,
+	}
+	a.RunAnalysis()
+
+	require.Empty(t, a.Errors)
+	require.Equal(t, 1, maxInflight["first"])
+	require.Equal(t, 1, maxInflight["second"])
+	// Node is cluster-scoped, so it runs once.
+	require.Equal(t, 1, nodeLists)
+	require.Len(t, a.Stats, 9)
+}
+
+// Test: the label and field selectors are both forwarded to the List calls of the analyzers
+func TestAnalysis_RunAnalysisFieldSelector(t *testing.T) {
+	viper.Reset()
+	for _, tt := range []struct {
+		name          string
+		labelSelector string
+		fieldSelector string
+	}{
+		{name: "both selectors", labelSelector: "app=web", fieldSelector: "status.phase=Running"},
+		{name: "no selector"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			var mutex sync.Mutex
+			selectors := map[string][2]string{}
+			clientset.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				restrictions := action.(k8stesting.ListAction).GetListRestrictions()
+				mutex.Lock()
+				defer mutex.Unlock()
+				if resource := action.GetResource().Resource; resource != "events" {
+					selectors[resource] = [2]string{restrictions.Labels.String(), restrictions.Fields.String()}
+				}
+				return false, nil, nil
+			})
+
+			a := Analysis{
+				Context:       context.Background(),
+				Filters:       []string{"Pod", "Deployment", "Node"},
+				Client:        &kubernetes.Client{Client: clientset},
+				LabelSelector: tt.labelSelector,
+				FieldSelector: tt.fieldSelector,
+			}
+			a.RunAnalysis()
+
+			require.Empty(t, a.Errors)
+			want := [2]string{tt.labelSelector, tt.fieldSelector}
+			require.Equal(t, map[string][2]string{"pods": want, "deployments": want, "nodes": want}, selectors)
+		})
+	}
+}
+
+// Test: the listed namespaces are analyzed separately, within MaxConcurrency, and their results merged
+func TestAnalysis_RunAnalysisNamespaces(t *testing.T) {
+	viper.Reset()
+	pendingPod := func(namespace string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: namespace},
+			Status: v1.PodStatus{
+				Phase:      v1.PodPending,
+				Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}},
+			},
+		}
+	}
+	clientset := fake.NewSimpleClientset(pendingPod("first"), pendingPod("second"), pendingPod("third"))
+	var mutex sync.Mutex
+	var inflight, maxInflight, nodeLists int
+	clientset.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		mutex.Lock()
+		if action.GetResource().Resource == "nodes" {
+			nodeLists++
+		}
+		inflight++
+		maxInflight = max(maxInflight, inflight)
+		mutex.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mutex.Lock()
+		inflight--
+		mutex.Unlock()
+		return false, nil, nil
+	})
+
+	a := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Pod", "Deployment", "Node"},
+		Namespaces:     []string{"first", "second"},
+		MaxConcurrency: 2,
+		Client:         &kubernetes.Client{Client: clientset},
+	}
+	a.RunAnalysis()
+
+	require.Empty(t, a.Errors)
+	var names []string
+	for _, result := range a.Results {
+		names = append(names, result.Name)
+	}
+	require.ElementsMatch(t, []string{"first/pending", "second/pending"}, names)
+	require.LessOrEqual(t, maxInflight, 2)
+	// Node is cluster-scoped, so it runs once.
+	require.Equal(t, 1, nodeLists)
+}
+
+// concurrentAIClient answers after a delay decreasing with the failure number,
+// so the later results complete first, and records the requests in flight.
+type concurrentAIClient struct {
+	ai.NoOpAIClient
+	mutex       sync.Mutex
+	inflight    int
+	maxInflight int
+	fail        string
+}
+
+func (c *concurrentAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	c.mutex.Lock()
+	c.inflight++
+	c.maxInflight = max(c.maxInflight, c.inflight)
+	c.mutex.Unlock()
+	defer func() {
+		c.mutex.Lock()
+		c.inflight--
+		c.mutex.Unlock()
+	}()
+
+	if c.fail != "" && strings.Contains(prompt, c.fail) {
+		return "", errRateLimited
+	}
+	var n int
+	_, _ = fmt.Sscanf(prompt[strings.LastIndex(prompt, " ")+1:], "%d", &n)
+	select {
+	case <-time.After(time.Duration(10-n) * 5 * time.Millisecond):
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	return c.NoOpAIClient.GetCompletion(ctx, prompt)
+}
+
+func newConcurrentAnalysis(client ai.IAI, concurrency int) *Analysis {
+	a := &Analysis{
+		Context:       context.Background(),
+		AIClient:      client,
+		Cache:         newMemoryCache(),
+		Language:      "english",
+		PromptMap:     map[string]string{"default": "%s %s"},
+		AIConcurrency: concurrency,
+	}
+	for i := 0; i < 8; i++ {
+		a.Results = append(a.Results, common.Result{
+			Kind:  "Pod",
+			Name:  fmt.Sprintf("default/pod-%d", i),
+			Error: []common.Failure{{Text: fmt.Sprintf("failure %d", i)}},
+		})
+	}
+	return a
+}
+
+// Test: the results are explained concurrently, up to AIConcurrency at once, and keep their order
+func TestGetAIResults_Concurrent(t *testing.T) {
+	client := &concurrentAIClient{}
+	a := newConcurrentAnalysis(client, 3)
+	require.NoError(t, a.GetAIResults("json", false))
+
+	require.Equal(t, 3, client.maxInflight)
+	for i, result := range a.Results {
+		require.Equal(t, fmt.Sprintf("I am a noop response to the prompt english failure %d", i), result.Details)
+		require.Equal(t, "noopai", result.Provider)
+	}
+}
+
+// Test: the explanations are bounded by defaultAIConcurrency, whatever MaxConcurrency
+func TestGetAIResults_DefaultConcurrency(t *testing.T) {
+	client := &concurrentAIClient{}
+	a := newConcurrentAnalysis(client, 0)
+	a.MaxConcurrency = 10
+	require.NoError(t, a.GetAIResults("json", false))
+
+	require.Equal(t, defaultAIConcurrency, client.maxInflight)
+}
+
+// Test: the first failure cancels the requests of the other workers
+func TestGetAIResults_ConcurrentFailureCancels(t *testing.T) {
+	client := &concurrentAIClient{fail: "failure 2"}
+	a := newConcurrentAnalysis(client, 4)
+	err := a.GetAIResults("json", false)
+	require.ErrorContains(t, err, "exhausted API quota for AI provider noopai")
+
+	// The requests in flight were cancelled and the remaining ones not started.
+	for _, result := range a.Results {
+		require.Empty(t, result.Details)
+	}
+	require.Empty(t, a.Cache.(*memoryCache).data)
+	require.NoError(t, a.Context.Err())
+}
+
+// Test: the results explained before a failure keep their Details
+func TestGetAIResults_PartialFailure(t *testing.T) {
+	client := &concurrentAIClient{fail: "failure 2"}
+	a := newConcurrentAnalysis(client, 1)
+	err := a.GetAIResults("json", false)
+	var explanationErr *ExplanationError
+	require.ErrorAs(t, err, &explanationErr)
+	require.Equal(t, 2, explanationErr.Explained)
+	require.Equal(t, 6, explanationErr.Unexplained)
+	require.ErrorContains(t, err, "exhausted API quota for AI provider noopai: ")
+	require.ErrorContains(t, err, " (2 of 8 results explained)")
+
+	for i, result := range a.Results {
+		if i < 2 {
+			require.Equal(t, fmt.Sprintf("I am a noop response to the prompt english failure %d", i), result.Details)
+		} else {
+			require.Empty(t, result.Details)
+		}
+	}
+
+	// Analyze returns them too.
+	a = newConcurrentAnalysis(client, 1)
+	a.Explain = true
+	a.Client = &kubernetes.Client{Client: fake.NewSimpleClientset()}
+	a.Filters = []string{"Pod"}
+	output, err := a.Analyze()
+	require.ErrorAs(t, err, &explanationErr)
+	require.Len(t, output.Results, 8)
+	require.NotEmpty(t, output.Results[0].Details)
+}
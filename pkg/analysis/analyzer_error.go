@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// unknownFilterError is reported for the filters naming no analyzer. Its
+// message names the filter, so AnalyzerError doesn't prefix it.
+type unknownFilterError struct {
+	filter string
+}
+
+func (e *unknownFilterError) Error() string {
+	return fmt.Sprintf("\"%s\" filter does not exist. Please run k8sgpt filters list.", e.filter)
+}
+
+// ErrNoAnalyzers is reported when none of the filters exist, so that the
+// empty results of the run aren't mistaken for a healthy cluster.
+var ErrNoAnalyzers = errors.New("none of the filters exist, no analyzer ran")
+
+// ErrorPhase is the stage of the analysis an AnalyzerError occurred in.
+type ErrorPhase string
+
+const (
+	// PhaseConfiguration covers the filters, the custom analyzers and the
+	// manifests.
+	PhaseConfiguration ErrorPhase = "configuration"
+	// PhaseAnalysis covers running the analyzers.
+	PhaseAnalysis ErrorPhase = "analysis"
+	// PhaseDocumentation covers fetching the Kubernetes docs of WithDoc.
+	PhaseDocumentation ErrorPhase = "documentation"
+	// PhaseExplanation covers explaining the results.
+	PhaseExplanation ErrorPhase = "explanation"
+	// PhaseDelivery covers delivering the output, e.g. to the Webhook.
+	PhaseDelivery ErrorPhase = "delivery"
+)
+
+// AnalyzerError is an error the analysis went on after, e.g. the failure of an
+// analyzer as opposed to the problems it found in the cluster. Analyzer names
+// the analyzer, or the part of the analysis (e.g. Budget), which failed.
+type AnalyzerError struct {
+	Analyzer string
+	Phase    ErrorPhase
+	// Namespace is the analyzed namespace, empty for all namespaces.
+	Namespace string
+	// Partial is set when the analyzer returned some results along with the
+	// error. Those results are reported.
+	Partial bool
+	Err     error
+	// Cluster is the kube context analyzed, when several were.
+	Cluster string
+}
+
+func (e *AnalyzerError) Error() string {
+	var msg string
+	var unknownFilter *unknownFilterError
+	if e.Analyzer != "" && !errors.As(e.Err, &unknownFilter) {
+		msg = fmt.Sprintf("[%s] ", e.Analyzer)
+	}
+	if e.Cluster != "" {
+		msg += fmt.Sprintf("cluster %s: ", e.Cluster)
+	}
+	if e.Namespace != "" {
+		msg += fmt.Sprintf("namespace %s: ", e.Namespace)
+	}
+	msg += e.Err.Error()
+	if e.Partial {
+		msg += " (partial results)"
+	}
+	return msg
+}
+
+func (e *AnalyzerError) Unwrap() error {
+	return e.Err
+}
+
+type analyzerErrorJSON struct {
+	Analyzer  string     `json:"analyzer,omitempty"`
+	Phase     ErrorPhase `json:"phase,omitempty"`
+	Namespace string     `json:"namespace,omitempty"`
+	Partial   bool       `json:"partial,omitempty"`
+	Error     string     `json:"error"`
+	Cluster   string     `json:"cluster,omitempty"`
+}
+
+// JSONSchemaAlias describes AnalyzerError by its JSON encoding in OutputSchema.
+func (AnalyzerError) JSONSchemaAlias() any {
+	return analyzerErrorJSON{}
+}
+
+func (e *AnalyzerError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(analyzerErrorJSON{e.Analyzer, e.Phase, e.Namespace, e.Partial, e.Err.Error(), e.Cluster})
+}
+
+// UnmarshalJSON also reads the errors of the outputs of the older versions,
+// which were strings, e.g. for Previous.
+func (e *AnalyzerError) UnmarshalJSON(data []byte) error {
+	var message string
+	if json.Unmarshal(data, &message) == nil {
+		*e = AnalyzerError{Err: errors.New(message)}
+		return nil
+	}
+	var fields analyzerErrorJSON
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*e = AnalyzerError{
+		Analyzer:  fields.Analyzer,
+		Phase:     fields.Phase,
+		Namespace: fields.Namespace,
+		Partial:   fields.Partial,
+		Err:       errors.New(fields.Error),
+		Cluster:   fields.Cluster,
+	}
+	return nil
+}
+
+// ExplanationError is returned when the AI provider failed part way through
+// explaining the results, e.g. once the quota is exhausted. The results
+// explained before the failure keep their Details, so the output is still
+// worth reporting.
+type ExplanationError struct {
+	// Explained and Unexplained count the results to explain which were and
+	// weren't explained.
+	Explained   int
+	Unexplained int
+	Err         error
+}
+
+func (e *ExplanationError) Error() string {
+	if e.Explained == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v (%d of %d results explained)", e.Err, e.Explained, e.Explained+e.Unexplained)
+}
+
+func (e *ExplanationError) Unwrap() error {
+	return e.Err
+}
+
+// Strings returns the messages of the errors, as listed by the text output.
+func (e AnalysisErrors) Strings() []string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+// addError reports in a.Errors an error of the analyzer, or of the part of the
+// analysis, which the analysis went on after.
+func (a *Analysis) addError(analyzer string, phase ErrorPhase, err error) {
+	a.Errors = append(a.Errors, &AnalyzerError{Analyzer: analyzer, Phase: phase, Err: err})
+}
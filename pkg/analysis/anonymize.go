@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"github.com/spf13/viper"
+)
+
+// getAnonymizePatterns compiles the anonymize.patterns configuration, the
+// extra data masked in failure texts when anonymizing.
+func getAnonymizePatterns() ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, pattern := range viper.GetStringSlice("anonymize.patterns") {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid anonymize pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, nil
+}
+
+// masker masks the matches of re with the value returned by mask.
+type masker struct {
+	re   *regexp.Regexp
+	mask func(string) string
+}
+
+// mask masks the matches of the AnonymizePatterns and of the extra maskers in
+// text. Matches overlapping the already masked values, or an earlier match,
+// are skipped so nothing gets masked twice.
+func (a *Analysis) mask(text string, masked []string, extra []masker) string {
+	maskers := extra
+	for _, re := range a.AnonymizePatterns {
+		maskers = append(maskers, masker{re: re, mask: a.patternMask})
+	}
+	return maskMatches(text, masked, maskers)
+}
+
+// redactedMask replaces the secrets redacted from the cached explanations.
+const redactedMask = "[REDACTED]"
+
+// redact replaces the matches of the AnonymizePatterns in text with
+// redactedMask. Unlike mask, the values can't be restored.
+func (a *Analysis) redact(text string) string {
+	maskers := make([]masker, 0, len(a.AnonymizePatterns))
+	for _, re := range a.AnonymizePatterns {
+		maskers = append(maskers, masker{re: re, mask: func(string) string { return redactedMask }})
+	}
+	return maskMatches(text, nil, maskers)
+}
+
+// maskMatches masks the matches of the maskers in text, skipping the ones
+// overlapping the masked values or an earlier match.
+func maskMatches(text string, masked []string, maskers []masker) string {
+	if len(maskers) == 0 {
+		return text
+	}
+
+	var protected [][]int
+	for _, value := range masked {
+		for offset := 0; value != ""; {
+			index := strings.Index(text[offset:], value)
+			if index < 0 {
+				break
+			}
+			start := offset + index
+			protected = append(protected, []int{start, start + len(value)})
+			offset = start + len(value)
+		}
+	}
+
+	// A match is its span followed by the index of its masker.
+	var matches [][]int
+	for i, m := range maskers {
+		for _, match := range m.re.FindAllStringIndex(text, -1) {
+			if match[0] < match[1] && !overlapsAny(match, protected) {
+				matches = append(matches, append(match, i))
+			}
+		}
+	}
+	// Prefer the earliest, then the longest, match.
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i][0] != matches[j][0] {
+			return matches[i][0] < matches[j][0]
+		}
+		return matches[i][1] > matches[j][1]
+	})
+
+	var output strings.Builder
+	last := 0
+	for _, match := range matches {
+		if match[0] < last {
+			continue
+		}
+		output.WriteString(text[last:match[0]])
+		output.WriteString(maskers[match[2]].mask(text[match[0]:match[1]]))
+		last = match[1]
+	}
+	output.WriteString(text[last:])
+	return output.String()
+}
+
+func overlapsAny(span []int, others [][]int) bool {
+	for _, other := range others {
+		if span[0] < other[1] && other[0] < span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// patternMask returns the mask of a value matched by the AnonymizePatterns,
+// registering it so unmaskPatterns restores it. A value gets the same mask
+// for the whole run.
+func (a *Analysis) patternMask(value string) string {
+	if a.patternMasks == nil {
+		a.patternMasks = map[string]string{}
+	}
+	mask, ok := a.patternMasks[value]
+	if !ok {
+		mask = util.MaskString(value)
+		a.patternMasks[value] = mask
+	}
+	return mask
+}
+
+// unmaskPatterns restores the values masked by patternMask in text.
+func (a *Analysis) unmaskPatterns(text string) string {
+	for value, mask := range a.patternMasks {
+		text = strings.ReplaceAll(text, mask, value)
+	}
+	return text
+}
+
+// nameMaskers mask the namespace and name of a result with pseudonyms, e.g.
+// namespace-1 and pod-2.
+func (a *Analysis) nameMaskers(result common.Result) []masker {
+	namespace, name, found := strings.Cut(result.Name, "/")
+	if !found {
+		namespace, name = "", result.Name
+	}
+	var maskers []masker
+	if name != "" {
+		maskers = append(maskers, a.pseudonymMasker(strings.ToLower(result.Kind), name))
+	}
+	if namespace != "" {
+		maskers = append(maskers, a.pseudonymMasker("namespace", namespace))
+	}
+	return maskers
+}
+
+func (a *Analysis) pseudonymMasker(prefix string, value string) masker {
+	return masker{
+		re: regexp.MustCompile(`\b` + regexp.QuoteMeta(value) + `\b`),
+		mask: func(string) string {
+			return a.pseudonym(prefix, value)
+		},
+	}
+}
+
+// pseudonym returns the pseudonym of value, the same for the whole run.
+func (a *Analysis) pseudonym(prefix string, value string) string {
+	if a.pseudonyms == nil {
+		a.pseudonyms = map[string]string{}
+		a.pseudonymCounts = map[string]int{}
+	}
+	pseudonym, ok := a.pseudonyms[value]
+	if !ok {
+		a.pseudonymCounts[prefix]++
+		pseudonym = fmt.Sprintf("%s-%d", prefix, a.pseudonymCounts[prefix])
+		a.pseudonyms[value] = pseudonym
+	}
+	return pseudonym
+}
+
+// unmaskPseudonyms restores the values replaced by pseudonyms in text.
+func (a *Analysis) unmaskPseudonyms(text string) string {
+	for value, pseudonym := range a.pseudonyms {
+		re := regexp.MustCompile(`\b` + regexp.QuoteMeta(pseudonym) + `\b`)
+		text = re.ReplaceAllLiteralString(text, value)
+	}
+	return text
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+// echoAIClient answers with the prompt, to observe what reaches the AI provider.
+type echoAIClient struct {
+	ai.NoOpAIClient
+	mutex   sync.Mutex
+	prompts []string
+}
+
+func (c *echoAIClient) GetCompletion(_ context.Context, prompt string) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.prompts = append(c.prompts, prompt)
+	return prompt, nil
+}
+
+func TestMask_Patterns(t *testing.T) {
+	a := Analysis{AnonymizePatterns: []*regexp.Regexp{
+		regexp.MustCompile(`[a-z0-9-]+\.corp\.example\.com`),
+		regexp.MustCompile(`db-[0-9]+`),
+		regexp.MustCompile(`OPS-[0-9]+`),
+	}}
+
+	text := "pod db-1 can't reach db-1.corp.example.com, see OPS-42 and OPS-42"
+	masked := a.mask(text, nil, nil)
+	require.NotContains(t, masked, "corp.example.com")
+	require.NotContains(t, masked, "OPS-42")
+	// The same value gets the same mask.
+	require.Equal(t, 2, strings.Count(masked, a.patternMasks["OPS-42"]))
+	// The overlapping db-1 of the hostname is masked as part of the hostname only.
+	require.Len(t, a.patternMasks, 3)
+	require.Equal(t, text, a.unmaskPatterns(masked))
+}
+
+func TestMask_SkipsMaskedValues(t *testing.T) {
+	a := Analysis{AnonymizePatterns: []*regexp.Regexp{regexp.MustCompile(`[A-Za-z0-9+/]{8,}==`)}}
+
+	masked := a.mask("service c2VjcmV0Cg== has no endpoints", []string{"c2VjcmV0Cg=="}, nil)
+	require.Equal(t, "service c2VjcmV0Cg== has no endpoints", masked)
+	require.Empty(t, a.patternMasks)
+}
+
+func TestGetAIResults_AnonymizePatterns(t *testing.T) {
+	client := &echoAIClient{}
+	a := Analysis{
+		AIClient:          client,
+		Cache:             newMemoryCache(),
+		Language:          "english",
+		PromptMap:         map[string]string{"default": "%s %s"},
+		AnonymizePatterns: []*regexp.Regexp{regexp.MustCompile(`OPS-[0-9]+`)},
+		Results: []common.Result{{
+			Kind: "Service",
+			Name: "default/payments",
+			Error: []common.Failure{{
+				Text:      "Service payments has no endpoints, tracked in OPS-42",
+				Sensitive: []common.Sensitive{{Unmasked: "payments", Masked: "cGF5bWVudHM="}},
+			}},
+		}},
+	}
+	require.NoError(t, a.GetAIResults("json", true))
+
+	require.Len(t, client.prompts, 1)
+	require.NotContains(t, client.prompts[0], "payments")
+	require.NotContains(t, client.prompts[0], "OPS-42")
+	require.Equal(t, "english Service payments has no endpoints, tracked in OPS-42", a.Results[0].Details)
+}
+
+func TestGetAIResults_AnonymizeNames(t *testing.T) {
+	client := &echoAIClient{}
+	a := Analysis{
+		AIClient:  client,
+		Cache:     newMemoryCache(),
+		Language:  "english",
+		PromptMap: map[string]string{"default": "%s %s"},
+		Results: []common.Result{
+			{Kind: "Pod", Name: "payments/api-7d9f", Error: []common.Failure{{Text: "pod api-7d9f in namespace payments is crashing"}}},
+			{Kind: "Pod", Name: "payments/worker", Error: []common.Failure{{Text: "pod worker in payments is pending"}}},
+		},
+	}
+	require.NoError(t, a.GetAIResults("json", true))
+
+	// The results are explained concurrently, the pseudonyms follow their order.
+	require.ElementsMatch(t, []string{
+		"english pod pod-1 in namespace namespace-1 is crashing",
+		"english pod pod-2 in namespace-1 is pending",
+	}, client.prompts)
+	require.Equal(t, "english pod api-7d9f in namespace payments is crashing", a.Results[0].Details)
+	require.Equal(t, "english pod worker in payments is pending", a.Results[1].Details)
+}
+
+func TestGetAIResults_NoAnonymizeKeepsNames(t *testing.T) {
+	client := &echoAIClient{}
+	a := Analysis{
+		AIClient:  client,
+		Cache:     newMemoryCache(),
+		Language:  "english",
+		PromptMap: map[string]string{"default": "%s %s"},
+		Results:   []common.Result{{Kind: "Pod", Name: "payments/worker", Error: []common.Failure{{Text: "pod worker is pending"}}}},
+	}
+	require.NoError(t, a.GetAIResults("json", false))
+	require.Equal(t, []string{"english pod worker is pending"}, client.prompts)
+	require.Empty(t, a.pseudonyms)
+}
+
+func TestRedact(t *testing.T) {
+	a := Analysis{AnonymizePatterns: []*regexp.Regexp{regexp.MustCompile(`token=[A-Za-z0-9]+`), regexp.MustCompile(`OPS-[0-9]+`)}}
+
+	require.Equal(t, "rotate [REDACTED], see [REDACTED]", a.redact("rotate token=s3cr3t, see OPS-42"))
+	require.Empty(t, a.patternMasks)
+	require.Equal(t, "no secret", (&Analysis{}).redact("no secret"))
+}
+
+// Test: with RedactCache the cached copy of an explanation is redacted, not the explanation of the run
+func TestGetAIResults_RedactCache(t *testing.T) {
+	for _, anonymize := range []bool{false, true} {
+		a := Analysis{
+			AIClient:          &echoAIClient{},
+			Cache:             newMemoryCache(),
+			Language:          "english",
+			PromptMap:         map[string]string{"default": "%s %s"},
+			AnonymizePatterns: []*regexp.Regexp{regexp.MustCompile(`token=[A-Za-z0-9]+`)},
+			RedactCache:       true,
+			Results:           []common.Result{{Kind: "Pod", Name: "default/api", Error: []common.Failure{{Text: "api rejects token=s3cr3t"}}}},
+		}
+		require.NoError(t, a.GetAIResults("json", anonymize))
+
+		require.Equal(t, "english api rejects token=s3cr3t", a.Results[0].Details)
+		texts := a.sanitizedFailureTexts(a.Results[0], anonymize)
+		value, err := a.Cache.Load(a.cacheKey(a.primaryAIBackend(), a.failureInput("Pod", texts)))
+		require.NoError(t, err)
+		cached, err := decodeCacheValue(value)
+		require.NoError(t, err)
+		require.NotContains(t, cached, "s3cr3t")
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// batchMarkerPattern matches the "### <number>" lines delimiting the answers of
+// a batched response.
+var batchMarkerPattern = regexp.MustCompile(`(?m)^[ \t]*###[ \t]*(\d+)[ \t]*$`)
+
+// getBatchedAIResults explains the results using the default prompt in groups
+// of BatchSize per AI request, and returns the explanations by result index.
+// The reused results, explained by a previous analysis, and the results below
+// the ExplainMinSeverity are skipped. Results which are cached, use a custom
+// prompt or belong to a batch whose response couldn't be split are missing
+// from the map and must be explained one by one.
+func (a *Analysis) getBatchedAIResults(anonymize bool, reused map[int]common.Result) map[int]string {
+	batchTmpl, ok := a.PromptMap["batch"]
+	// Streamed explanations are printed per result, so they aren't batched,
+	// and the batch prompt asks for no commands.
+	if a.BatchSize < 2 || !ok || a.AIClient.GetName() == ai.CustomRestClientName || a.onChunk != nil || a.ExplainRemediation || a.CacheOnly {
+		return nil
+	}
+
+	primary := a.primaryAIBackend()
+	var pending []int
+	for index, result := range a.Results {
+		if _, custom := a.PromptMap[result.Kind]; custom {
+			continue
+		}
+		if _, ok := reused[index]; ok || !a.explained(result) {
+			continue
+		}
+		inputKey := a.truncateInput(strings.Join(a.sanitizedFailureTexts(result, anonymize), " "))
+		if !a.Cache.IsCacheDisabled() && a.Cache.Exists(a.cacheKey(primary, inputKey)) {
+			continue
+		}
+		pending = append(pending, index)
+	}
+
+	batched := map[int]string{}
+	for start := 0; start < len(pending); start += a.BatchSize {
+		end := min(start+a.BatchSize, len(pending))
+		a.explainBatch(batchTmpl, pending[start:end], anonymize, batched)
+	}
+	return batched
+}
+
+// explainBatch explains the results at the given indices with a single AI
+// request and adds the answers to batched. Nothing is added on failure.
+func (a *Analysis) explainBatch(batchTmpl string, indices []int, anonymize bool, batched map[int]string) {
+	if len(indices) < 2 {
+		return
+	}
+	log := a.logger()
+
+	inputKeys := make([]string, len(indices))
+	var body strings.Builder
+	for i, index := range indices {
+		inputKeys[i] = a.failureInput(a.Results[index].Kind, a.sanitizedFailureTexts(a.Results[index], anonymize))
+		fmt.Fprintf(&body, "### %d\n%s\n", i+1, inputKeys[i])
+	}
+	log.Debug("Explaining the results in a single AI request", "results", len(indices))
+
+	prompt := fmt.Sprintf(strings.TrimSpace(batchTmpl), a.language(), strings.TrimSpace(body.String()))
+	response, usage, err := a.getCompletionWithRetry(a.AIClient, prompt)
+	if err != nil {
+		log.Debug("Batched AI request failed, explaining the results one by one", "error", err)
+		return
+	}
+	a.recordBatchTokenUsage(indices, usage)
+
+	answers, ok := splitBatchedResponse(response, len(indices))
+	if !ok {
+		log.Debug("Batched AI response couldn't be split, explaining the results one by one")
+		return
+	}
+
+	primary := a.primaryAIBackend()
+	for i, index := range indices {
+		batched[index] = answers[i]
+		if !a.Cache.IsCacheDisabled() {
+			a.recordCacheMiss()
+		}
+		// Cache per result so later runs get partial cache hits.
+		cacheKey := a.cacheKey(primary, inputKeys[i])
+		a.storeExplanation(cacheKey, a.Results[index].Kind, answers[i])
+	}
+}
+
+// splitBatchedResponse splits a batched response into its n answers. It fails
+// unless the response holds exactly the answers 1 to n, in order, none empty.
+func splitBatchedResponse(response string, n int) ([]string, bool) {
+	markers := batchMarkerPattern.FindAllStringSubmatchIndex(response, -1)
+	if len(markers) != n {
+		return nil, false
+	}
+	answers := make([]string, n)
+	for i, marker := range markers {
+		number, err := strconv.Atoi(response[marker[2]:marker[3]])
+		if err != nil || number != i+1 {
+			return nil, false
+		}
+		end := len(response)
+		if i+1 < n {
+			end = markers[i+1][0]
+		}
+		answers[i] = strings.TrimSpace(response[marker[1]:end])
+		if answers[i] == "" {
+			return nil, false
+		}
+	}
+	return answers, true
+}
+
+// recordBatchTokenUsage shares the tokens of a batched completion evenly among
+// the analyzers of the explained results.
+func (a *Analysis) recordBatchTokenUsage(indices []int, usage ai.TokenUsage) {
+	n := len(indices)
+	for i, index := range indices {
+		share := ai.TokenUsage{
+			PromptTokens:     usage.PromptTokens / n,
+			CompletionTokens: usage.CompletionTokens / n,
+			Estimated:        usage.Estimated,
+		}
+		if i == 0 {
+			share.PromptTokens += usage.PromptTokens % n
+			share.CompletionTokens += usage.CompletionTokens % n
+		}
+		a.recordTokenUsage(a.Results[index].Kind, share)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+// batchAIClient answers every numbered message of a batched prompt, or
+// returns malformed when set.
+type batchAIClient struct {
+	ai.NoOpAIClient
+	mutex     sync.Mutex
+	calls     int
+	malformed bool
+}
+
+func (c *batchAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	c.mutex.Lock()
+	c.calls++
+	c.mutex.Unlock()
+	n := len(batchMarkerPattern.FindAllString(prompt, -1))
+	if n == 0 {
+		return c.NoOpAIClient.GetCompletion(ctx, prompt)
+	}
+	if c.malformed {
+		return "Error: something\nSolution: something else", nil
+	}
+	var response strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&response, "### %d\nanswer %d\n\n", i, i)
+	}
+	return response.String(), nil
+}
+
+func newBatchAnalysis(client ai.IAI) *Analysis {
+	return &Analysis{
+		AIClient:  client,
+		Cache:     newMemoryCache(),
+		Language:  "english",
+		BatchSize: 2,
+		PromptMap: map[string]string{"default": "%s %s", "batch": ai.PromptMap["batch"], "Custom": "custom %s %s"},
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/a", Error: []common.Failure{{Text: "failure a"}}},
+			{Kind: "Pod", Name: "default/b", Error: []common.Failure{{Text: "failure b"}}},
+			{Kind: "Custom", Name: "c", Error: []common.Failure{{Text: "failure c"}}},
+			{Kind: "Service", Name: "default/d", Error: []common.Failure{{Text: "failure d"}}},
+		},
+	}
+}
+
+func TestSplitBatchedResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		n        int
+		want     []string
+	}{
+		{"ok", "### 1\nfirst\n### 2\nsecond\nline", 2, []string{"first", "second\nline"}},
+		{"leading text", "Here you go:\n###1\nfirst\n ### 2 \nsecond", 2, []string{"first", "second"}},
+		{"missing answer", "### 1\nfirst", 2, nil},
+		{"out of order", "### 2\nsecond\n### 1\nfirst", 2, nil},
+		{"empty answer", "### 1\n\n### 2\nsecond", 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := splitBatchedResponse(tt.response, tt.n)
+			require.Equal(t, tt.want != nil, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetAIResults_Batched(t *testing.T) {
+	client := &batchAIClient{}
+	a := newBatchAnalysis(client)
+	require.NoError(t, a.GetAIResults("json", false))
+
+	// Pod a and b share a batch, Service d is alone and Custom uses its own prompt.
+	require.Equal(t, 3, client.calls)
+	require.Equal(t, "answer 1", a.Results[0].Details)
+	require.Equal(t, "answer 2", a.Results[1].Details)
+	require.Equal(t, "I am a noop response to the prompt custom english failure c", a.Results[2].Details)
+	require.Equal(t, "I am a noop response to the prompt english failure d", a.Results[3].Details)
+
+	// Batched answers are cached per result.
+	cached, _, _, _, err := a.getAIResultForSanitizedFailures("Pod", []string{"failure b"}, "%s %s")
+	require.NoError(t, err)
+	require.Equal(t, "answer 2", cached)
+	require.Equal(t, 3, client.calls)
+}
+
+func TestGetAIResults_BatchedMalformedResponse(t *testing.T) {
+	client := &batchAIClient{malformed: true}
+	a := newBatchAnalysis(client)
+	require.NoError(t, a.GetAIResults("json", false))
+
+	// The unsplittable batch is explained again one result at a time.
+	require.Equal(t, 5, client.calls)
+	require.Equal(t, "I am a noop response to the prompt english failure a", a.Results[0].Details)
+	require.Equal(t, "I am a noop response to the prompt english failure b", a.Results[1].Details)
+}
+
+// leakingBatchAIClient answers the two messages of a batched prompt with a secret.
+type leakingBatchAIClient struct {
+	ai.NoOpAIClient
+}
+
+func (c *leakingBatchAIClient) GetCompletion(context.Context, string) (string, error) {
+	return "### 1\nrotate token=s3cr3t\n### 2\nrotate token=s3cr3t", nil
+}
+
+// Test: the batched explanations are redacted before they are cached too
+func TestGetAIResults_BatchedRedactCache(t *testing.T) {
+	a := newBatchAnalysis(&leakingBatchAIClient{})
+	a.Results = a.Results[:2]
+	a.AnonymizePatterns = []*regexp.Regexp{regexp.MustCompile(`token=[A-Za-z0-9]+`)}
+	a.RedactCache = true
+	require.NoError(t, a.GetAIResults("json", false))
+
+	for _, result := range a.Results {
+		require.Equal(t, "rotate token=s3cr3t", result.Details)
+		value, err := a.Cache.Load(a.cacheKey(a.primaryAIBackend(), a.failureInput(result.Kind, a.sanitizedFailureTexts(result, false))))
+		require.NoError(t, err)
+		cached, err := decodeCacheValue(value)
+		require.NoError(t, err)
+		require.Equal(t, "rotate "+redactedMask, cached)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+)
+
+// CostEstimate is the estimated cost of explaining the results, before any
+// request is sent to the AI provider.
+type CostEstimate struct {
+	PromptTokens int
+	// Cost is PromptTokens priced at PricePer1K.
+	Cost float64
+}
+
+// EstimateCost sums the estimated prompt tokens of the results to explain. The
+// results reusing the explanations of the Previous analysis or below the
+// ExplainMinSeverity aren't counted, the cached ones are, so the estimate is an
+// upper bound.
+func (a *Analysis) EstimateCost() CostEstimate {
+	reused := a.previousDetails()
+	var estimate CostEstimate
+	for index, result := range a.Results {
+		if _, ok := reused[index]; ok || !a.explained(result) {
+			continue
+		}
+		// The masked texts are about as long as the original ones.
+		inputKey := a.truncateInput(strings.Join(a.sanitizedFailureTexts(result, false), " "))
+		prompt := fmt.Sprintf(strings.TrimSpace(a.promptTemplate(result.Kind)), a.Language, inputKey)
+		estimate.PromptTokens += ai.EstimateTokens(prompt)
+	}
+	estimate.Cost = float64(estimate.PromptTokens) / 1000 * a.PricePer1K
+	return estimate
+}
+
+// withinBudget reports whether the results may be explained. When the
+// estimated cost exceeds the Budget, ConfirmBudget decides, and without it the
+// explanation is skipped and reported in a.Errors.
+func (a *Analysis) withinBudget() bool {
+	estimate := a.EstimateCost()
+	a.logger().Debug("Estimated the cost of the explanations", "promptTokens", estimate.PromptTokens, "cost", estimate.Cost)
+	if a.Budget <= 0 || estimate.Cost <= a.Budget {
+		return true
+	}
+	if a.ConfirmBudget != nil && a.ConfirmBudget(estimate) {
+		return true
+	}
+	a.addError("Budget", PhaseExplanation, fmt.Errorf("estimated cost %.4f exceeds the budget of %.4f, results not explained", estimate.Cost, a.Budget))
+	return false
+}
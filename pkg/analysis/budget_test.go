@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newBudgetAnalysis(budget float64) *Analysis {
+	return &Analysis{
+		Context:    context.Background(),
+		AIClient:   &ai.NoOpAIClient{},
+		Cache:      newMemoryCache(),
+		Language:   "english",
+		PromptMap:  map[string]string{"default": "%s %s"},
+		PricePer1K: 1,
+		Budget:     budget,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: strings.Repeat("a", 3992)}}},
+			{Kind: "Pod", Name: "default/other", Error: []common.Failure{{Text: strings.Repeat("b", 3992)}}},
+		},
+	}
+}
+
+func TestAnalysis_EstimateCost(t *testing.T) {
+	a := newBudgetAnalysis(0)
+	// "english " and the text are 4000 characters, 1000 tokens per result.
+	require.Equal(t, CostEstimate{PromptTokens: 2000, Cost: 2}, a.EstimateCost())
+
+	a.Previous = &JsonOutput{Results: []common.Result{{Kind: "Pod", Name: "default/pod", Error: a.Results[0].Error, Details: "previous explanation"}}}
+	require.Equal(t, CostEstimate{PromptTokens: 1000, Cost: 1}, a.EstimateCost())
+}
+
+func TestGetAIResults_OverBudget(t *testing.T) {
+	a := newBudgetAnalysis(1.5)
+	require.NoError(t, a.GetAIResults("json", false))
+
+	require.Equal(t, []string{"[Budget] estimated cost 2.0000 exceeds the budget of 1.5000, results not explained"}, a.Errors.Strings())
+	require.Empty(t, a.Results[0].Details)
+	require.Empty(t, a.Results[1].Details)
+}
+
+func TestGetAIResults_OverBudgetConfirmed(t *testing.T) {
+	a := newBudgetAnalysis(1.5)
+	var asked CostEstimate
+	a.ConfirmBudget = func(estimate CostEstimate) bool {
+		asked = estimate
+		return true
+	}
+	require.NoError(t, a.GetAIResults("json", false))
+
+	require.Equal(t, 2000, asked.PromptTokens)
+	require.Empty(t, a.Errors)
+	require.NotEmpty(t, a.Results[0].Details)
+}
+
+func TestGetAIResults_WithinBudget(t *testing.T) {
+	a := newBudgetAnalysis(2)
+	a.ConfirmBudget = func(CostEstimate) bool {
+		t.Fatal("the budget isn't exceeded")
+		return false
+	}
+	require.NoError(t, a.GetAIResults("json", false))
+	require.NotEmpty(t, a.Results[0].Details)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// CacheStats counts the outcome of the cache lookups of the AI phase.
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	// Corrupt entries couldn't be decoded and were explained again.
+	Corrupt int64 `json:"corrupt"`
+}
+
+func (s CacheStats) String() string {
+	return fmt.Sprintf("%d hits, %d misses, %d corrupt", s.Hits, s.Misses, s.Corrupt)
+}
+
+// CacheStats returns the cache lookup counts of the AI phase so far.
+func (a *Analysis) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:    atomic.LoadInt64(&a.cacheStats.Hits),
+		Misses:  atomic.LoadInt64(&a.cacheStats.Misses),
+		Corrupt: atomic.LoadInt64(&a.cacheStats.Corrupt),
+	}
+}
+
+func (a *Analysis) recordCacheHit() {
+	atomic.AddInt64(&a.cacheStats.Hits, 1)
+	a.metrics().CacheLookup(true)
+}
+
+func (a *Analysis) recordCacheMiss() {
+	atomic.AddInt64(&a.cacheStats.Misses, 1)
+	a.metrics().CacheLookup(false)
+}
+
+func (a *Analysis) recordCacheCorrupt() {
+	atomic.AddInt64(&a.cacheStats.Corrupt, 1)
+	a.metrics().CacheLookup(false)
+}
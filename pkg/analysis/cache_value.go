@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// compressedCachePrefix starts the cached explanations compressed by
+// CompressCache. It can't clash with base64 data, so the entries stored
+// uncompressed are still read.
+const compressedCachePrefix = "gzip:"
+
+// encodeCacheValue encodes an explanation to cache it, compressed with gzip
+// when CompressCache is set.
+func (a *Analysis) encodeCacheValue(explanation string) string {
+	if !a.CompressCache {
+		return base64.StdEncoding.EncodeToString([]byte(explanation))
+	}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	// Writing to a bytes.Buffer doesn't fail.
+	_, _ = writer.Write([]byte(explanation))
+	_ = writer.Close()
+	return compressedCachePrefix + base64.StdEncoding.EncodeToString(compressed.Bytes())
+}
+
+// storeExplanation caches the explanation of a result of kind under cacheKey,
+// redacted first when RedactCache is set. Failing to store it is only
+// reported.
+func (a *Analysis) storeExplanation(cacheKey string, kind string, explanation string) {
+	if a.RedactCache {
+		redacted := a.redact(explanation)
+		if redacted != explanation {
+			a.logger().Debug("Secrets redacted from the cached explanation", "kind", kind)
+		}
+		explanation = redacted
+	}
+	if err := a.Cache.Store(cacheKey, a.encodeCacheValue(explanation)); err != nil {
+		color.Red("error storing value to cache; value won't be cached: %v", err)
+	}
+}
+
+// decodeCacheValue decodes a cached explanation, compressed or not, whatever
+// CompressCache.
+func decodeCacheValue(value string) (string, error) {
+	encoded, compressed := strings.CutPrefix(value, compressedCachePrefix)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || !compressed {
+		return string(data), err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	explanation, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(explanation), nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// cloudProviderLabels tell the cloud provider of the nodes without a
+// providerID.
+var cloudProviderLabels = map[string]string{
+	"eks.amazonaws.com/nodegroup":   "aws",
+	"cloud.google.com/gke-nodepool": "gce",
+	"kubernetes.azure.com/cluster":  "azure",
+}
+
+// addClusterInfo gathers the metadata of the clusters of the results, so that
+// sanitizedFailureTexts prepends it to their failures: the Kubernetes
+// version, the cloud provider and region, and the OS and architecture of the
+// nodes. The region is left out when anonymizing. The errors fetching them
+// are reported and the metadata found so far is kept.
+func (a *Analysis) addClusterInfo(anonymize bool) {
+	a.clusterInfos = map[string]string{}
+	clients := a.clusterClients()
+	for _, result := range a.Results {
+		if _, ok := a.clusterInfos[result.Cluster]; ok {
+			continue
+		}
+		client := clients[result.Cluster]
+		if client == nil || client.Offline {
+			a.clusterInfos[result.Cluster] = ""
+			continue
+		}
+		a.clusterInfos[result.Cluster] = a.clusterInfo(result.Cluster, client, anonymize)
+	}
+	a.logger().Debug("Results enriched with the metadata of their cluster", "clusters", len(a.clusterInfos))
+}
+
+// clusterInfo returns the compact metadata of the cluster of client, empty
+// when none could be fetched.
+func (a *Analysis) clusterInfo(name string, client *kubernetes.Client, anonymize bool) string {
+	var parts []string
+	label := "the cluster"
+	if name != "" {
+		label = "cluster " + name
+	}
+	serverVersion, err := client.GetClient().Discovery().ServerVersion()
+	if err != nil {
+		a.addError("ClusterInfo", PhaseExplanation, fmt.Errorf("fetching the version of %s: %w", label, err))
+	} else if serverVersion.GitVersion != "" {
+		parts = append(parts, "Kubernetes "+serverVersion.GitVersion)
+	}
+
+	nodes, err := client.GetClient().CoreV1().Nodes().List(a.contextOrBackground(), metav1.ListOptions{})
+	if err != nil {
+		a.addError("ClusterInfo", PhaseExplanation, fmt.Errorf("listing the nodes of %s: %w", label, err))
+	} else if len(nodes.Items) > 0 {
+		provider, region := nodeCloud(nodes.Items)
+		if provider != "" {
+			parts = append(parts, "provider "+provider)
+		}
+		if region != "" && !anonymize {
+			parts = append(parts, "region "+region)
+		}
+		parts = append(parts, "nodes "+nodePlatforms(nodes.Items))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "[Cluster: " + strings.Join(parts, ", ") + "]"
+}
+
+// nodeCloud returns the cloud provider and the region of the first node
+// telling them.
+func nodeCloud(nodes []v1.Node) (string, string) {
+	var provider, region string
+	for _, node := range nodes {
+		if provider == "" {
+			if scheme, _, found := strings.Cut(node.Spec.ProviderID, "://"); found {
+				provider = scheme
+			}
+			for label, labelProvider := range cloudProviderLabels {
+				if _, ok := node.Labels[label]; ok && provider == "" {
+					provider = labelProvider
+				}
+			}
+		}
+		if region == "" {
+			region = node.Labels[v1.LabelTopologyRegion]
+		}
+	}
+	return provider, region
+}
+
+// nodePlatforms counts the nodes by OS and architecture, e.g. "3 linux/amd64,
+// 1 linux/arm64", the most common first.
+func nodePlatforms(nodes []v1.Node) string {
+	counts := map[string]int{}
+	for _, node := range nodes {
+		counts[node.Status.NodeInfo.OperatingSystem+"/"+node.Status.NodeInfo.Architecture]++
+	}
+	platforms := make([]string, 0, len(counts))
+	for platform := range counts {
+		platforms = append(platforms, platform)
+	}
+	sort.Slice(platforms, func(i, j int) bool {
+		if counts[platforms[i]] != counts[platforms[j]] {
+			return counts[platforms[i]] > counts[platforms[j]]
+		}
+		return platforms[i] < platforms[j]
+	})
+	for i, platform := range platforms {
+		platforms[i] = fmt.Sprintf("%d %s", counts[platform], platform)
+	}
+	return strings.Join(platforms, ", ")
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func platformNode(name string, arch string, labels map[string]string, providerID string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       v1.NodeSpec{ProviderID: providerID},
+		Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{OperatingSystem: "linux", Architecture: arch}},
+	}
+}
+
+func clusterInfoClient(objects ...runtime.Object) *kubernetes.Client {
+	clientset := fake.NewSimpleClientset(objects...)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.30.2-eks-1552ad0"}
+	return &kubernetes.Client{Client: clientset}
+}
+
+// Test: the metadata of the cluster is prepended to the failures sent to the AI provider
+func TestAnalysis_IncludeClusterInfo(t *testing.T) {
+	viper.Reset()
+	client := &echoAIClient{}
+	results := []common.Result{{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "ImagePullBackOff"}}}}
+	a := Analysis{
+		Context: context.Background(),
+		Client: clusterInfoClient(
+			platformNode("node-1", "amd64", map[string]string{v1.LabelTopologyRegion: "eu-west-1"}, "aws:///eu-west-1a/i-0123"),
+			platformNode("node-2", "arm64", nil, ""),
+			platformNode("node-3", "amd64", nil, ""),
+		),
+		AIClient:           client,
+		Cache:              newMemoryCache(),
+		PromptMap:          map[string]string{"default": "%s %s"},
+		Explain:            true,
+		IncludeClusterInfo: true,
+		Results:            append([]common.Result(nil), results...),
+	}
+	require.NoError(t, a.explainResults(false, false))
+	require.Empty(t, a.Errors)
+	require.Equal(t, []string{"english [Cluster: Kubernetes v1.30.2-eks-1552ad0, provider aws, region eu-west-1, nodes 2 linux/amd64, 1 linux/arm64] ImagePullBackOff"}, client.prompts)
+
+	// The region is left out when anonymizing.
+	a.Results = append([]common.Result(nil), results...)
+	require.NoError(t, a.explainResults(false, true))
+	require.Len(t, client.prompts, 2)
+	require.Contains(t, client.prompts[1], "[Cluster: Kubernetes v1.30.2-eks-1552ad0, provider aws, nodes 2 linux/amd64, 1 linux/arm64]")
+
+	// The explanations without it aren't served from the cache of those with it.
+	a.IncludeClusterInfo = false
+	a.clusterInfos = nil
+	a.Results = append([]common.Result(nil), results...)
+	require.NoError(t, a.explainResults(false, false))
+	require.Equal(t, "english ImagePullBackOff", client.prompts[2])
+	require.False(t, a.Results[0].Cached)
+}
+
+// Test: the errors fetching the metadata are reported and the metadata found is kept
+func TestAnalysis_AddClusterInfoError(t *testing.T) {
+	client := clusterInfoClient(platformNode("node-1", "amd64", map[string]string{"cloud.google.com/gke-nodepool": "default"}, ""))
+	client.Client.(*fake.Clientset).PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	a := Analysis{
+		Context: context.Background(),
+		Clusters: []Cluster{
+			{Name: "prod", Client: client},
+			{Name: "staging", Client: clusterInfoClient(platformNode("node-1", "amd64", map[string]string{"cloud.google.com/gke-nodepool": "default"}, ""))},
+		},
+		Results: []common.Result{
+			{Kind: "Pod", Cluster: "prod", Name: "default/web", Error: []common.Failure{{Text: "ImagePullBackOff"}}},
+			{Kind: "Pod", Cluster: "staging", Name: "default/web", Error: []common.Failure{{Text: "ImagePullBackOff"}}},
+		},
+	}
+	a.addClusterInfo(false)
+	require.Equal(t, []string{"[ClusterInfo] listing the nodes of cluster prod: forbidden"}, a.Errors.Strings())
+	require.Equal(t, []string{"[Cluster: Kubernetes v1.30.2-eks-1552ad0]", "ImagePullBackOff"}, a.sanitizedFailureTexts(a.Results[0], false))
+	require.Equal(t, []string{"[Cluster: Kubernetes v1.30.2-eks-1552ad0, provider gce, nodes 1 linux/amd64]", "ImagePullBackOff"}, a.sanitizedFailureTexts(a.Results[1], false))
+}
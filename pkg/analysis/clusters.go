@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+)
+
+// Cluster is a kube context analyzed along with others in one run.
+type Cluster struct {
+	// Name is the kube context, it tags the results found in the cluster.
+	Name   string
+	Client *kubernetes.Client
+}
+
+// newClusters initialises a client for each of the kube contexts.
+func newClusters(kubecontexts []string, kubeconfig string) ([]Cluster, error) {
+	clusters := make([]Cluster, 0, len(kubecontexts))
+	for _, kubecontext := range kubecontexts {
+		client, err := kubernetes.NewClientWithOptions(kubecontext, kubeconfig, getKubernetesClientOptions())
+		if err != nil {
+			return nil, fmt.Errorf("initialising kubernetes client of context %s: %w", kubecontext, err)
+		}
+		clusters = append(clusters, Cluster{Name: kubecontext, Client: client})
+	}
+	return clusters, nil
+}
+
+// clusterClients maps the Cluster of the results to the client of their
+// cluster, "" to Client for the results of a single cluster.
+func (a *Analysis) clusterClients() map[string]*kubernetes.Client {
+	clients := map[string]*kubernetes.Client{"": a.Client}
+	for _, cluster := range a.Clusters {
+		clients[cluster.Name] = cluster.Client
+	}
+	return clients
+}
+
+// runClusters runs the analyzers against each of the Clusters at once, at
+// most MaxConcurrency analyzers at a time across all of them, and merges
+// their results, errors and stats tagged with the name of their cluster. The
+// merged results are deduplicated, prioritized and capped together.
+func (a *Analysis) runClusters() {
+	semaphore := make(chan struct{}, a.concurrency())
+	runs := make([]*Analysis, len(a.Clusters))
+	var wg sync.WaitGroup
+	for i, cluster := range a.Clusters {
+		run := *a
+		run.Client = cluster.Client
+		run.Clusters = nil
+		run.semaphore = semaphore
+		run.Results = nil
+		run.Errors = nil
+		run.Stats = nil
+		run.SkippedAnalyzers = nil
+		run.cappedResults = nil
+		run.resultAnalyzers = nil
+		run.truncated = nil
+		run.flagged = nil
+		// The results are capped once merged.
+		run.MaxProblems = 0
+		runs[i] = &run
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run.runAnalysis()
+		}()
+	}
+	wg.Wait()
+
+	for i, run := range runs {
+		name := a.Clusters[i].Name
+		for _, result := range run.Results {
+			result.Cluster = name
+			a.Results = append(a.Results, result)
+		}
+		for _, err := range run.Errors {
+			// The configuration is the same for all the clusters, so are
+			// its errors.
+			if err.Phase == PhaseConfiguration && i > 0 {
+				continue
+			}
+			if err.Phase != PhaseConfiguration {
+				err.Cluster = name
+			}
+			a.Errors = append(a.Errors, err)
+		}
+		a.Stats = append(a.Stats, run.Stats...)
+		for _, skipped := range run.SkippedAnalyzers {
+			a.SkippedAnalyzers = append(a.SkippedAnalyzers, fmt.Sprintf("%s (cluster %s)", skipped, name))
+		}
+		for analyzer, count := range run.truncated {
+			if a.truncated == nil {
+				a.truncated = map[string]int{}
+			}
+			a.truncated[fmt.Sprintf("%s (cluster %s)", analyzer, name)] = count
+		}
+	}
+	// The filters are the same for all the clusters.
+	a.noAnalyzers = runs[0].noAnalyzers
+	a.finishResults()
+	a.logger().Debug("Results found in the clusters", "results", len(a.Results), "clusters", len(a.Clusters))
+}
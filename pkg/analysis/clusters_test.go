@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func pendingPodCluster(name string) Cluster {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Status:     v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}}},
+	}
+	return Cluster{Name: name, Client: &kubernetes.Client{Client: fake.NewSimpleClientset(pod)}}
+}
+
+// Test: the results of each cluster are kept, tagged with it
+func TestAnalysis_RunClusters(t *testing.T) {
+	viper.Reset()
+	a := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Pod", "Bogus"},
+		MaxConcurrency: 2,
+		Clusters:       []Cluster{pendingPodCluster("staging"), pendingPodCluster("prod")},
+	}
+	a.RunAnalysis()
+
+	require.Len(t, a.Results, 2)
+	require.Equal(t, "prod", a.Results[0].Cluster)
+	require.Equal(t, "staging", a.Results[1].Cluster)
+	require.Equal(t, a.Results[0].Name, a.Results[1].Name)
+	// The configuration errors are reported once.
+	require.Equal(t, []string{"\"Bogus\" filter does not exist. Please run k8sgpt filters list."}, a.Errors.Strings())
+}
+
+// Test: MaxProblems caps the merged results
+func TestAnalysis_RunClustersMaxProblems(t *testing.T) {
+	viper.Reset()
+	a := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Pod"},
+		MaxConcurrency: 2,
+		MaxProblems:    1,
+		Clusters:       []Cluster{pendingPodCluster("staging"), pendingPodCluster("prod")},
+	}
+	a.RunAnalysis()
+
+	require.Len(t, a.Results, 1)
+	require.Equal(t, "prod", a.Results[0].Cluster)
+	require.Equal(t, 2, a.totalResults())
+}
+
+// inflightAnalyzer records the most runs of it at once.
+type inflightAnalyzer struct {
+	mutex    sync.Mutex
+	inflight int
+	most     int
+}
+
+func (i *inflightAnalyzer) Analyze(common.Analyzer) ([]common.Result, error) {
+	i.mutex.Lock()
+	i.inflight++
+	i.most = max(i.most, i.inflight)
+	i.mutex.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	i.mutex.Lock()
+	i.inflight--
+	i.mutex.Unlock()
+	return nil, nil
+}
+
+// Test: MaxConcurrency bounds the analyzers run at once across the clusters
+func TestAnalysis_RunClustersConcurrency(t *testing.T) {
+	viper.Reset()
+	inflight := &inflightAnalyzer{}
+	require.NoError(t, analyzer.Register("ClustersTestInflight", inflight))
+	t.Cleanup(func() { analyzer.Unregister("ClustersTestInflight") })
+	a := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"ClustersTestInflight"},
+		MaxConcurrency: 2,
+		Clusters:       []Cluster{pendingPodCluster("a"), pendingPodCluster("b"), pendingPodCluster("c"), pendingPodCluster("d")},
+	}
+	a.RunAnalysis()
+
+	require.Empty(t, a.Errors)
+	require.Equal(t, 2, inflight.most)
+}
+
+func TestAnalyzerError_Cluster(t *testing.T) {
+	err := &AnalyzerError{Analyzer: "Pod", Namespace: "default", Cluster: "prod", Err: context.DeadlineExceeded}
+	require.Equal(t, "[Pod] cluster prod: namespace default: context deadline exceeded", err.Error())
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"slices"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// analyzerReport is what an analyzer of a run reports to the collector.
+type analyzerReport struct {
+	// name is the analyzer, label its run in the skipped and cut short
+	// analyzers, e.g. with its namespace.
+	name  string
+	label string
+	// skipped and cutShort tell the run wasn't started, or didn't complete.
+	skipped  bool
+	cutShort bool
+	results  []common.Result
+	err      *AnalyzerError
+	stat     *common.AnalysisStats
+	// analyzer and config are kept to recheck the results, the custom
+	// analyzers have none.
+	analyzer common.IAnalyzer
+	config   common.Analyzer
+}
+
+// collector gathers the reports of the analyzers of a run in a single
+// goroutine, the only one appending to the Results, Errors and Stats of the
+// analysis until wait returns, so the analyzers don't contend on a mutex.
+type collector struct {
+	reports chan analyzerReport
+	done    chan struct{}
+}
+
+// startCollector starts collecting the reports of a run into a.
+func (a *Analysis) startCollector() *collector {
+	c := &collector{
+		// Buffered so that the analyzers rarely wait for the collector.
+		reports: make(chan analyzerReport, a.concurrency()),
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(c.done)
+		for report := range c.reports {
+			a.collect(report)
+		}
+	}()
+	return c
+}
+
+func (c *collector) report(report analyzerReport) {
+	c.reports <- report
+}
+
+// wait returns once all the reports are collected. Nothing may be reported
+// afterwards.
+func (c *collector) wait() {
+	close(c.reports)
+	<-c.done
+}
+
+func (a *Analysis) collect(report analyzerReport) {
+	switch {
+	case report.skipped:
+		a.SkippedAnalyzers = append(a.SkippedAnalyzers, report.label)
+		return
+	case report.cutShort:
+		a.cutShort = append(a.cutShort, report.label)
+		return
+	}
+	if report.analyzer != nil {
+		a.recordFlagged(report.analyzer, report.name, report.config, report.results)
+	}
+	if report.stat != nil {
+		a.Stats = append(a.Stats, *report.stat)
+	}
+	if report.err != nil {
+		a.Errors = append(a.Errors, report.err)
+	}
+	if a.MaxResultsPerAnalyzer > 0 {
+		if a.resultAnalyzers == nil {
+			a.resultAnalyzers = map[string]string{}
+		}
+		for _, result := range report.results {
+			if _, ok := a.resultAnalyzers[resultKey(result)]; !ok {
+				a.resultAnalyzers[resultKey(result)] = report.name
+			}
+		}
+	}
+	// Including what a partially failed analyzer found.
+	a.Results = append(a.Results, report.results...)
+}
+
+// capAnalyzerResults keeps the MaxResultsPerAnalyzer first results of each
+// analyzer, the most severe ones once prioritized, and counts the others as
+// truncated. The runs of the same analyzer, e.g. in distinct namespaces, share
+// the cap.
+func (a *Analysis) capAnalyzerResults() {
+	if a.MaxResultsPerAnalyzer <= 0 {
+		return
+	}
+	kept := map[string]int{}
+	var capped []string
+	results := a.Results[:0]
+	for _, result := range a.Results {
+		name, ok := a.resultAnalyzers[resultKey(result)]
+		if !ok || kept[name] < a.MaxResultsPerAnalyzer {
+			kept[name]++
+			results = append(results, result)
+			continue
+		}
+		if a.truncated == nil {
+			a.truncated = map[string]int{}
+		}
+		if !slices.Contains(capped, name) {
+			capped = append(capped, name)
+		}
+		a.truncated[name]++
+	}
+	a.Results = results
+	for _, name := range capped {
+		a.logger().Debug("Results capped, --max-results-per-analyzer reached", "analyzer", name, "kept", a.MaxResultsPerAnalyzer, "truncated", a.truncated[name])
+	}
+}
+
+// severityRank ranks the results without a severity as warnings, the default
+// of prioritizeResults.
+func severityRank(result common.Result) int {
+	if result.Severity == "" {
+		return common.SeverityWarning.Rank()
+	}
+	return result.Severity.Rank()
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// Test: the reports of concurrent analyzers are all collected
+func TestCollector_ConcurrentReports(t *testing.T) {
+	a := Analysis{WithStats: true, MaxConcurrency: 4}
+	c := a.startCollector()
+	var wg sync.WaitGroup
+	for i := 0; i < 1000; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("analyzer-%d", i)
+			report := analyzerReport{name: name, stat: &common.AnalysisStats{Analyzer: name}}
+			switch i % 4 {
+			case 0:
+				report = analyzerReport{label: name, skipped: true}
+			case 1:
+				report.err = &AnalyzerError{Analyzer: name, Phase: PhaseAnalysis, Err: errors.New("forbidden")}
+			default:
+				report.results = []common.Result{{Kind: "Pod", Name: "default/" + name}}
+			}
+			c.report(report)
+		}()
+	}
+	wg.Wait()
+	c.wait()
+
+	require.Len(t, a.SkippedAnalyzers, 250)
+	require.Len(t, a.Errors, 250)
+	require.Len(t, a.Results, 500)
+	require.Len(t, a.Stats, 750)
+}
+
+// Test: under high concurrency every analyzer of every namespace is collected
+func TestAnalysis_RunAnalysisHighConcurrency(t *testing.T) {
+	viper.Reset()
+	var objects []runtime.Object
+	var namespaces []string
+	for i := 0; i < 50; i++ {
+		namespace := fmt.Sprintf("team-%d", i)
+		namespaces = append(namespaces, namespace)
+		objects = append(objects, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: namespace},
+			Status: v1.PodStatus{
+				Phase:      v1.PodPending,
+				Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}},
+			},
+		})
+	}
+	a := Analysis{
+		Context:        context.Background(),
+		Client:         &kubernetes.Client{Client: fake.NewSimpleClientset(objects...)},
+		Filters:        []string{"Pod", "Service", "Deployment"},
+		Namespaces:     namespaces,
+		MaxConcurrency: 100,
+		WithStats:      true,
+	}
+	a.RunAnalysis()
+
+	require.Empty(t, a.Errors)
+	require.Len(t, a.Results, 50)
+	require.Len(t, a.Stats, 150)
+}
+
+// Test: MaxResultsPerAnalyzer keeps the most severe results of each analyzer across its runs
+func TestCollector_MaxResultsPerAnalyzer(t *testing.T) {
+	viper.Reset()
+	a := Analysis{MaxResultsPerAnalyzer: 2}
+	c := a.startCollector()
+	c.report(analyzerReport{name: "Pod", results: []common.Result{
+		{Kind: "Pod", Name: "default/info", Severity: common.SeverityInfo},
+		{Kind: "Pod", Name: "default/warning"},
+		{Kind: "Pod", Name: "default/critical", Severity: common.SeverityCritical},
+	}})
+	c.report(analyzerReport{name: "Pod", results: []common.Result{{Kind: "Pod", Name: "team/critical", Severity: common.SeverityCritical}}})
+	c.report(analyzerReport{name: "Service", results: []common.Result{{Kind: "Service", Name: "default/service"}}})
+	c.wait()
+	a.finishResults()
+
+	require.Equal(t, []common.Result{
+		{Kind: "Pod", Name: "default/critical", Severity: common.SeverityCritical},
+		{Kind: "Pod", Name: "team/critical", Severity: common.SeverityCritical},
+		{Kind: "Service", Name: "default/service", Severity: common.SeverityWarning},
+	}, a.Results)
+	require.Equal(t, map[string]int{"Pod": 2}, a.getJsonOutput().TruncatedResults)
+	output, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "Pod: (and 2 more, --max-results-per-analyzer)")
+
+	// The next run of a watch starts with empty caps.
+	a.NextRun()
+	require.Nil(t, a.getJsonOutput().TruncatedResults)
+}
+
+// Test: the results below MinSeverity don't count against MaxResultsPerAnalyzer
+func TestCollector_MaxResultsPerAnalyzerMinSeverity(t *testing.T) {
+	viper.Reset()
+	a := Analysis{MaxResultsPerAnalyzer: 1, MinSeverity: common.SeverityWarning}
+	c := a.startCollector()
+	c.report(analyzerReport{name: "Pod", results: []common.Result{
+		{Kind: "Pod", Name: "default/info", Severity: common.SeverityInfo},
+		{Kind: "Pod", Name: "default/warning"},
+	}})
+	c.wait()
+	a.finishResults()
+
+	require.Equal(t, []common.Result{{Kind: "Pod", Name: "default/warning", Severity: common.SeverityWarning}}, a.Results)
+	require.Nil(t, a.getJsonOutput().TruncatedResults)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"strconv"
+
+	"github.com/fatih/color"
+)
+
+// confidenceRequested reports whether the AI provider is asked for its
+// confidence in the explanations.
+func (a *Analysis) confidenceRequested() bool {
+	return a.Confidence || a.MinConfidence > 0
+}
+
+// belowMinConfidence reports whether an explanation of the given confidence is
+// dropped. Explanations without a confidence never are.
+func (a *Analysis) belowMinConfidence(confidence *float64) bool {
+	return confidence != nil && *confidence < a.MinConfidence
+}
+
+// confidenceCacheKey is the key of the confidence cached next to the
+// explanation of cacheKey, so that cached explanations are thresholded alike.
+func confidenceCacheKey(cacheKey string) string {
+	return cacheKey + "-confidence"
+}
+
+func (a *Analysis) storeConfidence(cacheKey string, confidence *float64) {
+	if confidence == nil || a.Cache.IsCacheDisabled() {
+		return
+	}
+	if err := a.Cache.Store(confidenceCacheKey(cacheKey), strconv.FormatFloat(*confidence, 'g', -1, 64)); err != nil {
+		color.Red("error storing value to cache; value won't be cached: %v", err)
+	}
+}
+
+// loadConfidence returns the cached confidence of the explanation of cacheKey,
+// nil when not requested or none was cached.
+func (a *Analysis) loadConfidence(cacheKey string) *float64 {
+	key := confidenceCacheKey(cacheKey)
+	if !a.confidenceRequested() || !a.Cache.Exists(key) {
+		return nil
+	}
+	value, err := a.Cache.Load(key)
+	if err != nil {
+		return nil
+	}
+	confidence, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &confidence
+}
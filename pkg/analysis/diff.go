@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// ResultDiff compares the results of the analysis with the ones of a previous
+// analysis. Results are matched by resultKey.
+type ResultDiff struct {
+	New       []common.Result `json:"new"`
+	Resolved  []common.Result `json:"resolved"`
+	Unchanged []common.Result `json:"unchanged"`
+}
+
+// LoadPreviousOutput reads the JSON output of a previous analysis, e.g. of
+// the last scheduled run.
+func LoadPreviousOutput(path string) (*JsonOutput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading previous analysis: %w", err)
+	}
+	var previous JsonOutput
+	if err := json.Unmarshal(data, &previous); err != nil {
+		return nil, fmt.Errorf("decoding previous analysis %s: %w", path, err)
+	}
+	return &previous, nil
+}
+
+func (a *Analysis) previousResults() map[string]common.Result {
+	previous := map[string]common.Result{}
+	if a.Previous == nil {
+		return previous
+	}
+	for _, result := range a.Previous.Results {
+		previous[resultKey(result)] = result
+	}
+	return previous
+}
+
+// previousDetails returns the explanations of the previous analysis for the
+// unchanged results, by index, so only the new problems are explained.
+func (a *Analysis) previousDetails() map[int]common.Result {
+	if a.Previous == nil {
+		return nil
+	}
+	previous := a.previousResults()
+	reused := map[int]common.Result{}
+	for index, result := range a.Results {
+		if prior, ok := previous[resultKey(result)]; ok && prior.Details != "" {
+			reused[index] = prior
+		}
+	}
+	return reused
+}
+
+// Diff compares the results with the ones of the Previous analysis, nil when
+// there's none.
+func (a *Analysis) Diff() *ResultDiff {
+	if a.Previous == nil {
+		return nil
+	}
+	previous := a.previousResults()
+	diff := &ResultDiff{
+		New:       []common.Result{},
+		Resolved:  []common.Result{},
+		Unchanged: []common.Result{},
+	}
+	current := map[string]bool{}
+	for _, result := range a.Results {
+		key := resultKey(result)
+		current[key] = true
+		if _, ok := previous[key]; ok {
+			diff.Unchanged = append(diff.Unchanged, result)
+		} else {
+			diff.New = append(diff.New, result)
+		}
+	}
+	for _, result := range a.Previous.Results {
+		if !current[resultKey(result)] {
+			diff.Resolved = append(diff.Resolved, result)
+		}
+	}
+	return diff
+}
+
+func writeTextDiff(output *strings.Builder, diff *ResultDiff) {
+	output.WriteString("\n")
+	output.WriteString(fmt.Sprintf("Since the previous analysis: %s, %s, %d unchanged\n",
+		color.RedString("%d new", len(diff.New)),
+		color.GreenString("%d resolved", len(diff.Resolved)),
+		len(diff.Unchanged)))
+	for _, result := range diff.New {
+		output.WriteString(fmt.Sprintf("%s %s %s\n", color.RedString("+"), color.HiYellowString(result.Kind), color.YellowString(result.Name)))
+	}
+	for _, result := range diff.Resolved {
+		output.WriteString(fmt.Sprintf("%s %s %s\n", color.GreenString("-"), color.HiYellowString(result.Kind), color.YellowString(result.Name)))
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func writePreviousOutput(t *testing.T, output JsonOutput) string {
+	data, err := json.Marshal(output)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "previous.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestAnalysis_Diff(t *testing.T) {
+	path := writePreviousOutput(t, JsonOutput{Results: []common.Result{
+		{Kind: "Pod", Name: "default/unchanged", Error: []common.Failure{{Text: "crash loop"}}, Details: "previous explanation", Provider: "openai"},
+		{Kind: "Pod", Name: "default/resolved", Error: []common.Failure{{Text: "image pull"}}, Details: "resolved explanation"},
+		{Kind: "Pod", Name: "default/changed", Error: []common.Failure{{Text: "pending"}}, Details: "changed explanation"},
+	}})
+	previous, err := LoadPreviousOutput(path)
+	require.NoError(t, err)
+
+	client := &echoAIClient{}
+	a := Analysis{
+		AIClient:  client,
+		Cache:     newMemoryCache(),
+		Language:  "english",
+		PromptMap: map[string]string{"default": "%s %s"},
+		Explain:   true,
+		Previous:  previous,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/unchanged", Error: []common.Failure{{Text: "crash loop"}}},
+			{Kind: "Pod", Name: "default/changed", Error: []common.Failure{{Text: "oom killed"}}},
+			{Kind: "Service", Name: "default/new", Error: []common.Failure{{Text: "no endpoints"}}},
+		},
+	}
+	require.NoError(t, a.GetAIResults("json", false))
+
+	// Only the new problems are explained.
+	require.ElementsMatch(t, []string{"english oom killed", "english no endpoints"}, client.prompts)
+	require.Equal(t, "previous explanation", a.Results[0].Details)
+	require.Equal(t, "openai", a.Results[0].Provider)
+	require.Equal(t, "english oom killed", a.Results[1].Details)
+
+	diff := a.Diff()
+	require.Len(t, diff.Unchanged, 1)
+	require.Equal(t, "default/unchanged", diff.Unchanged[0].Name)
+	require.Len(t, diff.New, 2)
+	require.Equal(t, "default/changed", diff.New[0].Name)
+	require.Equal(t, "default/new", diff.New[1].Name)
+	require.Len(t, diff.Resolved, 2)
+	require.Equal(t, "default/resolved", diff.Resolved[0].Name)
+	require.Equal(t, "default/changed", diff.Resolved[1].Name)
+
+	output, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	got := JsonOutput{}
+	require.NoError(t, json.Unmarshal(output, &got))
+	require.Equal(t, diff, got.Diff)
+
+	a.Explain = false
+	text, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(text), `Since the previous analysis: 2 new, 2 resolved, 1 unchanged
++ Pod default/changed
++ Service default/new
+- Pod default/resolved
+- Pod default/changed
+`)
+}
+
+func TestAnalysis_DiffWithoutPrevious(t *testing.T) {
+	a := Analysis{Results: []common.Result{{Kind: "Pod", Name: "default/pod"}}}
+	require.Nil(t, a.Diff())
+
+	output, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	require.NotContains(t, string(output), "diff")
+}
+
+func TestLoadPreviousOutput_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "previous.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	_, err := LoadPreviousOutput(path)
+	require.ErrorContains(t, err, "decoding previous analysis")
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/custom"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DryRunAnalyzer is an analyzer which the analysis would run.
+type DryRunAnalyzer struct {
+	Name   string `json:"name"`
+	Custom bool   `json:"custom,omitempty"`
+	// Objects is the number of objects the analyzer would scan, -1 when they
+	// can't be counted in advance.
+	Objects int `json:"objects"`
+}
+
+// objectCounter counts the objects an analyzer scans.
+type objectCounter func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error)
+
+// objectCounters count the objects listed by the analyzers, the analyzers which
+// aren't listed here can't be counted in advance.
+var objectCounters = map[string]objectCounter{
+	"Pod": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.CoreV1().Pods(namespace).List(ctx, opts))
+	},
+	"Log": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.CoreV1().Pods(namespace).List(ctx, opts))
+	},
+	"Deployment": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.AppsV1().Deployments(namespace).List(ctx, opts))
+	},
+	"ReplicaSet": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.AppsV1().ReplicaSets(namespace).List(ctx, opts))
+	},
+	"StatefulSet": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.AppsV1().StatefulSets(namespace).List(ctx, opts))
+	},
+	"PersistentVolumeClaim": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, opts))
+	},
+	// The Service analyzer checks the endpoints of the services.
+	"Service": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.CoreV1().Endpoints(namespace).List(ctx, opts))
+	},
+	"Ingress": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.NetworkingV1().Ingresses(namespace).List(ctx, opts))
+	},
+	"Job": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.BatchV1().Jobs(namespace).List(ctx, opts))
+	},
+	"CronJob": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.BatchV1().CronJobs(namespace).List(ctx, opts))
+	},
+	"ConfigMap": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.CoreV1().ConfigMaps(namespace).List(ctx, opts))
+	},
+	"Node": func(ctx context.Context, client kubernetes.Interface, _ string, opts metav1.ListOptions) (int, error) {
+		return countList(client.CoreV1().Nodes().List(ctx, opts))
+	},
+	"ValidatingWebhookConfiguration": func(ctx context.Context, client kubernetes.Interface, _ string, opts metav1.ListOptions) (int, error) {
+		return countList(client.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, opts))
+	},
+	"MutatingWebhookConfiguration": func(ctx context.Context, client kubernetes.Interface, _ string, opts metav1.ListOptions) (int, error) {
+		return countList(client.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, opts))
+	},
+	"HorizontalPodAutoscaler": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, opts))
+	},
+	"PodDisruptionBudget": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, opts))
+	},
+	"NetworkPolicy": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		return countList(client.NetworkingV1().NetworkPolicies(namespace).List(ctx, opts))
+	},
+	// The ContainerResources analyzer checks the deployments and the pods.
+	"ContainerResources": func(ctx context.Context, client kubernetes.Interface, namespace string, opts metav1.ListOptions) (int, error) {
+		deployments, err := countList(client.AppsV1().Deployments(namespace).List(ctx, opts))
+		if err != nil {
+			return 0, err
+		}
+		pods, err := countList(client.CoreV1().Pods(namespace).List(ctx, opts))
+		return deployments + pods, err
+	},
+}
+
+func countList(list runtime.Object, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	return meta.LenList(list), nil
+}
+
+// DryRun resolves the analyzers RunAnalysis would run, along with the custom
+// analyzers when CustomAnalysis is set, and counts the objects each of them
+// would scan in the analyzed namespaces. Nothing is analyzed and the AI provider isn't called.
+func (a *Analysis) DryRun() []DryRunAnalyzer {
+	names := a.resolveAnalyzers()
+	sort.Strings(names)
+
+	ctx := a.contextOrBackground()
+	opts := metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector}
+	var plan []DryRunAnalyzer
+	for _, name := range names {
+		analyzer := DryRunAnalyzer{Name: name, Objects: -1}
+		if counter, ok := objectCounters[name]; ok {
+			namespaces := []string{a.Namespace}
+			if len(a.Namespaces) > 0 && !clusterScopedAnalyzers[name] {
+				namespaces = a.Namespaces
+			}
+			total := 0
+			for _, namespace := range namespaces {
+				count, err := counter(ctx, a.Client.Client, namespace, opts)
+				if err != nil {
+					a.addError(name, PhaseAnalysis, err)
+					total = -1
+					break
+				}
+				total += count
+			}
+			analyzer.Objects = total
+		}
+		plan = append(plan, analyzer)
+	}
+
+	if a.CustomAnalysis {
+		var customAnalyzers []custom.CustomAnalyzer
+		if err := viper.UnmarshalKey("custom_analyzers", &customAnalyzers); err != nil {
+			a.addError("CustomAnalyzers", PhaseConfiguration, err)
+		}
+		for _, cAnalyzer := range a.selectCustomAnalyzers(a.validCustomAnalyzers(customAnalyzers)) {
+			plan = append(plan, DryRunAnalyzer{Name: cAnalyzer.Name, Custom: true, Objects: -1})
+		}
+	}
+	return plan
+}
+
+// PrintDryRun formats the analyzers returned by DryRun.
+func (a *Analysis) PrintDryRun(plan []DryRunAnalyzer) []byte {
+	var output strings.Builder
+
+	if len(plan) == 0 {
+		output.WriteString(color.YellowString("No analyzers would run\n"))
+	} else {
+		output.WriteString(color.YellowString("Analyzers which would run: \n"))
+	}
+	for _, analyzer := range plan {
+		switch {
+		case analyzer.Custom:
+			output.WriteString(fmt.Sprintf("- %s (custom analyzer)\n", color.YellowString(analyzer.Name)))
+		case analyzer.Objects < 0:
+			output.WriteString(fmt.Sprintf("- %s: objects can't be counted in advance\n", color.YellowString(analyzer.Name)))
+		default:
+			output.WriteString(fmt.Sprintf("- %s: %d objects\n", color.YellowString(analyzer.Name), analyzer.Objects))
+		}
+	}
+
+	if len(a.Errors) != 0 {
+		output.WriteString("\n")
+		output.WriteString(color.YellowString("Warnings : \n"))
+		for _, aerror := range a.Errors {
+			output.WriteString(fmt.Sprintf("- %s\n", color.YellowString(aerror.Error())))
+		}
+	}
+	return []byte(output.String())
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAnalysis_DryRun(t *testing.T) {
+	viper.Reset()
+	viper.Set("custom_analyzers", []map[string]interface{}{
+		{"name": "my-analyzer", "connection": map[string]interface{}{"url": "localhost", "port": 8085}},
+	})
+	clientset := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "default"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: "default"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"}},
+	)
+	a := Analysis{
+		Context:        context.Background(),
+		Namespace:      "default",
+		Filters:        []string{"Pod", "Gateway", "Service", "Unknown", "my-analyzer"},
+		ExcludeFilters: []string{"Service"},
+		CustomAnalysis: true,
+		Client:         &kubernetes.Client{Client: clientset},
+	}
+
+	plan := a.DryRun()
+	require.Equal(t, []DryRunAnalyzer{
+		{Name: "Gateway", Objects: -1},
+		{Name: "Pod", Objects: 2},
+		{Name: "my-analyzer", Custom: true, Objects: -1},
+	}, plan)
+	require.Equal(t, []string{"\"Unknown\" filter does not exist. Please run k8sgpt filters list."}, a.Errors.Strings())
+	require.Empty(t, a.Results)
+
+	output := string(a.PrintDryRun(plan))
+	require.Contains(t, output, "Pod: 2 objects")
+	require.Contains(t, output, "Gateway: objects can't be counted in advance")
+	require.Contains(t, output, "my-analyzer (custom analyzer)")
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const (
+	// maxResultEvents and maxEventAge bound the events added to a result,
+	// the Since window replaces maxEventAge when set.
+	maxResultEvents = 5
+	maxEventAge     = time.Hour
+	// eventsHeader introduces the events appended to the last failure text
+	// of a result. They aren't part of the resultKey, so the results are
+	// compared with the previous ones without them.
+	eventsHeader = "\nRecent events:"
+)
+
+// addEvents appends the recent events of the objects of the results to their
+// last failure text, so that the AI provider explains them with more
+// context. The events are listed in the cluster of each result. The event
+// messages are masked when anonymizing. The first error fetching them in a
+// cluster is reported and its remaining results are left as is.
+func (a *Analysis) addEvents() {
+	clients := a.clusterClients()
+	failed := map[string]bool{}
+	cutoff := time.Now().Add(-maxEventAge)
+	if a.Since > 0 {
+		cutoff = time.Now().Add(-a.Since)
+	}
+	ctx := a.contextOrBackground()
+	for index, result := range a.Results {
+		if len(result.Error) == 0 || strings.Contains(result.Error[len(result.Error)-1].Text, eventsHeader) {
+			continue
+		}
+		client := clients[result.Cluster]
+		if client == nil || client.Offline || failed[result.Cluster] {
+			continue
+		}
+		// e.g. Security/Pod is about a Pod.
+		kind := result.Kind[strings.LastIndex(result.Kind, "/")+1:]
+		namespace, name, found := strings.Cut(result.Name, "/")
+		if !found {
+			namespace, name = "", result.Name
+		}
+		selector := fields.Set{"involvedObject.kind": kind, "involvedObject.name": name}.String()
+		list, err := client.GetClient().CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+		if err != nil {
+			a.Errors = append(a.Errors, &AnalyzerError{
+				Analyzer: "Events",
+				Phase:    PhaseExplanation,
+				Err:      fmt.Errorf("fetching the events of %s %s, results not enriched with events: %w", result.Kind, result.Name, err),
+				Cluster:  result.Cluster,
+			})
+			failed[result.Cluster] = true
+			continue
+		}
+		events := recentEvents(list.Items, kind, name, cutoff)
+		if len(events) == 0 {
+			continue
+		}
+		failure := &a.Results[index].Error[len(result.Error)-1]
+		var text strings.Builder
+		text.WriteString(failure.Text + eventsHeader)
+		for _, event := range events {
+			text.WriteString(fmt.Sprintf("\n- %s %s: %s", event.Type, event.Reason, event.Message))
+			failure.Sensitive = append(failure.Sensitive, common.Sensitive{Unmasked: event.Message, Masked: util.MaskString(event.Message)})
+		}
+		failure.Text = text.String()
+	}
+	a.logger().Debug("Results enriched with their recent events")
+}
+
+// recentEvents returns the latest maxResultEvents events about the object
+// since cutoff, oldest first and without the consecutive repeats.
+func recentEvents(events []v1.Event, kind string, name string, cutoff time.Time) []v1.Event {
+	var recent []v1.Event
+	for _, event := range events {
+		// The fake clients ignore the field selectors.
+		if event.InvolvedObject.Kind != kind || event.InvolvedObject.Name != name || eventTime(event).Before(cutoff) {
+			continue
+		}
+		recent = append(recent, event)
+	}
+	slices.SortStableFunc(recent, func(first, second v1.Event) int {
+		return eventTime(first).Compare(eventTime(second))
+	})
+	recent = slices.CompactFunc(recent, func(first, second v1.Event) bool {
+		return first.Reason == second.Reason && first.Message == second.Message
+	})
+	return recent[max(0, len(recent)-maxResultEvents):]
+}
+
+// eventTime is the last time the event occurred.
+func eventTime(event v1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func podEvent(name string, object string, reason string, message string, age time.Duration) *v1.Event {
+	return &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: object, Namespace: "default"},
+		Type:           v1.EventTypeWarning,
+		Reason:         reason,
+		Message:        message,
+		LastTimestamp:  metav1.NewTime(time.Now().Add(-age)),
+	}
+}
+
+// Test: the recent events of the objects are appended to their last failure
+func TestAnalysis_AddEvents(t *testing.T) {
+	objects := []runtime.Object{
+		podEvent("pulled", "web", "Failed", "Failed to pull image nginx:latst", 2*time.Minute),
+		podEvent("backoff", "web", "BackOff", "Back-off pulling image nginx:latst", time.Minute),
+		podEvent("again", "web", "BackOff", "Back-off pulling image nginx:latst", 30*time.Second),
+		podEvent("old", "web", "Scheduled", "Successfully assigned default/web", 2*time.Hour),
+		podEvent("other", "api", "BackOff", "Back-off restarting failed container", time.Minute),
+	}
+	for i := 0; i < maxResultEvents+2; i++ {
+		objects = append(objects, podEvent(fmt.Sprintf("probe-%d", i), "db", "Unhealthy", fmt.Sprintf("Readiness probe failed %d", i), time.Duration(10-i)*time.Minute))
+	}
+	a := Analysis{
+		Context: context.Background(),
+		Client:  &kubernetes.Client{Client: fake.NewSimpleClientset(objects...)},
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "ImagePullBackOff"}}},
+			{Kind: "Security/Pod", Name: "default/db", Error: []common.Failure{{Text: "no security context"}}},
+			{Kind: "Node", Name: "node-1", Error: []common.Failure{{Text: "NotReady"}}},
+		},
+	}
+	key := resultKey(a.Results[0])
+	a.addEvents()
+	require.Empty(t, a.Errors)
+
+	require.Equal(t, "ImagePullBackOff\nRecent events:\n- Warning Failed: Failed to pull image nginx:latst\n- Warning BackOff: Back-off pulling image nginx:latst", a.Results[0].Error[0].Text)
+	require.Len(t, a.Results[0].Error[0].Sensitive, 2)
+	require.Equal(t, "Failed to pull image nginx:latst", a.Results[0].Error[0].Sensitive[0].Unmasked)
+	require.NotEmpty(t, a.Results[0].Error[0].Sensitive[0].Masked)
+	require.Equal(t, key, resultKey(a.Results[0]))
+
+	require.Equal(t, "no security context\nRecent events:\n"+
+		"- Warning Unhealthy: Readiness probe failed 2\n- Warning Unhealthy: Readiness probe failed 3\n"+
+		"- Warning Unhealthy: Readiness probe failed 4\n- Warning Unhealthy: Readiness probe failed 5\n"+
+		"- Warning Unhealthy: Readiness probe failed 6", a.Results[1].Error[0].Text)
+	require.Equal(t, "NotReady", a.Results[2].Error[0].Text)
+
+	// The events are only appended once.
+	a.addEvents()
+	require.Len(t, a.Results[0].Error[0].Sensitive, 2)
+
+	// The Since window replaces the default age.
+	a.Results[0].Error[0] = common.Failure{Text: "ImagePullBackOff"}
+	a.Since = 90 * time.Second
+	a.addEvents()
+	require.Equal(t, "ImagePullBackOff\nRecent events:\n- Warning BackOff: Back-off pulling image nginx:latst", a.Results[0].Error[0].Text)
+}
+
+// Test: the first error listing the events is reported
+func TestAnalysis_AddEventsError(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "events", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	a := Analysis{
+		Context: context.Background(),
+		Client:  &kubernetes.Client{Client: clientset},
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "ImagePullBackOff"}}},
+			{Kind: "Pod", Name: "default/api", Error: []common.Failure{{Text: "CrashLoopBackOff"}}},
+		},
+	}
+	a.addEvents()
+	require.Equal(t, []string{"[Events] fetching the events of Pod default/web, results not enriched with events: forbidden"}, a.Errors.Strings())
+	require.Equal(t, "ImagePullBackOff", a.Results[0].Error[0].Text)
+}
+
+// Test: the events are listed in the cluster of each result, and an error in
+// one cluster leaves the others enriched
+func TestAnalysis_AddEventsClusters(t *testing.T) {
+	failing := fake.NewSimpleClientset()
+	failing.PrependReactor("list", "events", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	a := Analysis{
+		Context: context.Background(),
+		Clusters: []Cluster{
+			{Name: "staging", Client: &kubernetes.Client{Client: fake.NewSimpleClientset(podEvent("backoff", "web", "BackOff", "Back-off pulling image nginx:staging", time.Minute))}},
+			{Name: "prod", Client: &kubernetes.Client{Client: fake.NewSimpleClientset(podEvent("backoff", "web", "BackOff", "Back-off pulling image nginx:prod", time.Minute))}},
+			{Name: "dev", Client: &kubernetes.Client{Client: failing}},
+		},
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/web", Cluster: "prod", Error: []common.Failure{{Text: "ImagePullBackOff"}}},
+			{Kind: "Pod", Name: "default/web", Cluster: "staging", Error: []common.Failure{{Text: "ImagePullBackOff"}}},
+			{Kind: "Pod", Name: "default/web", Cluster: "dev", Error: []common.Failure{{Text: "ImagePullBackOff"}}},
+		},
+	}
+	a.addEvents()
+
+	require.Equal(t, "ImagePullBackOff\nRecent events:\n- Warning BackOff: Back-off pulling image nginx:prod", a.Results[0].Error[0].Text)
+	require.Equal(t, "ImagePullBackOff\nRecent events:\n- Warning BackOff: Back-off pulling image nginx:staging", a.Results[1].Error[0].Text)
+	require.Equal(t, "ImagePullBackOff", a.Results[2].Error[0].Text)
+	require.Equal(t, []string{"[Events] cluster dev: fetching the events of Pod default/web, results not enriched with events: forbidden"}, a.Errors.Strings())
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// followUpKind is the kind the tokens used by the follow-up questions are
+// attributed to.
+const followUpKind = "FollowUp"
+
+// FollowUpTurn is a question asked about the results and its answer.
+type FollowUpTurn struct {
+	Question string
+	Answer   string
+}
+
+// FollowUp is a conversation about the results of an analysis. The findings,
+// or the focused one, and the previous turns are sent along with each question.
+type FollowUp struct {
+	analysis *Analysis
+	focus    *common.Result
+	History  []FollowUpTurn
+}
+
+// NewFollowUp starts a conversation about the results of the analysis.
+func (a *Analysis) NewFollowUp() *FollowUp {
+	return &FollowUp{analysis: a}
+}
+
+// Focus restricts the following questions to the result at index, as numbered
+// in the text output. A negative index brings all the results back.
+func (f *FollowUp) Focus(index int) error {
+	if index < 0 {
+		f.focus = nil
+		return nil
+	}
+	if index >= len(f.analysis.Results) {
+		return fmt.Errorf("no result %d, the analysis has %d results", index, len(f.analysis.Results))
+	}
+	f.focus = &f.analysis.Results[index]
+	return nil
+}
+
+// Focused returns the result the questions are about, nil for all of them.
+func (f *FollowUp) Focused() *common.Result {
+	return f.focus
+}
+
+// Ask answers a question with the AI provider of the analysis and records the
+// turn in the History. The answers are cached like the explanations.
+func (f *FollowUp) Ask(question string) (string, error) {
+	a := f.analysis
+	if a.AIClient == nil {
+		return "", errors.New("no AI provider configured")
+	}
+	inputKey := a.truncateInput(f.input(question))
+	answer, _, _, err := a.getAIResultFromBackend(a.primaryAIBackend(), followUpKind, inputKey, ai.PromptMap["followup"])
+	if errors.Is(err, errCacheMiss) {
+		return "", errors.New("no cached answer to this question")
+	}
+	if err != nil {
+		return "", err
+	}
+	f.History = append(f.History, FollowUpTurn{Question: question, Answer: answer})
+	return answer, nil
+}
+
+// input renders the findings, the previous turns and the question.
+func (f *FollowUp) input(question string) string {
+	var input strings.Builder
+	input.WriteString("Findings:\n")
+	if f.focus != nil {
+		writeFollowUpResult(&input, *f.focus)
+	} else {
+		for _, result := range f.analysis.Results {
+			writeFollowUpResult(&input, result)
+		}
+	}
+	if len(f.History) > 0 {
+		input.WriteString("Conversation:\n")
+		for _, turn := range f.History {
+			fmt.Fprintf(&input, "Question: %s\nAnswer: %s\n", turn.Question, turn.Answer)
+		}
+	}
+	fmt.Fprintf(&input, "Question: %s", question)
+	return input.String()
+}
+
+func writeFollowUpResult(input *strings.Builder, result common.Result) {
+	fmt.Fprintf(input, "- %s %s\n", result.Kind, result.Name)
+	for _, failure := range result.Error {
+		fmt.Fprintf(input, "  Error: %s\n", failure.Text)
+	}
+	if result.Details != "" {
+		fmt.Fprintf(input, "  Explanation: %s\n", strings.TrimSpace(result.Details))
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newFollowUpAnalysis(client *echoAIClient) *Analysis {
+	return &Analysis{
+		Context:  context.Background(),
+		AIClient: client,
+		Cache:    newMemoryCache(),
+		Language: "english",
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/crash", Error: []common.Failure{{Text: "crash loop"}}, Details: "Error: the container exits"},
+			{Kind: "Service", Name: "default/web", Error: []common.Failure{{Text: "no endpoints"}}},
+		},
+	}
+}
+
+func TestFollowUp_Ask(t *testing.T) {
+	client := &echoAIClient{}
+	followUp := newFollowUpAnalysis(client).NewFollowUp()
+
+	answer, err := followUp.Ask("why does it crash?")
+	require.NoError(t, err)
+	require.Contains(t, answer, "in --- english --- language")
+	require.Contains(t, answer, "- Pod default/crash\n  Error: crash loop\n  Explanation: Error: the container exits\n")
+	require.Contains(t, answer, "- Service default/web\n  Error: no endpoints\n")
+	require.Contains(t, answer, "Question: why does it crash?")
+	require.NotContains(t, answer, "Conversation:")
+
+	// The following questions carry the previous turns.
+	require.NoError(t, followUp.Focus(1))
+	require.Equal(t, "default/web", followUp.Focused().Name)
+	answer, err = followUp.Ask("and the service?")
+	require.NoError(t, err)
+	require.Contains(t, answer, "Findings:\n- Service default/web\n  Error: no endpoints\nConversation:\nQuestion: why does it crash?\nAnswer: ")
+	require.Contains(t, answer, "Question: and the service?")
+	require.Len(t, followUp.History, 2)
+	require.Equal(t, "and the service?", followUp.History[1].Question)
+
+	require.NoError(t, followUp.Focus(-1))
+	require.Nil(t, followUp.Focused())
+	require.EqualError(t, followUp.Focus(2), "no result 2, the analysis has 2 results")
+}
+
+func TestFollowUp_Cache(t *testing.T) {
+	client := &echoAIClient{}
+	a := newFollowUpAnalysis(client)
+
+	first, err := a.NewFollowUp().Ask("why does it crash?")
+	require.NoError(t, err)
+	require.Len(t, client.prompts, 1)
+
+	// The same question in a new conversation is answered from the cache.
+	second, err := a.NewFollowUp().Ask("why does it crash?")
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+	require.Len(t, client.prompts, 1)
+
+	a.CacheOnly = true
+	_, err = a.NewFollowUp().Ask("what about the service?")
+	require.EqualError(t, err, "no cached answer to this question")
+}
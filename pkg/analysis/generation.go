@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/spf13/viper"
+)
+
+// applyGenerationOverrides replaces the generation parameters of the provider
+// by the ones of the generation.temperature, generation.top_p and
+// generation.max_tokens configuration keys which are set, e.g. by the flags
+// of a run.
+func applyGenerationOverrides(provider *ai.AIProvider) error {
+	if viper.IsSet("generation.temperature") {
+		temperature := viper.GetFloat64("generation.temperature")
+		if temperature < 0 || temperature > 1 {
+			return fmt.Errorf("generation.temperature: %v is not between 0 and 1", temperature)
+		}
+		provider.Temperature = float32(temperature)
+		provider.GenerationOverrides = append(provider.GenerationOverrides, ai.GenerationTemperature)
+	}
+	if viper.IsSet("generation.top_p") {
+		topP := viper.GetFloat64("generation.top_p")
+		if topP < 0 || topP > 1 {
+			return fmt.Errorf("generation.top_p: %v is not between 0 and 1", topP)
+		}
+		provider.TopP = float32(topP)
+		provider.GenerationOverrides = append(provider.GenerationOverrides, ai.GenerationTopP)
+	}
+	if viper.IsSet("generation.max_tokens") {
+		maxTokens := viper.GetInt("generation.max_tokens")
+		if maxTokens <= 0 {
+			return fmt.Errorf("generation.max_tokens: %d is not positive", maxTokens)
+		}
+		provider.MaxTokens = maxTokens
+		provider.GenerationOverrides = append(provider.GenerationOverrides, ai.GenerationMaxTokens)
+	}
+	return nil
+}
+
+// generationKey lists the generation parameters set for the provider, see
+// ai.AIProvider.IsGenerationSet, e.g. "temperature=0", so that the
+// explanations generated with other parameters aren't served from the cache.
+// It is empty when none is set, keeping the keys cached before.
+func generationKey(provider ai.AIProvider) string {
+	var params []string
+	if provider.IsGenerationSet(ai.GenerationTemperature) {
+		params = append(params, fmt.Sprintf("temperature=%g", provider.Temperature))
+	}
+	if provider.IsGenerationSet(ai.GenerationTopP) {
+		params = append(params, fmt.Sprintf("top_p=%g", provider.TopP))
+	}
+	if provider.IsGenerationSet(ai.GenerationTopK) {
+		params = append(params, fmt.Sprintf("top_k=%d", provider.TopK))
+	}
+	if provider.IsGenerationSet(ai.GenerationMaxTokens) {
+		params = append(params, fmt.Sprintf("max_tokens=%d", provider.MaxTokens))
+	}
+	return strings.Join(params, " ")
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultProvider has the generation parameters of k8sgpt auth add.
+func defaultProvider() ai.AIProvider {
+	return ai.AIProvider{
+		Name:        "openai",
+		Temperature: ai.DefaultTemperature,
+		TopP:        ai.DefaultTopP,
+		TopK:        ai.DefaultTopK,
+		MaxTokens:   ai.DefaultMaxTokens,
+	}
+}
+
+// Test: the generation configuration keys override the parameters of the provider
+func TestApplyGenerationOverrides(t *testing.T) {
+	viper.Reset()
+	provider := defaultProvider()
+	require.NoError(t, applyGenerationOverrides(&provider))
+	require.Equal(t, defaultProvider(), provider)
+
+	viper.Set("generation.temperature", 0)
+	viper.Set("generation.max_tokens", 512)
+	require.NoError(t, applyGenerationOverrides(&provider))
+	require.Zero(t, provider.Temperature)
+	require.Equal(t, ai.DefaultTopP, provider.TopP)
+	require.Equal(t, 512, provider.MaxTokens)
+	require.Equal(t, []string{ai.GenerationTemperature, ai.GenerationMaxTokens}, provider.GenerationOverrides)
+
+	viper.Set("generation.top_p", 1.5)
+	require.EqualError(t, applyGenerationOverrides(&provider), "generation.top_p: 1.5 is not between 0 and 1")
+	viper.Reset()
+}
+
+// Test: only the generation parameters set are part of the cache key
+func TestGenerationKey(t *testing.T) {
+	require.Empty(t, generationKey(defaultProvider()))
+	require.Empty(t, generationKey(ai.AIProvider{}))
+
+	provider := defaultProvider()
+	provider.Temperature = 0
+	provider.TopP = 0.9
+	provider.MaxTokens = 512
+	require.Equal(t, "top_p=0.9 max_tokens=512", generationKey(provider))
+	// A zero temperature is only set for the run.
+	provider.GenerationOverrides = []string{ai.GenerationTemperature}
+	require.Equal(t, "temperature=0 top_p=0.9 max_tokens=512", generationKey(provider))
+
+	a := Analysis{AIClient: &ai.NoOpAIClient{}}
+	defaults := a.cacheKey(a.primaryAIBackend(), "failure")
+	a.AIGeneration = generationKey(provider)
+	require.NotEqual(t, defaults, a.cacheKey(a.primaryAIBackend(), "failure"))
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// JSONLinesSummary is the last line of the ndjson output, after a line per
+// result. It is the JSON output without its results, Summary telling it apart
+// from them.
+type JSONLinesSummary struct {
+	Summary bool `json:"summary"`
+	JsonOutput
+	// Shadows the results of the JsonOutput, so they are omitted.
+	Results []common.Result `json:"results,omitempty"`
+	// ExplanationError is why the AI provider didn't explain all the results.
+	ExplanationError string `json:"explanationError,omitempty"`
+}
+
+// ndjsonOutput writes a JSON object per result, then the JSONLinesSummary.
+// The results already written to ResultLines by GetAIResults are left out.
+func (a *Analysis) ndjsonOutput() ([]byte, error) {
+	var output bytes.Buffer
+	for index, result := range a.Results {
+		if index < len(a.linesWritten) && a.linesWritten[index] {
+			continue
+		}
+		line, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling json: %v", err)
+		}
+		output.Write(append(line, '\n'))
+	}
+	summary := JSONLinesSummary{Summary: true, JsonOutput: a.getJsonOutput()}
+	if a.explanationErr != nil {
+		summary.ExplanationError = a.explanationErr.Error()
+	}
+	line, err := json.Marshal(summary)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling json: %v", err)
+	}
+	output.Write(line)
+	return output.Bytes(), nil
+}
+
+// writeResultLine writes the result at index to ResultLines once explained.
+// After a failed write the remaining results are left to the ndjson output.
+func (a *Analysis) writeResultLine(index int) {
+	if a.ResultLines == nil {
+		return
+	}
+	if a.linesWritten == nil {
+		a.linesWritten = make([]bool, len(a.Results))
+	}
+	line, err := json.Marshal(a.Results[index])
+	if err == nil {
+		_, err = a.ResultLines.Write(append(line, '\n'))
+	}
+	if err != nil {
+		a.logger().Debug("Results no longer streamed, writing one failed", "kind", a.Results[index].Kind, "name", a.Results[index].Name, "error", err)
+		a.ResultLines = nil
+		return
+	}
+	a.linesWritten[index] = true
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeLines decodes the results and the summary of the ndjson output.
+func decodeLines(t *testing.T, data string) ([]common.Result, *JSONLinesSummary) {
+	var results []common.Result
+	var summary *JSONLinesSummary
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		if strings.Contains(line, `"summary":true`) {
+			require.Nil(t, summary, "a single summary line")
+			summary = &JSONLinesSummary{}
+			require.NoError(t, json.Unmarshal([]byte(line), summary))
+			continue
+		}
+		require.Nil(t, summary, "the summary is the last line")
+		var result common.Result
+		require.NoError(t, json.Unmarshal([]byte(line), &result))
+		results = append(results, result)
+	}
+	return results, summary
+}
+
+// Test: the ndjson output is a line per result then the summary line without the results
+func TestNdjsonOutput(t *testing.T) {
+	a := Analysis{
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/first", Error: []common.Failure{{Text: "crash loop"}}},
+			{Kind: "Service", Name: "default/second", Error: []common.Failure{{Text: "no endpoints"}}},
+		},
+	}
+	output, err := a.PrintOutput("ndjson")
+	require.NoError(t, err)
+
+	results, summary := decodeLines(t, string(output))
+	require.Equal(t, a.Results, results)
+	require.NotNil(t, summary)
+	require.Equal(t, StateProblemDetected, summary.Status)
+	require.Equal(t, 2, summary.Problems)
+	require.NotContains(t, string(output), `"results"`)
+	require.Empty(t, summary.ExplanationError)
+}
+
+// Test: the results are streamed as they are explained, and the summary line follows a failure
+func TestGetAIResults_ResultLines(t *testing.T) {
+	viper.Reset()
+	client := &concurrentAIClient{fail: "failure 2"}
+	a := newConcurrentAnalysis(client, 1)
+	var lines bytes.Buffer
+	a.ResultLines = &lines
+	err := a.GetAIResults("ndjson", false)
+	require.ErrorContains(t, err, "exhausted API quota for AI provider noopai")
+
+	streamed, summary := decodeLines(t, lines.String())
+	require.Nil(t, summary)
+	require.Len(t, streamed, 2)
+	require.Equal(t, "default/pod-0", streamed[0].Name)
+	require.NotEmpty(t, streamed[0].Details)
+
+	output, err := a.PrintOutput("ndjson")
+	require.NoError(t, err)
+	remaining, summary := decodeLines(t, string(output))
+	require.Len(t, remaining, 6)
+	require.Equal(t, "default/pod-2", remaining[0].Name)
+	require.NotNil(t, summary)
+	require.Contains(t, summary.ExplanationError, "(2 of 8 results explained)")
+
+	// The next run of a watch streams all its results again.
+	a.NextRun()
+	require.Nil(t, a.linesWritten)
+	require.Nil(t, a.explanationErr)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Minimal JUnit XML report, as understood by CI servers such as Jenkins.
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+	SystemErr string          `xml:"system-err,omitempty"`
+}
+
+type junitTestCase struct {
+	ClassName string         `xml:"classname,attr"`
+	Name      string         `xml:"name,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// junitOutput converts the results into a single test suite where every result
+// is a test case, of its Kind, and every failure a JUnit failure. The failures
+// count of the suite is the number of problems. A clean cluster produces a
+// single passing test case.
+func (a *Analysis) junitOutput() ([]byte, error) {
+	suite := junitTestSuite{Name: "k8sgpt"}
+
+	for _, result := range a.Results {
+		testCase := junitTestCase{
+			ClassName: result.Kind,
+			Name:      result.Name,
+			SystemOut: result.Details,
+		}
+		for _, failure := range result.Error {
+			testCase.Failures = append(testCase.Failures, junitFailure{
+				Message: failure.Text,
+				Type:    string(result.Severity),
+				Text:    failure.Text,
+			})
+		}
+		suite.Failures += len(testCase.Failures)
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	if len(suite.TestCases) == 0 {
+		suite.TestCases = append(suite.TestCases, junitTestCase{ClassName: "k8sgpt", Name: "No problems detected"})
+	}
+	suite.Tests = len(suite.TestCases)
+	suite.SystemErr = strings.Join(a.Errors.Strings(), "\n")
+
+	output, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling junit: %v", err)
+	}
+	return append([]byte(xml.Header), output...), nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJunitOutput(t *testing.T) {
+	a := &Analysis{
+		Results: []common.Result{
+			{
+				Kind:    "Pod",
+				Name:    "default/pod-1",
+				Error:   []common.Failure{{Text: "first"}, {Text: "second <b>"}},
+				Details: "Solution: restart it",
+			},
+			{
+				Kind:  "Service",
+				Name:  "default/svc",
+				Error: []common.Failure{{Text: "no endpoints"}},
+			},
+		},
+		Errors: AnalysisErrors{{Analyzer: "Ingress", Phase: PhaseAnalysis, Err: errors.New("forbidden")}},
+	}
+
+	output, err := a.PrintOutput("junit")
+	require.NoError(t, err)
+
+	var got junitTestSuite
+	require.NoError(t, xml.Unmarshal(output, &got))
+	require.Equal(t, 2, got.Tests)
+	require.Equal(t, 3, got.Failures)
+	require.Equal(t, "Pod", got.TestCases[0].ClassName)
+	require.Equal(t, "default/pod-1", got.TestCases[0].Name)
+	require.Equal(t, "second <b>", got.TestCases[0].Failures[1].Message)
+	require.Equal(t, "Solution: restart it", got.TestCases[0].SystemOut)
+	require.Equal(t, "[Ingress] forbidden", got.SystemErr)
+}
+
+func TestJunitOutputNoProblems(t *testing.T) {
+	output, err := (&Analysis{}).PrintOutput("junit")
+	require.NoError(t, err)
+
+	var got junitTestSuite
+	require.NoError(t, xml.Unmarshal(output, &got))
+	require.Equal(t, 1, got.Tests)
+	require.Zero(t, got.Failures)
+	require.Empty(t, got.TestCases[0].Failures)
+}
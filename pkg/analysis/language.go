@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/viper"
+)
+
+// defaultLanguage is the language of the explanations when none, or an
+// unsupported one, is set.
+const defaultLanguage = "english"
+
+// supportedLanguages are the languages the explanations may be written in,
+// along with the ones of the ai.languages configuration key.
+var supportedLanguages = []string{
+	"arabic", "chinese", "czech", "dutch", "english", "french", "german", "hindi", "indonesian", "italian",
+	"japanese", "korean", "polish", "portuguese", "russian", "spanish", "swedish", "turkish", "ukrainian", "vietnamese",
+}
+
+func normalizeLanguage(language string) string {
+	return strings.ToLower(strings.TrimSpace(language))
+}
+
+// validLanguage normalizes the casing of language, as it's part of the cache
+// key, and falls back to defaultLanguage with a warning when it isn't
+// supported, e.g. a typo. Empty is defaultLanguage.
+func validLanguage(language string) string {
+	normalized := normalizeLanguage(language)
+	if normalized == "" {
+		return defaultLanguage
+	}
+	if slices.Contains(supportedLanguages, normalized) {
+		return normalized
+	}
+	for _, configured := range viper.GetStringSlice("ai.languages") {
+		if normalizeLanguage(configured) == normalized {
+			return normalized
+		}
+	}
+	// The warning goes to the standard error, not to mix with the output.
+	fmt.Fprintln(os.Stderr, color.YellowString("Warning: unsupported language %q, explaining in %s. Add it to ai.languages to use it anyway.", language, defaultLanguage))
+	return defaultLanguage
+}
+
+// language is the language the prompts ask for, defaultLanguage when the
+// Language isn't set.
+func (a *Analysis) language() string {
+	if strings.TrimSpace(a.Language) != "" {
+		return a.Language
+	}
+	return defaultLanguage
+}
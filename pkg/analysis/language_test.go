@@ -0,0 +1,49 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidLanguage(t *testing.T) {
+	viper.Reset()
+	require.Equal(t, "english", validLanguage(""))
+	require.Equal(t, "english", validLanguage("English"))
+	require.Equal(t, "spanish", validLanguage(" SPANISH "))
+	require.Equal(t, "english", validLanguage("Englsh"))
+
+	viper.Set("ai.languages", []string{"Klingon"})
+	require.Equal(t, "klingon", validLanguage("klingon"))
+}
+
+// Test: the prompts ask for an explanation in English without a language
+func TestAnalysis_LanguagePrompt(t *testing.T) {
+	viper.Reset()
+	for _, language := range []string{"", "  ", validLanguage("%s bogus")} {
+		client := &echoAIClient{}
+		a := Analysis{Context: context.Background(), AIClient: client, Cache: newMemoryCache(), Language: language}
+		_, _, _, _, err := a.getAIResultForSanitizedFailures("Pod", []string{"crash loop"}, ai.PromptMap["default"])
+		require.NoError(t, err)
+		require.Len(t, client.prompts, 1)
+		require.Contains(t, client.prompts[0], "written in --- english --- language.")
+		require.Contains(t, client.prompts[0], "--- crash loop ---")
+		require.NotContains(t, client.prompts[0], "%!")
+	}
+}
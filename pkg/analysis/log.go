@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// NewLogger returns the logger of the analyses without a Logger, and of the
+// commands running them. With the verbose flag it writes the debug records as
+// text to the standard output, without it nothing. The analyses create it for
+// each record, so that it follows the flag and the standard output.
+func NewLogger() *slog.Logger {
+	if !viper.GetBool("verbose") {
+		return slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// logger returns the Logger of the analysis, NewLogger when there's none.
+func (a *Analysis) logger() *slog.Logger {
+	if a.Logger != nil {
+		return a.Logger
+	}
+	return NewLogger()
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// cappedResults are two results of an analyzer capped at one.
+func cappedResults(a *Analysis) {
+	a.MaxResultsPerAnalyzer = 1
+	a.collect(analyzerReport{name: "Pod", results: []common.Result{{Name: "default/first"}, {Name: "default/second"}}})
+	a.capAnalyzerResults()
+}
+
+// Test: the debug records go to the Logger of the analysis, whatever the verbose flag
+func TestLogger_Custom(t *testing.T) {
+	viper.Reset()
+	var records bytes.Buffer
+	a := &Analysis{Logger: slog.New(slog.NewJSONHandler(&records, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+
+	output := util.CaptureOutput(func() { cappedResults(a) })
+
+	require.Empty(t, output)
+	require.Contains(t, records.String(), `"msg":"Results capped, --max-results-per-analyzer reached","analyzer":"Pod","kept":1,"truncated":1`)
+}
+
+// Test: without a Logger the debug records are printed with the verbose flag only
+func TestLogger_Verbose(t *testing.T) {
+	viper.Reset()
+	output := util.CaptureOutput(func() { cappedResults(&Analysis{}) })
+	require.Empty(t, output)
+
+	viper.Set("verbose", true)
+	defer viper.Reset()
+	output = util.CaptureOutput(func() { cappedResults(&Analysis{}) })
+	require.Contains(t, output, `level=DEBUG msg="Results capped, --max-results-per-analyzer reached" analyzer=Pod kept=1 truncated=1`)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// MetricsRecorder is notified by RunAnalysis and GetAIResults of the work they
+// do. Nothing is recorded when Analysis.Metrics is nil.
+type MetricsRecorder interface {
+	// AnalysisCompleted is called once RunAnalysis ran the analyzers.
+	AnalysisCompleted()
+	// AnalyzerCompleted is called after each analyzer, failed is set when it
+	// returned an error.
+	AnalyzerCompleted(analyzer string, duration time.Duration, failed bool)
+	// AICallCompleted is called after each request to an AI provider.
+	AICallCompleted(provider string, duration time.Duration, failed bool)
+	// CacheLookup is called for each explanation looked up in the cache.
+	CacheLookup(hit bool)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) AnalysisCompleted()                            {}
+func (noopMetrics) AnalyzerCompleted(string, time.Duration, bool) {}
+func (noopMetrics) AICallCompleted(string, time.Duration, bool)   {}
+func (noopMetrics) CacheLookup(bool)                              {}
+
+func (a *Analysis) metrics() MetricsRecorder {
+	if a.Metrics == nil {
+		return noopMetrics{}
+	}
+	return a.Metrics
+}
+
+var (
+	analysesMetric = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "k8sgpt_analyses_total",
+		Help: "Number of analyses run",
+	})
+	analyzerDurationMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "k8sgpt_analyzer_duration_seconds",
+		Help:    "Time taken by each analyzer",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"analyzer"})
+	aiCallDurationMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "k8sgpt_ai_request_duration_seconds",
+		Help:    "Latency of the requests to the AI providers",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 40, 80},
+	}, []string{"provider"})
+	cacheLookupsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "k8sgpt_cache_lookups_total",
+		Help: "Number of explanations looked up in the cache, by result (hit or miss)",
+	}, []string{"result"})
+	errorsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "k8sgpt_errors_total",
+		Help: "Number of failed analyzer runs and AI requests",
+	}, []string{"source", "name"})
+)
+
+// PrometheusMetrics records the metrics in the default Prometheus registry.
+type PrometheusMetrics struct{}
+
+func (PrometheusMetrics) AnalysisCompleted() {
+	analysesMetric.Inc()
+}
+
+func (PrometheusMetrics) AnalyzerCompleted(analyzer string, duration time.Duration, failed bool) {
+	analyzerDurationMetric.WithLabelValues(analyzer).Observe(duration.Seconds())
+	if failed {
+		errorsMetric.WithLabelValues("analyzer", analyzer).Inc()
+	}
+}
+
+func (PrometheusMetrics) AICallCompleted(provider string, duration time.Duration, failed bool) {
+	aiCallDurationMetric.WithLabelValues(provider).Observe(duration.Seconds())
+	if failed {
+		errorsMetric.WithLabelValues("ai", provider).Inc()
+	}
+}
+
+func (PrometheusMetrics) CacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookupsMetric.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type recordingMetrics struct {
+	mutex     sync.Mutex
+	analyses  int
+	analyzers []string
+	aiCalls   []string
+	lookups   []bool
+}
+
+func (m *recordingMetrics) AnalysisCompleted() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.analyses++
+}
+
+func (m *recordingMetrics) AnalyzerCompleted(analyzer string, _ time.Duration, _ bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.analyzers = append(m.analyzers, analyzer)
+}
+
+func (m *recordingMetrics) AICallCompleted(provider string, _ time.Duration, _ bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.aiCalls = append(m.aiCalls, provider)
+}
+
+func (m *recordingMetrics) CacheLookup(hit bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.lookups = append(m.lookups, hit)
+}
+
+func TestAnalysis_Metrics(t *testing.T) {
+	viper.Reset()
+	metrics := &recordingMetrics{}
+	c := newMemoryCache()
+	a := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Pod"},
+		MaxConcurrency: 1,
+		Client:         &kubernetes.Client{Client: fake.NewSimpleClientset()},
+		AIClient:       &ai.NoOpAIClient{},
+		Cache:          c,
+		PromptMap:      map[string]string{"default": "%s %s %s"},
+		Metrics:        metrics,
+	}
+	a.RunAnalysis()
+	require.Equal(t, 1, metrics.analyses)
+	require.Equal(t, []string{"Pod"}, metrics.analyzers)
+
+	_, _, _, _, err := a.getAIResultForSanitizedFailures("Pod", []string{"pod failure"}, "%s %s %s")
+	require.NoError(t, err)
+	_, _, _, _, err = a.getAIResultForSanitizedFailures("Pod", []string{"pod failure"}, "%s %s %s")
+	require.NoError(t, err)
+	require.Equal(t, []string{"noopai"}, metrics.aiCalls)
+	require.Equal(t, []bool{false, true}, metrics.lookups)
+}
+
+func TestAnalysis_MetricsDisabled(t *testing.T) {
+	a := Analysis{}
+	require.Equal(t, noopMetrics{}, a.metrics())
+	a.Metrics = PrometheusMetrics{}
+	require.Equal(t, PrometheusMetrics{}, a.metrics())
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import "fmt"
+
+// offlineDegradedAnalyzers are the analyzers which also check live data the
+// manifests don't provide, by what they miss when analyzing offline.
+var offlineDegradedAnalyzers = map[string]string{
+	"Pod":                   "events aren't available, containers stuck creating aren't explained",
+	"PersistentVolumeClaim": "events aren't available, provisioning failures aren't reported",
+	"StatefulSet":           "events aren't available, pods failing to be created aren't reported",
+	"Service":               "events aren't available, endpoint events aren't reported",
+	"Log":                   "container logs aren't available, no problems are reported",
+}
+
+// warnOffline reports in a.Errors the checks of the analyzer skipped offline.
+func (a *Analysis) warnOffline(name string) {
+	if skipped, ok := offlineDegradedAnalyzers[name]; ok {
+		a.addError(name, PhaseAnalysis, fmt.Errorf("analyzing manifests offline, %s", skipped))
+	}
+}
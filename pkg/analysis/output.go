@@ -3,14 +3,48 @@ package analysis
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v3"
 )
 
+// GroupByNamespace groups the results of the text output by namespace.
+const GroupByNamespace = "namespace"
+
 var outputFormats = map[string]func(*Analysis) ([]byte, error){
-	"json": (*Analysis).jsonOutput,
-	"text": (*Analysis).textOutput,
+	"json":    (*Analysis).jsonOutput,
+	"text":    (*Analysis).textOutput,
+	"yaml":    (*Analysis).yamlOutput,
+	"sarif":   (*Analysis).sarifOutput,
+	"junit":   (*Analysis).junitOutput,
+	"summary": (*Analysis).summaryOutput,
+	"ndjson":  (*Analysis).ndjsonOutput,
+}
+
+// machineReadableOutputFormats are the formats meant to be consumed by other
+// tools, so nothing else (e.g. a progress bar) may be interleaved with them.
+var machineReadableOutputFormats = map[string]bool{
+	"json":    true,
+	"yaml":    true,
+	"sarif":   true,
+	"junit":   true,
+	"summary": true,
+	"ndjson":  true,
+}
+
+// isMachineReadableOutput tells whether one of the comma-separated formats is
+// machine readable.
+func isMachineReadableOutput(formats string) bool {
+	return slices.ContainsFunc(strings.Split(formats, ","), func(format string) bool {
+		return machineReadableOutputFormats[strings.TrimSpace(format)]
+	})
 }
 
 func getOutputFormats() []string {
@@ -30,29 +64,131 @@ func (a *Analysis) PrintOutput(format string) ([]byte, error) {
 }
 
 func (a *Analysis) jsonOutput() ([]byte, error) {
+	output, err := json.MarshalIndent(a.getJsonOutput(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling json: %v", err)
+	}
+	return output, nil
+}
+
+// yamlOutput serializes the same document as jsonOutput. The JSON encoding is
+// decoded into a YAML node tree, which keeps the json field names and order,
+// and multi-line strings (e.g. AI details) are emitted as literal block scalars.
+func (a *Analysis) yamlOutput() ([]byte, error) {
+	jsonData, err := json.Marshal(a.getJsonOutput())
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling json: %v", err)
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(jsonData, &node); err != nil {
+		return nil, fmt.Errorf("error converting json to yaml: %v", err)
+	}
+	resetYamlStyle(&node)
+	output, err := yaml.Marshal(&node)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling yaml: %v", err)
+	}
+	return output, nil
+}
+
+// resetYamlStyle drops the flow/quoted styles inherited from the JSON input so
+// the document is rendered in block style.
+func resetYamlStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		resetYamlStyle(child)
+	}
+}
+
+// Status is StateProblemDetected when the results have problems, whatever the
+// errors, StateNoAnalyzers when none of the filters exist, so no analyzer ran,
+// StatePartialFailure when there are errors, and StateOK otherwise.
+func (a *Analysis) Status() AnalysisStatus {
+	switch {
+	case a.problems() > 0:
+		return StateProblemDetected
+	case a.noAnalyzers:
+		return StateNoAnalyzers
+	case len(a.Errors) > 0:
+		return StatePartialFailure
+	default:
+		return StateOK
+	}
+}
+
+func (a *Analysis) problems() int {
 	var problems int
-	var status AnalysisStatus
 	for _, result := range a.Results {
 		problems += len(result.Error)
 	}
-	if problems > 0 {
-		status = StateProblemDetected
-	} else {
-		status = StateOK
+	return problems
+}
+
+// summaryOutput is the Status and the number of problems on a single line,
+// e.g. "ProblemDetected: 7 problems", for the scripts. The errors are
+// counted, and with WithStats the analyzers, the slowest of them and the
+// tokens used are appended.
+func (a *Analysis) summaryOutput() ([]byte, error) {
+	summary := fmt.Sprintf("%s: %s", a.Status(), plural(a.problems(), "problem"))
+	if len(a.Errors) > 0 {
+		summary += ", " + plural(len(a.Errors), "error")
+	}
+	if a.WithStats && len(a.Stats) > 0 {
+		slowest := a.Stats[0]
+		for _, stat := range a.Stats[1:] {
+			if stat.DurationTime > slowest.DurationTime {
+				slowest = stat
+			}
+		}
+		summary += fmt.Sprintf(" (%s, slowest %s in %s", plural(len(a.Stats), "analyzer"), slowest.Analyzer, slowest.DurationTime.Round(time.Millisecond))
+		if tokens := a.getJsonStats().TotalTokens; tokens > 0 {
+			summary += ", " + plural(tokens, "token")
+		}
+		summary += ")"
+	}
+	return []byte(summary), nil
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
 	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
 
+func (a *Analysis) getJsonOutput() JsonOutput {
 	result := JsonOutput{
-		Provider: a.AnalysisAIProvider,
-		Problems: problems,
-		Results:  a.Results,
-		Errors:   a.Errors,
-		Status:   status,
+		Provider:         a.AnalysisAIProvider,
+		Problems:         a.problems(),
+		Results:          a.Results,
+		Errors:           a.Errors,
+		Status:           a.Status(),
+		SkippedAnalyzers: a.SkippedAnalyzers,
+		Diff:             a.Diff(),
 	}
-	output, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling json: %v", err)
+	if len(a.cappedResults) > 0 {
+		result.TotalResults = a.totalResults()
 	}
-	return output, nil
+	if len(a.truncated) > 0 {
+		result.TruncatedResults = a.truncated
+	}
+	if a.WithStats {
+		result.Stats = a.getJsonStats()
+	}
+	return result
+}
+
+func (a *Analysis) getJsonStats() *JsonStats {
+	stats := &JsonStats{Analyzers: a.Stats, Cache: a.CacheStats()}
+	for _, stat := range a.Stats {
+		stats.PromptTokens += stat.PromptTokens
+		stats.CompletionTokens += stat.CompletionTokens
+	}
+	stats.TotalTokens = stats.PromptTokens + stats.CompletionTokens
+	return stats
 }
 
 func (a *Analysis) PrintStats() []byte {
@@ -60,47 +196,242 @@ func (a *Analysis) PrintStats() []byte {
 
 	output.WriteString(color.YellowString("The stats mode allows for debugging and understanding the time taken by an analysis by displaying the statistics of each analyzer.\n"))
 
+	var withTokens bool
+	for _, stat := range a.Stats {
+		withTokens = withTokens || stat.PromptTokens > 0 || stat.CompletionTokens > 0
+	}
+	headers := []string{"Analyzer", "Duration", "Objects scanned", "Problems"}
+	if withTokens {
+		headers = append(headers, "Tokens")
+	}
+	table := tablewriter.NewWriter(&output)
+	table.SetHeader(headers)
 	for _, stat := range a.Stats {
-		output.WriteString(fmt.Sprintf("- Analyzer %s took %s \n", color.YellowString(stat.Analyzer), stat.DurationTime))
+		// Integrations and custom analyzers don't report the objects they scan.
+		scanned := "n/a"
+		if stat.ObjectsScanned != nil {
+			scanned = strconv.Itoa(*stat.ObjectsScanned)
+		}
+		row := []string{stat.Analyzer, stat.DurationTime.String(), scanned, strconv.Itoa(stat.Problems)}
+		if withTokens {
+			row = append(row, fmt.Sprintf("%d prompt, %d completion%s", stat.PromptTokens, stat.CompletionTokens, estimatedSuffix(stat.TokensEstimated)))
+		}
+		table.Append(row)
+	}
+	table.Render()
+
+	stats := a.getJsonStats()
+	if stats.TotalTokens > 0 {
+		var estimated bool
+		for _, stat := range a.Stats {
+			estimated = estimated || stat.TokensEstimated
+		}
+		output.WriteString(fmt.Sprintf("Total tokens: %s (%d prompt, %d completion)%s\n", color.YellowString("%d", stats.TotalTokens), stats.PromptTokens, stats.CompletionTokens, estimatedSuffix(estimated)))
+	}
+	if stats.Cache != (CacheStats{}) {
+		output.WriteString(fmt.Sprintf("Cache: %s\n", stats.Cache))
 	}
 
 	return []byte(output.String())
 }
 
+// WriteStats writes the stats of the analysis as JSON to the file at path, with
+// the durations in milliseconds, e.g. for dashboards. See writeFileAtomic.
+func (a *Analysis) WriteStats(path string) error {
+	data, err := json.MarshalIndent(a.getJsonStats(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling json: %v", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("writing stats to %s: %w", path, err)
+	}
+	return nil
+}
+
+func severityLabel(severity common.Severity) string {
+	switch severity {
+	case "":
+		return ""
+	case common.SeverityCritical:
+		return color.RedString("[%s] ", severity)
+	default:
+		return fmt.Sprintf("[%s] ", severity)
+	}
+}
+
+func clusterLabel(cluster string) string {
+	if cluster == "" {
+		return ""
+	}
+	return color.MagentaString("(%s) ", cluster)
+}
+
+func detectedByLabel(detectedBy int) string {
+	if detectedBy < 2 {
+		return ""
+	}
+	return fmt.Sprintf(" (detected by %d analyzers)", detectedBy)
+}
+
+func cachedLabel(cached bool) string {
+	if !cached {
+		return ""
+	}
+	return " (cached)"
+}
+
+func estimatedSuffix(estimated bool) string {
+	if estimated {
+		return " (estimated)"
+	}
+	return ""
+}
+
 func (a *Analysis) textOutput() ([]byte, error) {
+	return renderText(a.getJsonOutput(), a.Explain, a.GroupBy, a.ExecutionBudget, len(a.ShowKinds) > 0), nil
+}
+
+// renderText formats output as text, explained tells whether the AI provider
+// was used and kindsShown whether the results were filtered by ShowKinds.
+func renderText(jsonOutput JsonOutput, explained bool, groupBy string, executionBudget time.Duration, kindsShown bool) []byte {
 	var output strings.Builder
 
 	// Print the AI provider used for this analysis (if explain was enabled).
-	if a.Explain {
-		output.WriteString(fmt.Sprintf("AI Provider: %s\n", color.YellowString(a.AnalysisAIProvider)))
+	if explained {
+		output.WriteString(fmt.Sprintf("AI Provider: %s\n", color.YellowString(jsonOutput.Provider)))
 	} else {
 		output.WriteString(fmt.Sprintf("AI Provider: %s\n", color.YellowString("AI not used; --explain not set")))
 	}
 
-	if len(a.Errors) != 0 {
+	if len(jsonOutput.Errors) != 0 {
 		output.WriteString("\n")
 		output.WriteString(color.YellowString("Warnings : \n"))
-		for _, aerror := range a.Errors {
-			output.WriteString(fmt.Sprintf("- %s\n", color.YellowString(aerror)))
+		for _, aerror := range jsonOutput.Errors {
+			output.WriteString(fmt.Sprintf("- %s\n", color.YellowString(aerror.Error())))
+		}
+	}
+	if len(jsonOutput.SkippedAnalyzers) != 0 {
+		output.WriteString("\n")
+		output.WriteString(color.YellowString("Skipped analyzers (execution budget of %s exceeded): \n", executionBudget))
+		for _, skipped := range jsonOutput.SkippedAnalyzers {
+			output.WriteString(fmt.Sprintf("- %s\n", color.YellowString(skipped)))
 		}
 	}
 	output.WriteString("\n")
-	if len(a.Results) == 0 {
+	if jsonOutput.Status == StateNoAnalyzers {
+		output.WriteString(color.RedString("No analyzer ran, none of the filters exist\n"))
+	} else if len(jsonOutput.Results) == 0 && kindsShown {
+		output.WriteString(color.GreenString("0 matching results\n"))
+	} else if len(jsonOutput.Results) == 0 && jsonOutput.Status == StatePartialFailure {
+		output.WriteString(color.YellowString("No problems detected, but the analysis is incomplete, see the warnings\n"))
+	} else if len(jsonOutput.Results) == 0 {
 		output.WriteString(color.GreenString("No problems detected\n"))
-		return []byte(output.String()), nil
-	}
-	for n, result := range a.Results {
-		output.WriteString(fmt.Sprintf("%s: %s %s(%s)\n", color.CyanString("%d", n),
-			color.HiYellowString(result.Kind),
-			color.YellowString(result.Name),
-			color.CyanString(result.ParentObject)))
-		for _, err := range result.Error {
-			output.WriteString(fmt.Sprintf("- %s %s\n", color.RedString("Error:"), color.RedString(err.Text)))
-			if err.KubernetesDoc != "" {
-				output.WriteString(fmt.Sprintf("  %s %s\n", color.RedString("Kubernetes Doc:"), color.RedString(err.KubernetesDoc)))
+	} else if groupBy == GroupByNamespace {
+		writeResultsByNamespace(&output, jsonOutput.Results)
+	} else {
+		for n, result := range jsonOutput.Results {
+			writeTextResult(&output, n, result)
+		}
+	}
+	if len(jsonOutput.TruncatedResults) > 0 {
+		analyzers := make([]string, 0, len(jsonOutput.TruncatedResults))
+		for analyzer := range jsonOutput.TruncatedResults {
+			analyzers = append(analyzers, analyzer)
+		}
+		sort.Strings(analyzers)
+		output.WriteString("\n")
+		for _, analyzer := range analyzers {
+			output.WriteString(color.YellowString("%s: (and %d more, --max-results-per-analyzer)\n", analyzer, jsonOutput.TruncatedResults[analyzer]))
+		}
+	}
+	if jsonOutput.TotalResults > 0 {
+		output.WriteString(color.YellowString("\nShowing %d of %d problems (--max-problems)\n", len(jsonOutput.Results), jsonOutput.TotalResults))
+	}
+	if jsonOutput.Diff != nil {
+		writeTextDiff(&output, jsonOutput.Diff)
+	}
+	return []byte(output.String())
+}
+
+func writeTextResult(output *strings.Builder, n int, result common.Result) {
+	output.WriteString(fmt.Sprintf("%s: %s%s%s %s(%s)%s%s\n", color.CyanString("%d", n),
+		clusterLabel(result.Cluster),
+		severityLabel(result.Severity),
+		color.HiYellowString(result.Kind),
+		color.YellowString(result.Name),
+		color.CyanString(result.ParentObject),
+		detectedByLabel(result.DetectedBy),
+		cachedLabel(result.Cached)))
+	for _, err := range result.Error {
+		output.WriteString(fmt.Sprintf("- %s %s\n", color.RedString("Error:"), color.RedString(err.Text)))
+		if err.KubernetesDoc != "" {
+			output.WriteString(fmt.Sprintf("  %s %s\n", color.RedString("Kubernetes Doc:"), color.RedString(err.KubernetesDoc)))
+		}
+	}
+	output.WriteString(color.GreenString(result.Details + "\n"))
+	if len(result.Remediation) != 0 {
+		output.WriteString(color.CyanString("Remediation:\n"))
+		for _, remediation := range result.Remediation {
+			if remediation.Description != "" {
+				output.WriteString(fmt.Sprintf("- %s  %s\n", remediation.Command, color.CyanString("# %s", remediation.Description)))
+			} else {
+				output.WriteString(fmt.Sprintf("- %s\n", remediation.Command))
 			}
 		}
-		output.WriteString(color.GreenString(result.Details + "\n"))
 	}
-	return []byte(output.String()), nil
+}
+
+// writeResultsByNamespace writes the results under a header per namespace, in
+// namespace order with the cluster-scoped results last, then a summary. The
+// results keep their number in the flat list.
+func writeResultsByNamespace(output *strings.Builder, results []common.Result) {
+	indices := map[string][]int{}
+	var namespaces []string
+	var problems int
+	for n, result := range results {
+		namespace := resultNamespace(result)
+		if _, ok := indices[namespace]; !ok {
+			namespaces = append(namespaces, namespace)
+		}
+		indices[namespace] = append(indices[namespace], n)
+		problems += len(result.Error)
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		if namespaces[i] == "" || namespaces[j] == "" {
+			return namespaces[j] == ""
+		}
+		return namespaces[i] < namespaces[j]
+	})
+
+	affected := 0
+	for _, namespace := range namespaces {
+		header := "Cluster-scoped"
+		if namespace != "" {
+			header = fmt.Sprintf("Namespace %s", namespace)
+			affected++
+		}
+		output.WriteString(fmt.Sprintf("%s (%s)\n", color.HiCyanString(header), resultCount(len(indices[namespace]))))
+		for _, n := range indices[namespace] {
+			writeTextResult(output, n, results[n])
+		}
+		output.WriteString("\n")
+	}
+	output.WriteString(fmt.Sprintf("Total: %s in %d namespaces\n", color.RedString("%d problems", problems), affected))
+}
+
+// resultNamespace returns the namespace of a result named "<namespace>/<name>",
+// empty for the cluster-scoped ones.
+func resultNamespace(result common.Result) string {
+	namespace, _, found := strings.Cut(result.Name, "/")
+	if !found {
+		return ""
+	}
+	return namespace
+}
+
+func resultCount(n int) string {
+	if n == 1 {
+		return "1 result"
+	}
+	return fmt.Sprintf("%d results", n)
 }
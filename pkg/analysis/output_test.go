@@ -14,9 +14,13 @@ limitations under the License.
 package analysis
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestPrintOutput(t *testing.T) {
@@ -41,6 +45,67 @@ func TestPrintOutput(t *testing.T) {
 			format:         "text",
 			expectedOutput: "AI Provider: AI not used; --explain not set\n\nNo problems detected\n",
 		},
+		{
+			name:           "yaml format",
+			a:              &Analysis{},
+			format:         "yaml",
+			expectedOutput: "provider: \"\"\nerrors: null\nstatus: OK\nproblems: 0\nresults: null\n",
+		},
+		{
+			name:           "summary format",
+			a:              &Analysis{},
+			format:         "summary",
+			expectedOutput: "OK: 0 problems",
+		},
+		{
+			name: "summary format with problems and errors",
+			a: &Analysis{
+				Results: []common.Result{
+					{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "CrashLoopBackOff"}, {Text: "OOMKilled"}}},
+					{Kind: "Service", Name: "default/web", Error: []common.Failure{{Text: "no endpoints"}}},
+				},
+				Errors: AnalysisErrors{{Analyzer: "Node", Err: errors.New("forbidden")}},
+			},
+			format:         "summary",
+			expectedOutput: "ProblemDetected: 3 problems, 1 error",
+		},
+		{
+			name: "summary format with stats",
+			a: &Analysis{
+				Results:   []common.Result{{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "CrashLoopBackOff"}}}},
+				WithStats: true,
+				Stats: []common.AnalysisStats{
+					{Analyzer: "Service", DurationTime: 120 * time.Millisecond},
+					{Analyzer: "Pod", DurationTime: 1234567 * time.Microsecond, PromptTokens: 100, CompletionTokens: 20},
+				},
+			},
+			format:         "summary",
+			expectedOutput: "ProblemDetected: 1 problem (2 analyzers, slowest Pod in 1.235s, 120 tokens)",
+		},
+		{
+			name:           "summary format without analyzers",
+			a:              &Analysis{noAnalyzers: true},
+			format:         "summary",
+			expectedOutput: "NoAnalyzers: 0 problems",
+		},
+		{
+			name:           "summary format with errors only",
+			a:              &Analysis{Errors: AnalysisErrors{{Analyzer: "Node", Err: errors.New("forbidden")}}},
+			format:         "summary",
+			expectedOutput: "PartialFailure: 0 problems, 1 error",
+		},
+		{
+			name:           "text format with errors only",
+			a:              &Analysis{Errors: AnalysisErrors{{Analyzer: "Node", Err: errors.New("forbidden")}}},
+			format:         "text",
+			expectedOutput: "- [Node] forbidden\n\nNo problems detected, but the analysis is incomplete, see the warnings\n",
+		},
+		{
+			name:           "json format with errors only",
+			a:              &Analysis{Errors: AnalysisErrors{{Analyzer: "Node", Err: errors.New("forbidden")}}},
+			format:         "json",
+			expectedOutput: "\"status\": \"PartialFailure\"",
+		},
 		{
 			name:        "unsupported format",
 			a:           &Analysis{},
@@ -62,3 +127,84 @@ func TestPrintOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestYamlOutputRoundTrip(t *testing.T) {
+	a := &Analysis{
+		AnalysisAIProvider: "openai",
+		Results: []common.Result{
+			{
+				Kind:    "Pod",
+				Name:    "default/example",
+				Error:   []common.Failure{{Text: "Back-off pulling image \"nginx:latest\""}},
+				Details: "Error: the image cannot be pulled.\nSolution: 1. Check the tag\n2. Retry\n",
+			},
+			{
+				Kind:    "Service",
+				Name:    "true",
+				Details: "123",
+			},
+		},
+	}
+
+	output, err := a.PrintOutput("yaml")
+	require.NoError(t, err)
+	// Multi-line AI details are emitted as a block scalar instead of escaped newlines.
+	require.Contains(t, string(output), "details: |\n")
+	require.NotContains(t, string(output), "\\n")
+
+	var got JsonOutput
+	require.NoError(t, yaml.Unmarshal(output, &got))
+	require.Equal(t, StateProblemDetected, got.Status)
+	require.Equal(t, 1, got.Problems)
+	require.Len(t, got.Results, 2)
+	require.Equal(t, a.Results[0].Details, got.Results[0].Details)
+	// Strings which look like other YAML types keep their string type.
+	require.Equal(t, "true", got.Results[1].Name)
+	require.Equal(t, "123", got.Results[1].Details)
+}
+
+func TestTextOutputGroupByNamespace(t *testing.T) {
+	a := &Analysis{
+		GroupBy: GroupByNamespace,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "web/frontend", Error: []common.Failure{{Text: "frontend failure"}}},
+			{Kind: "Node", Name: "node-1", Error: []common.Failure{{Text: "node failure"}}},
+			{Kind: "Service", Name: "api/backend", Error: []common.Failure{{Text: "backend failure"}, {Text: "another failure"}}},
+			{Kind: "Pod", Name: "web/cache", Error: []common.Failure{{Text: "cache failure"}}},
+		},
+	}
+	output, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Equal(t, `AI Provider: AI not used; --explain not set
+
+Namespace api (1 result)
+2: Service api/backend()
+- Error: backend failure
+- Error: another failure
+
+
+Namespace web (2 results)
+0: Pod web/frontend()
+- Error: frontend failure
+
+3: Pod web/cache()
+- Error: cache failure
+
+
+Cluster-scoped (1 result)
+1: Node node-1()
+- Error: node failure
+
+
+Total: 5 problems in 2 namespaces
+`, string(output))
+
+	// JSON output isn't grouped.
+	a.GroupBy = ""
+	flat, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	a.GroupBy = GroupByNamespace
+	grouped, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	require.Equal(t, flat, grouped)
+}
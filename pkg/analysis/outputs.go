@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FormatPlaceholder is replaced by the format of each output in the path of
+// ParseOutputs, e.g. report.{format}.
+const FormatPlaceholder = "{format}"
+
+// Output is a format an analysis is rendered in, written to File, or to the
+// standard output when File is empty.
+type Output struct {
+	Format string
+	File   string
+}
+
+// ParseOutputs returns the outputs of formats, a comma-separated list of
+// output formats, in order. files is where they are written: a path for a
+// single format, a path with FormatPlaceholder, or a comma-separated list of
+// format=path. The formats without a path are written to the standard output.
+func ParseOutputs(formats string, files string) ([]Output, error) {
+	var outputs []Output
+	for _, format := range strings.Split(formats, ",") {
+		format = strings.TrimSpace(format)
+		if _, ok := outputFormats[format]; !ok {
+			return nil, fmt.Errorf("unsupported output format: %s. Available format %s", format, strings.Join(getOutputFormats(), ","))
+		}
+		if !slices.ContainsFunc(outputs, func(output Output) bool { return output.Format == format }) {
+			outputs = append(outputs, Output{Format: format})
+		}
+	}
+	switch {
+	case files == "":
+	case strings.Contains(files, FormatPlaceholder):
+		for index := range outputs {
+			outputs[index].File = strings.ReplaceAll(files, FormatPlaceholder, outputs[index].Format)
+		}
+	case isOutputFileList(files):
+		for _, entry := range strings.Split(files, ",") {
+			format, path, _ := strings.Cut(entry, "=")
+			format, path = strings.TrimSpace(format), strings.TrimSpace(path)
+			index := slices.IndexFunc(outputs, func(output Output) bool { return output.Format == format })
+			if index < 0 {
+				return nil, fmt.Errorf("output file %s: %s is not one of the output formats", path, format)
+			}
+			outputs[index].File = path
+		}
+	case len(outputs) > 1:
+		return nil, fmt.Errorf("output file %s: it would be written by each of the %d output formats, add %s to the path or map the formats to their files with format=path", files, len(outputs), FormatPlaceholder)
+	default:
+		outputs[0].File = files
+	}
+	return outputs, nil
+}
+
+// isOutputFileList tells whether files is a list of format=path rather than a
+// path, which may have an = too.
+func isOutputFileList(files string) bool {
+	format, _, found := strings.Cut(files, "=")
+	_, ok := outputFormats[strings.TrimSpace(format)]
+	return found && ok
+}
+
+// WriteOutputs writes the analysis to each of the outputs with a FormatSink,
+// after a single analysis and explanation of the results. It returns the errors
+// of the outputs which couldn't be written, the others are written regardless.
+func (a *Analysis) WriteOutputs(outputs []Output) error {
+	var errs []error
+	jsonOutput := a.getJsonOutput()
+	for _, output := range outputs {
+		sink := &FormatSink{Analysis: a, Format: output.Format, Path: output.File}
+		if err := sink.Write(jsonOutput); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+// Test: the output formats and their files are parsed from the flags
+func TestParseOutputs(t *testing.T) {
+	tests := []struct {
+		formats string
+		files   string
+		want    []Output
+		err     string
+	}{
+		{formats: "text", want: []Output{{Format: "text"}}},
+		{formats: "json", files: "out.json", want: []Output{{Format: "json", File: "out.json"}}},
+		{formats: "junit, json,junit", want: []Output{{Format: "junit"}, {Format: "json"}}},
+		{formats: "junit,json", files: "report.{format}", want: []Output{{Format: "junit", File: "report.junit"}, {Format: "json", File: "report.json"}}},
+		{formats: "text,junit,json", files: "junit=report.xml, json=a=b.json", want: []Output{{Format: "text"}, {Format: "junit", File: "report.xml"}, {Format: "json", File: "a=b.json"}}},
+		{formats: "json", files: "a=b.json", want: []Output{{Format: "json", File: "a=b.json"}}},
+		{formats: "text,xml", err: "unsupported output format: xml"},
+		{formats: "junit,json", files: "report.out", err: "output file report.out: it would be written by each of the 2 output formats"},
+		{formats: "junit", files: "json=report.json", err: "output file report.json: json is not one of the output formats"},
+	}
+	for _, tt := range tests {
+		outputs, err := ParseOutputs(tt.formats, tt.files)
+		if tt.err != "" {
+			require.ErrorContains(t, err, tt.err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tt.want, outputs)
+	}
+}
+
+// Test: the progress bar is only drawn when none of the console formats is machine readable
+func TestIsMachineReadableOutput(t *testing.T) {
+	require.False(t, isMachineReadableOutput("text"))
+	require.True(t, isMachineReadableOutput("junit"))
+	require.True(t, isMachineReadableOutput("text, json"))
+}
+
+// Test: a single analysis is rendered in each of the outputs
+func TestAnalysis_WriteOutputs(t *testing.T) {
+	dir := t.TempDir()
+	a := &Analysis{Results: []common.Result{{Kind: "Pod", Name: "default/example", Error: []common.Failure{{Text: "pending"}}}}}
+	outputs, err := ParseOutputs("summary,json,junit", "json="+filepath.Join(dir, "out.json")+",junit="+filepath.Join(dir, "out.xml"))
+	require.NoError(t, err)
+
+	output := util.CaptureOutput(func() {
+		require.NoError(t, a.WriteOutputs(outputs))
+	})
+
+	require.Equal(t, "ProblemDetected: 1 problem\n", output)
+	data, err := os.ReadFile(filepath.Join(dir, "out.json"))
+	require.NoError(t, err)
+	var jsonOutput JsonOutput
+	require.NoError(t, json.Unmarshal(data, &jsonOutput))
+	require.Equal(t, a.Results, jsonOutput.Results)
+	data, err = os.ReadFile(filepath.Join(dir, "out.xml"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), "<?xml"))
+}
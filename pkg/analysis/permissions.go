@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// permission is a verb on a resource the analyzers need.
+type permission struct {
+	verb        string
+	group       string
+	resource    string
+	subresource string
+}
+
+// String describes the permission like kubectl auth can-i, e.g. list
+// deployments.apps.
+func (p permission) String() string {
+	resource := p.resource
+	if p.group != "" {
+		resource += "." + p.group
+	}
+	if p.subresource != "" {
+		resource += "/" + p.subresource
+	}
+	return p.verb + " " + resource
+}
+
+// analyzerPermissions are the permissions the core analyzers can't run
+// without, the objects they list. The objects they only get along the way
+// aren't checked, the analyzers go on without them.
+var analyzerPermissions = map[string][]permission{
+	"Pod":                                 {{verb: "list", resource: "pods"}},
+	"Deployment":                          {{verb: "list", group: "apps", resource: "deployments"}},
+	"ReplicaSet":                          {{verb: "list", group: "apps", resource: "replicasets"}},
+	"PersistentVolumeClaim":               {{verb: "list", resource: "persistentvolumeclaims"}},
+	"Service":                             {{verb: "list", resource: "endpoints"}},
+	"Ingress":                             {{verb: "list", group: "networking.k8s.io", resource: "ingresses"}},
+	"StatefulSet":                         {{verb: "list", group: "apps", resource: "statefulsets"}},
+	"Job":                                 {{verb: "list", group: "batch", resource: "jobs"}},
+	"CronJob":                             {{verb: "list", group: "batch", resource: "cronjobs"}},
+	"Node":                                {{verb: "list", resource: "nodes"}},
+	"ValidatingWebhookConfiguration":      {{verb: "list", group: "admissionregistration.k8s.io", resource: "validatingwebhookconfigurations"}},
+	"MutatingWebhookConfiguration":        {{verb: "list", group: "admissionregistration.k8s.io", resource: "mutatingwebhookconfigurations"}},
+	"ConfigMap":                           {{verb: "list", resource: "configmaps"}, {verb: "list", resource: "pods"}},
+	"HorizontalPodAutoscaler":             {{verb: "list", group: "autoscaling", resource: "horizontalpodautoscalers"}},
+	"PodDisruptionBudget":                 {{verb: "list", group: "policy", resource: "poddisruptionbudgets"}},
+	"NetworkPolicy":                       {{verb: "list", group: "networking.k8s.io", resource: "networkpolicies"}},
+	"Log":                                 {{verb: "list", resource: "pods"}, {verb: "get", resource: "pods", subresource: "log"}},
+	"GatewayClass":                        {{verb: "list", group: "gateway.networking.k8s.io", resource: "gatewayclasses"}},
+	"Gateway":                             {{verb: "list", group: "gateway.networking.k8s.io", resource: "gateways"}},
+	"HTTPRoute":                           {{verb: "list", group: "gateway.networking.k8s.io", resource: "httproutes"}},
+	"Storage":                             {{verb: "list", resource: "persistentvolumes"}, {verb: "list", group: "storage.k8s.io", resource: "storageclasses"}},
+	"Security":                            {{verb: "list", resource: "pods"}, {verb: "list", resource: "serviceaccounts"}},
+	"ContainerResources":                  {{verb: "list", resource: "pods"}},
+	"TLSCertificate":                      {{verb: "list", resource: "secrets"}},
+	"IngressBackend":                      {{verb: "list", group: "networking.k8s.io", resource: "ingresses"}},
+	"PodDisruptionBudgetMisconfiguration": {{verb: "list", group: "policy", resource: "poddisruptionbudgets"}, {verb: "list", resource: "pods"}},
+}
+
+// checkPermissions reviews with the API server whether the analyzers of the
+// run, names in namespaces as sharded by runAnalysis, are permitted to list
+// what they analyze. It returns the labels of the analyzer runs missing
+// permissions, which aren't launched, and reports the permissions missing in
+// a single error rather than an error per analyzer. When the review itself
+// fails, no analyzer run is held back.
+func (a *Analysis) checkPermissions(names []string, namespaces []string) map[string]bool {
+	ctx := a.contextOrBackground()
+	reviews := a.Client.GetClient().AuthorizationV1().SelfSubjectAccessReviews()
+	// allowed memoizes the reviews, the analyzers sharing e.g. list pods.
+	allowed := map[string]bool{}
+	var reviewErr error
+	review := func(p permission, namespace string) bool {
+		key := p.String() + " " + namespace
+		if ok, found := allowed[key]; found {
+			return ok
+		}
+		result, err := reviews.Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   namespace,
+					Verb:        p.verb,
+					Group:       p.group,
+					Resource:    p.resource,
+					Subresource: p.subresource,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			reviewErr = err
+			return true
+		}
+		allowed[key] = result.Status.Allowed
+		return result.Status.Allowed
+	}
+
+	denied := map[string]bool{}
+	// missing lists the permissions missing in the order found, with the
+	// analyzers held back by each of them.
+	var missing []string
+	missingAnalyzers := map[string][]string{}
+	check := func(name string, label string, namespace string) {
+		for _, p := range analyzerPermissions[name] {
+			if reviewErr != nil {
+				return
+			}
+			// Permitted in all namespaces is permitted in each of them.
+			if namespace != "" && !clusterScopedAnalyzers[name] && review(p, "") {
+				continue
+			}
+			if review(p, namespace) {
+				continue
+			}
+			denied[label] = true
+			description := "cannot " + p.String()
+			switch {
+			case clusterScopedAnalyzers[name]:
+			case namespace == "":
+				description += " in all namespaces"
+			default:
+				description += " in namespace " + namespace
+			}
+			if _, found := missingAnalyzers[description]; !found {
+				missing = append(missing, description)
+			}
+			missingAnalyzers[description] = append(missingAnalyzers[description], name)
+		}
+	}
+	for _, name := range names {
+		switch {
+		case clusterScopedAnalyzers[name]:
+			check(name, name, "")
+		case namespaces == nil:
+			check(name, name, a.Namespace)
+		default:
+			for _, namespace := range namespaces {
+				check(name, fmt.Sprintf("%s (%s)", name, namespace), namespace)
+			}
+		}
+	}
+	if reviewErr != nil {
+		a.addError("Permissions", PhaseAnalysis, fmt.Errorf("reviewing the permissions of the analyzers, analyzers run unchecked: %w", reviewErr))
+		return nil
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	for index, description := range missing {
+		missing[index] = fmt.Sprintf("%s (%s)", description, strings.Join(missingAnalyzers[description], ", "))
+	}
+	a.addError("Permissions", PhaseAnalysis, fmt.Errorf("analyzers not run for lack of permissions, grant them or pass --no-permission-check: %s", strings.Join(missing, "; ")))
+	return denied
+}
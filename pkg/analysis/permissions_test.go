@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// pendingPodClientset has a pod which can't be scheduled.
+func pendingPodClientset() *fake.Clientset {
+	return fake.NewSimpleClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+		Status:     v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}}},
+	})
+}
+
+// deniedClient is a client with a pending pod, which may do anything but the
+// denied resources. It counts the reviews.
+func deniedClient(reviews *int, denied ...string) *kubernetes.Client {
+	clientset := pendingPodClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = true
+		for _, resource := range denied {
+			if review.Spec.ResourceAttributes.Resource == resource {
+				review.Status.Allowed = false
+			}
+		}
+		return true, review, nil
+	})
+	return &kubernetes.Client{Client: clientset}
+}
+
+// Test: the analyzers missing permissions aren't run and are reported in a single error
+func TestAnalysis_CheckPermissions(t *testing.T) {
+	var reviews int
+	a := &Analysis{
+		Context:          context.Background(),
+		Client:           deniedClient(&reviews, "pods", "nodes"),
+		Namespace:        "default",
+		Filters:          []string{"Pod", "Log", "Deployment", "Node"},
+		MaxConcurrency:   1,
+		CheckPermissions: true,
+	}
+	a.RunAnalysis()
+
+	require.Empty(t, a.Results)
+	require.Len(t, a.Errors, 1)
+	require.Equal(t, "Permissions", a.Errors[0].Analyzer)
+	require.Equal(t, "[Permissions] analyzers not run for lack of permissions, grant them or pass --no-permission-check: "+
+		"cannot list pods in namespace default (Pod, Log); cannot get pods/log in namespace default (Log); cannot list nodes (Node)", a.Errors[0].Error())
+	// list pods in all namespaces and in default, reviewed once for Pod and
+	// Log, get pods/log twice, list deployments.apps once and list nodes.
+	require.Equal(t, 6, reviews)
+}
+
+// Test: the permitted analyzers run as before, and no review is made without CheckPermissions
+func TestAnalysis_CheckPermissionsAllowed(t *testing.T) {
+	for _, check := range []bool{true, false} {
+		var reviews int
+		a := &Analysis{
+			Context:          context.Background(),
+			Client:           deniedClient(&reviews),
+			Namespace:        "default",
+			Filters:          []string{"Pod"},
+			MaxConcurrency:   1,
+			CheckPermissions: check,
+		}
+		a.RunAnalysis()
+
+		require.Empty(t, a.Errors)
+		require.Len(t, a.Results, 1)
+		if check {
+			require.Equal(t, 1, reviews)
+		} else {
+			require.Zero(t, reviews)
+		}
+	}
+}
+
+// Test: the analyzers are run unchecked when the permissions can't be reviewed
+func TestAnalysis_CheckPermissionsReviewFailure(t *testing.T) {
+	clientset := pendingPodClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	a := &Analysis{
+		Context:          context.Background(),
+		Client:           &kubernetes.Client{Client: clientset},
+		Namespace:        "default",
+		Filters:          []string{"Pod"},
+		MaxConcurrency:   1,
+		CheckPermissions: true,
+	}
+	a.RunAnalysis()
+
+	require.Len(t, a.Errors, 1)
+	require.EqualError(t, a.Errors[0], "[Permissions] reviewing the permissions of the analyzers, analyzers run unchecked: forbidden")
+	require.Len(t, a.Results, 1)
+}
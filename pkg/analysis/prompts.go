@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const promptTemplateExt = ".tmpl"
+
+// rawPromptType is the prompt wrapping the others for the custom REST
+// provider.
+const rawPromptType = "raw"
+
+// promptVariable matches the named placeholders of the prompts, e.g.
+// ${cluster}, and the escaped ones, e.g. $${cluster} for a literal ${cluster}.
+var promptVariable = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolatePrompt replaces the ${name} placeholders of the prompt template
+// by the value of name in vars, or else of the environment variable. The
+// values are escaped for the positional %s placeholders filled afterwards. It
+// returns an error listing the variables which are defined nowhere.
+func interpolatePrompt(template string, vars map[string]string) (string, error) {
+	var undefined []string
+	interpolated := promptVariable.ReplaceAllStringFunc(template, func(placeholder string) string {
+		if strings.HasPrefix(placeholder, "$$") {
+			return placeholder[1:]
+		}
+		name := promptVariable.FindStringSubmatch(placeholder)[1]
+		value, ok := vars[name]
+		if !ok {
+			// The configuration keys are lower-cased.
+			value, ok = vars[strings.ToLower(name)]
+		}
+		if !ok {
+			value, ok = os.LookupEnv(name)
+		}
+		if !ok {
+			if !slices.Contains(undefined, name) {
+				undefined = append(undefined, name)
+			}
+			return placeholder
+		}
+		return strings.ReplaceAll(value, "%", "%%")
+	})
+	if len(undefined) > 0 {
+		return "", fmt.Errorf("undefined prompt variables %s, set them in ai.prompt_vars or in the environment", strings.Join(undefined, ", "))
+	}
+	return interpolated, nil
+}
+
+// loadPromptTemplates reads the prompt templates of dir, named by the Kind they
+// explain (e.g. Pod.tmpl, or default.tmpl for all the kinds without one), and
+// fills their ${name} placeholders from vars, see interpolatePrompt.
+func loadPromptTemplates(dir string, vars map[string]string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading prompt templates: %w", err)
+	}
+	templates := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != promptTemplateExt {
+			continue
+		}
+		kind := strings.TrimSuffix(entry.Name(), promptTemplateExt)
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading prompt template %s: %w", entry.Name(), err)
+		}
+		template, err := interpolatePrompt(string(data), vars)
+		if err != nil {
+			return nil, fmt.Errorf("prompt template %s: %w", entry.Name(), err)
+		}
+		validate := validatePromptTemplate
+		if kind == rawPromptType {
+			validate = validateRawPromptTemplate
+		}
+		if err := validate(template); err != nil {
+			return nil, fmt.Errorf("prompt template %s: %w", entry.Name(), err)
+		}
+		templates[kind] = template
+	}
+	return templates, nil
+}
+
+// validatePromptTemplate checks that a template has the two %s placeholders
+// filled with the language and the failures, and no other formatting verb.
+func validatePromptTemplate(template string) error {
+	return validatePlaceholders(template, 2, "the language and the failures")
+}
+
+// validateRawPromptTemplate checks that the raw prompt has the three %s
+// placeholders filled with the language, the failures and the prompt it
+// wraps, and no other formatting verb.
+func validateRawPromptTemplate(template string) error {
+	return validatePlaceholders(template, 3, "the language, the failures and the prompt")
+}
+
+func validatePlaceholders(template string, expected int, filledWith string) error {
+	var placeholders int
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			continue
+		}
+		if i+1 == len(template) {
+			return fmt.Errorf("unterminated %% at the end of the template, use %%%% for a literal %%")
+		}
+		i++
+		switch template[i] {
+		case '%':
+		case 's':
+			placeholders++
+		default:
+			return fmt.Errorf("unsupported placeholder %%%c, only %%s (and %%%% for a literal %%) can be used", template[i])
+		}
+	}
+	if placeholders != expected {
+		return fmt.Errorf("expected %d %%s placeholders, for %s, found %d", expected, filledWith, placeholders)
+	}
+	return nil
+}
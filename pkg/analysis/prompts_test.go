@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPromptTemplates(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Pod.tmpl"), []byte("Explain in %s at 100%% certainty: %s"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "default.tmpl"), []byte("%s %s"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600))
+
+	templates, err := loadPromptTemplates(dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"Pod":     "Explain in %s at 100%% certainty: %s",
+		"default": "%s %s",
+	}, templates)
+}
+
+func TestLoadPromptTemplates_Malformed(t *testing.T) {
+	for name, template := range map[string]string{
+		"missing placeholder": "Explain in %s",
+		"extra placeholder":   "%s %s %s",
+		"unsupported verb":    "Explain in %s: %d",
+		"trailing percent":    "Explain in %s: %s 100%",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "Service.tmpl"), []byte(template), 0o600))
+			_, err := loadPromptTemplates(dir, nil)
+			require.ErrorContains(t, err, "prompt template Service.tmpl")
+		})
+	}
+}
+
+func TestLoadPromptTemplates_Raw(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "raw.tmpl"), []byte("%s %s"), 0o600))
+	_, err := loadPromptTemplates(dir, nil)
+	require.ErrorContains(t, err, "prompt template raw.tmpl: expected 3 %s placeholders")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "raw.tmpl"), []byte(`{"lang": "%s", "input": "%s", "instructions": "%s"}`), 0o600))
+	templates, err := loadPromptTemplates(dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, `{"lang": "%s", "input": "%s", "instructions": "%s"}`, templates["raw"])
+}
+
+// Test: the built-in prompts pass the validation of the templates
+func TestValidatePromptTemplate_BuiltIn(t *testing.T) {
+	for kind, template := range ai.PromptMap {
+		if kind == "raw" {
+			require.NoError(t, validateRawPromptTemplate(template), kind)
+			continue
+		}
+		require.NoError(t, validatePromptTemplate(template), kind)
+	}
+}
+
+// Test: the named placeholders are filled from the variables, or else the environment
+func TestInterpolatePrompt(t *testing.T) {
+	t.Setenv("K8SGPT_TEAM", "payments")
+	vars := map[string]string{"cluster": "prod-eu", "budget": "100%"}
+
+	prompt, err := interpolatePrompt("Explain in %s for team ${K8SGPT_TEAM} of ${CLUSTER} at a ${budget} budget, not $${cluster}: %s", vars)
+	require.NoError(t, err)
+	require.Equal(t, "Explain in %s for team payments of prod-eu at a 100%% budget, not ${cluster}: %s", prompt)
+	require.NoError(t, validatePromptTemplate(prompt))
+
+	_, err = interpolatePrompt("${team} of ${cluster} in ${region} and ${team}: %s %s", vars)
+	require.EqualError(t, err, "undefined prompt variables team, region, set them in ai.prompt_vars or in the environment")
+}
+
+// Test: the prompt templates are interpolated before they are validated
+func TestLoadPromptTemplates_Variables(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Pod.tmpl"), []byte("Explain in %s for ${cluster}: %s"), 0o600))
+
+	templates, err := loadPromptTemplates(dir, map[string]string{"cluster": "prod-eu"})
+	require.NoError(t, err)
+	require.Equal(t, "Explain in %s for prod-eu: %s", templates["Pod"])
+
+	_, err = loadPromptTemplates(dir, nil)
+	require.EqualError(t, err, "prompt template Pod.tmpl: undefined prompt variables cluster, set them in ai.prompt_vars or in the environment")
+}
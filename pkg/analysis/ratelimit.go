@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+
+	"golang.org/x/time/rate"
+)
+
+// NewRateLimiter returns a limiter of the AI requests to rps requests per
+// second, nil when rps isn't positive. It doesn't allow bursts, the requests
+// are spread evenly.
+func NewRateLimiter(rps float64) *rate.Limiter {
+	if rps <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(rps), 1)
+}
+
+// waitRateLimit blocks until the RateLimiter allows another AI request, or
+// a.Context is done.
+func (a *Analysis) waitRateLimit() error {
+	if a.RateLimiter == nil {
+		return nil
+	}
+	if err := a.RateLimiter.Wait(a.contextOrBackground()); err != nil {
+		return fmt.Errorf("waiting for the AI rate limit (ai.rps): %w", err)
+	}
+	return nil
+}
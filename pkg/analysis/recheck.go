@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// flaggedRun is a run of an analyzer which found problems, the objects it
+// flagged are rechecked by running it again.
+type flaggedRun struct {
+	analyzer common.IAnalyzer
+	name     string
+	config   common.Analyzer
+	objects  map[string]bool
+}
+
+// flaggedObject identifies the object of a result. The failures aren't part
+// of it, they may change between the checks, e.g. a restart count.
+func flaggedObject(result common.Result) string {
+	return result.Kind + "\x00" + result.Name
+}
+
+// recordFlagged keeps the run of an analyzer to recheck, when RecheckCount is
+// set and it found problems. It is called by the collector of the run.
+func (a *Analysis) recordFlagged(analyzer common.IAnalyzer, name string, config common.Analyzer, results []common.Result) {
+	if a.RecheckCount <= 0 || len(results) == 0 {
+		return
+	}
+	run := flaggedRun{analyzer: analyzer, name: name, config: config, objects: map[string]bool{}}
+	for _, result := range results {
+		run.objects[flaggedObject(result)] = true
+	}
+	a.flagged = append(a.flagged, run)
+}
+
+// recheckResults runs the analyzers which found problems RecheckCount more
+// times, RecheckDelay apart, and drops the results of the objects they no
+// longer flag, e.g. a pod Pending during a rollout. When a recheck fails, the
+// results of that analyzer are kept.
+func (a *Analysis) recheckResults() {
+	flagged := a.flagged
+	a.flagged = nil
+	ctx := a.contextOrBackground()
+	cleared := map[string]bool{}
+	for round := 0; round < a.RecheckCount && len(flagged) > 0; round++ {
+		select {
+		case <-time.After(a.RecheckDelay):
+		case <-ctx.Done():
+			// The results not rechecked yet are kept.
+			return
+		}
+		flagged = a.recheck(ctx, flagged, cleared)
+	}
+
+	results := a.Results[:0]
+	for _, result := range a.Results {
+		if !cleared[flaggedObject(result)] {
+			results = append(results, result)
+		}
+	}
+	if dropped := len(a.Results) - len(results); dropped > 0 {
+		a.logger().Debug("Results cleared on recheck, dropped", "results", dropped)
+	}
+	a.Results = results
+}
+
+// recheck runs the flagged analyzers again, adds the objects they no longer
+// flag to cleared and returns the runs still flagging some.
+func (a *Analysis) recheck(ctx context.Context, flagged []flaggedRun, cleared map[string]bool) []flaggedRun {
+	semaphore := make(chan struct{}, a.concurrency())
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var persistent []flaggedRun
+	for _, run := range flagged {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			results, err := a.reanalyze(ctx, run)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				a.logger().Debug("Recheck failed, results kept", "analyzer", run.name, "error", err)
+				persistent = append(persistent, run)
+				return
+			}
+			found := map[string]bool{}
+			for _, result := range results {
+				found[flaggedObject(result)] = true
+			}
+			still := flaggedRun{analyzer: run.analyzer, name: run.name, config: run.config, objects: map[string]bool{}}
+			for object := range run.objects {
+				if found[object] {
+					still.objects[object] = true
+				} else {
+					cleared[object] = true
+				}
+			}
+			if len(still.objects) > 0 {
+				persistent = append(persistent, still)
+			}
+		}()
+	}
+	wg.Wait()
+	// The objects cleared by a run but flagged by another one are persistent.
+	for _, run := range persistent {
+		for object := range run.objects {
+			delete(cleared, object)
+		}
+	}
+	return persistent
+}
+
+// reanalyze runs a flagged analyzer again, within the AnalyzerTimeout.
+func (a *Analysis) reanalyze(ctx context.Context, run flaggedRun) ([]common.Result, error) {
+	analyzerCtx := ctx
+	if a.AnalyzerTimeout > 0 {
+		var cancel context.CancelFunc
+		analyzerCtx, cancel = context.WithTimeout(ctx, a.AnalyzerTimeout)
+		defer cancel()
+	}
+	config := run.config
+	config.Context = analyzerCtx
+	config.Scanned = &common.ScanCount{}
+	return runUntilDone(analyzerCtx, func() ([]common.Result, error) {
+		return run.analyzer.Analyze(config)
+	})
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+// churnAnalyzer flags its objects[i] on its i-th run, the last ones afterwards.
+type churnAnalyzer struct {
+	mutex   sync.Mutex
+	runs    int
+	objects [][]string
+	err     error
+}
+
+func (c *churnAnalyzer) Analyze(common.Analyzer) ([]common.Result, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	objects := c.objects[min(c.runs, len(c.objects)-1)]
+	c.runs++
+	if c.runs > 1 && c.err != nil {
+		return nil, c.err
+	}
+	var results []common.Result
+	for _, name := range objects {
+		results = append(results, common.Result{Kind: "Pod", Name: name, Error: []common.Failure{{Text: "pending"}}})
+	}
+	return results, nil
+}
+
+func runChurnAnalyzer(t *testing.T, a *Analysis, churn *churnAnalyzer) {
+	semaphore := make(chan struct{}, 1)
+	semaphore <- struct{}{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	c := a.startCollector()
+	a.executeAnalyzer(churn, "Pod", common.Analyzer{Namespace: "default"}, semaphore, &wg, c)
+	c.wait()
+	require.Len(t, a.flagged, 1)
+	a.recheckResults()
+	require.Empty(t, a.flagged)
+}
+
+// Test: the results of the objects cleared on a recheck are dropped
+func TestAnalysis_RecheckResults(t *testing.T) {
+	churn := &churnAnalyzer{objects: [][]string{
+		{"default/rollout", "default/broken", "default/scaling"},
+		{"default/broken", "default/scaling"},
+		{"default/broken"},
+	}}
+	a := &Analysis{Context: context.Background(), RecheckCount: 2, RecheckDelay: time.Millisecond}
+	// The results of other analyzers aren't rechecked.
+	a.Results = []common.Result{{Kind: "Host", Name: "node-1"}}
+	runChurnAnalyzer(t, a, churn)
+
+	require.Equal(t, 3, churn.runs)
+	require.Len(t, a.Results, 2)
+	require.Equal(t, "node-1", a.Results[0].Name)
+	require.Equal(t, "default/broken", a.Results[1].Name)
+}
+
+// Test: the results are kept when the recheck fails
+func TestAnalysis_RecheckResultsError(t *testing.T) {
+	churn := &churnAnalyzer{objects: [][]string{{"default/broken"}, {}}, err: errors.New("forbidden")}
+	a := &Analysis{Context: context.Background(), RecheckCount: 1, RecheckDelay: time.Millisecond}
+	runChurnAnalyzer(t, a, churn)
+
+	require.Equal(t, 2, churn.runs)
+	require.Len(t, a.Results, 1)
+}
+
+// Test: the results are kept when the analysis is cancelled before the recheck
+func TestAnalysis_RecheckResultsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	churn := &churnAnalyzer{objects: [][]string{{"default/broken"}, {}}}
+	a := &Analysis{Context: ctx, RecheckCount: 1, RecheckDelay: time.Hour}
+	semaphore := make(chan struct{}, 1)
+	semaphore <- struct{}{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	c := a.startCollector()
+	a.executeAnalyzer(churn, "Pod", common.Analyzer{Namespace: "default"}, semaphore, &wg, c)
+	c.wait()
+	cancel()
+	a.recheckResults()
+
+	require.Equal(t, 1, churn.runs)
+	require.Len(t, a.Results, 1)
+}
+
+// Test: without RecheckCount the runs aren't kept
+func TestAnalysis_RecheckDisabled(t *testing.T) {
+	a := &Analysis{Context: context.Background()}
+	semaphore := make(chan struct{}, 1)
+	semaphore <- struct{}{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	c := a.startCollector()
+	a.executeAnalyzer(&churnAnalyzer{objects: [][]string{{"default/broken"}}}, "Pod", common.Analyzer{}, semaphore, &wg, c)
+	c.wait()
+	require.Empty(t, a.flagged)
+	require.Len(t, a.Results, 1)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// remediationHeading starts the section of the commands in the answers to the
+// remediation prompt.
+const remediationHeading = "commands:"
+
+// setRemediation adds the commands of an explanation of result to its
+// Remediation, when they were asked for, and returns the explanation without
+// them. The explanation must be unmasked, so they name the actual objects.
+func (a *Analysis) setRemediation(result *common.Result, details string) string {
+	if !a.asksRemediation(result.Kind) {
+		return details
+	}
+	details, commands := extractRemediation(details)
+	result.Remediation = mergeRemediation(result.Remediation, commands)
+	return details
+}
+
+// extractRemediation splits the commands section off an explanation, one
+// command per line. Markdown fences, list markers and shell prompts are
+// dropped.
+func extractRemediation(details string) (string, []common.Remediation) {
+	lines := strings.Split(details, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(strings.ToLower(line), remediationHeading) {
+			continue
+		}
+		// The first command may follow the heading.
+		commands := append([]string{line[len(remediationHeading):]}, lines[i+1:]...)
+		var remediation []common.Remediation
+		for _, command := range commands {
+			command = strings.TrimSpace(command)
+			if strings.HasPrefix(command, "```") {
+				continue
+			}
+			for _, prefix := range []string{"- ", "* ", "$ "} {
+				command = strings.TrimPrefix(command, prefix)
+			}
+			command = strings.TrimSpace(strings.Trim(command, "`"))
+			if command == "" || strings.EqualFold(command, "none") {
+				continue
+			}
+			remediation = append(remediation, common.Remediation{Command: command})
+		}
+		return strings.TrimSpace(strings.Join(lines[:i], "\n")), remediation
+	}
+	return details, nil
+}
+
+// mergeRemediation appends the commands not listed yet to remediation.
+func mergeRemediation(remediation []common.Remediation, commands []common.Remediation) []common.Remediation {
+	for _, command := range commands {
+		listed := false
+		for _, existing := range remediation {
+			listed = listed || existing.Command == command.Command
+		}
+		if !listed {
+			remediation = append(remediation, command)
+		}
+	}
+	return remediation
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util/backoff"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = time.Second
+	// defaultKubernetesMaxRetries and defaultKubernetesRetryBaseDelay retry
+	// the failing reads of the analyzers for about 3.5s.
+	defaultKubernetesMaxRetries     = 3
+	defaultKubernetesRetryBaseDelay = 500 * time.Millisecond
+)
+
+// retryAfterPattern matches the Retry-After hints providers put in error messages,
+// e.g. "Retry-After: 20" or "Please try again in 1.5s".
+var retryAfterPattern = regexp.MustCompile(`(?i)(?:retry[- ]after:?|try again in)\s*([0-9]+(?:\.[0-9]+)?)\s*(ms|s|sec|secs|seconds?)?`)
+
+// retryAfterer is implemented by errors which know how long the provider asked
+// the client to wait before retrying. A zero delay asks to retry right away.
+type retryAfterer interface {
+	RetryAfter() (time.Duration, bool)
+}
+
+// getRetryConfiguration reads the retry settings of the AI phase from the
+// ai.max_retries and ai.retry_base_delay configuration keys. The RequestTimeout
+// is read from ai.request_timeout apart.
+func getRetryConfiguration() (int, time.Duration) {
+	maxRetries := defaultMaxRetries
+	if viper.IsSet("ai.max_retries") {
+		maxRetries = viper.GetInt("ai.max_retries")
+	}
+	baseDelay := defaultRetryBaseDelay
+	if viper.IsSet("ai.retry_base_delay") {
+		baseDelay = viper.GetDuration("ai.retry_base_delay")
+	}
+	return maxRetries, baseDelay
+}
+
+// getKubernetesClientOptions reads the rate limit of the requests to the API
+// server from the qps and burst configuration keys, and the retries of the
+// reads failing transiently from k8s.max_retries and k8s.retry_base_delay.
+func getKubernetesClientOptions() kubernetes.ClientOptions {
+	options := kubernetes.ClientOptions{
+		QPS:            float32(viper.GetFloat64("qps")),
+		Burst:          viper.GetInt("burst"),
+		MaxRetries:     defaultKubernetesMaxRetries,
+		RetryBaseDelay: defaultKubernetesRetryBaseDelay,
+	}
+	if viper.IsSet("k8s.max_retries") {
+		options.MaxRetries = viper.GetInt("k8s.max_retries")
+	}
+	if viper.IsSet("k8s.retry_base_delay") {
+		options.RetryBaseDelay = viper.GetDuration("k8s.retry_base_delay")
+	}
+	return options
+}
+
+// errRequestTimeout wraps the errors of the completions which took longer than
+// the RequestTimeout.
+var errRequestTimeout = errors.New("request timed out")
+
+func isRateLimitError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "status code: 429")
+}
+
+// serverErrorPattern matches the 5xx status codes reported by the AI clients.
+var serverErrorPattern = regexp.MustCompile(`status code: 5[0-9]{2}\b`)
+
+// isUnavailableError reports whether err tells the AI provider is unavailable
+// or unreachable, e.g. a 503 or a refused connection, rather than the request
+// being wrong.
+func isUnavailableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	message := err.Error()
+	return serverErrorPattern.MatchString(message) ||
+		strings.Contains(message, "connection refused") ||
+		strings.Contains(message, "connection reset") ||
+		strings.Contains(message, "no such host")
+}
+
+// retryAfter returns the delay requested by the provider, if any.
+func retryAfter(err error) (time.Duration, bool) {
+	var ra retryAfterer
+	if errors.As(err, &ra) {
+		if delay, ok := ra.RetryAfter(); ok && delay >= 0 {
+			return delay, true
+		}
+	}
+	match := retryAfterPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, false
+	}
+	value, parseErr := strconv.ParseFloat(match[1], 64)
+	if parseErr != nil {
+		return 0, false
+	}
+	if strings.ToLower(match[2]) == "ms" {
+		return time.Duration(value * float64(time.Millisecond)), true
+	}
+	return time.Duration(value * float64(time.Second)), true
+}
+
+// backoffDelay returns how long to wait before the given retry attempt (starting
+// at 0): the provider's Retry-After when present, base * 2^attempt otherwise.
+// A zero Retry-After or base delay retries right away.
+func backoffDelay(err error, baseDelay time.Duration, attempt int) time.Duration {
+	if delay, ok := retryAfter(err); ok {
+		return backoff.Cap(delay)
+	}
+	return backoff.Exponential(baseDelay, attempt)
+}
+
+// getCompletionWithRetry calls the AI client, retrying rate limited (HTTP 429)
+// and timed out requests with jittered exponential backoff, unless the
+// provider said when to retry. Waiting is interrupted when
+// a.Context is done.
+func (a *Analysis) getCompletionWithRetry(client ai.IAI, prompt string) (string, ai.TokenUsage, error) {
+	for attempt := 0; ; attempt++ {
+		response, usage, err := a.getCompletion(client, prompt)
+		timedOut := errors.Is(err, errRequestTimeout)
+		if !isRateLimitError(err) && !timedOut || attempt >= a.MaxRetries {
+			return response, usage, err
+		}
+		delay := backoffDelay(err, a.RetryBaseDelay, attempt)
+		if _, ok := retryAfter(err); !ok {
+			delay = backoff.WithJitter(delay)
+		}
+		message := "AI provider rate limited the request, retrying"
+		if timedOut {
+			message = "AI provider didn't answer in time, retrying"
+		}
+		a.logger().Debug(message, "provider", client.GetName(), "delay", delay, "attempt", attempt+1, "maxRetries", a.MaxRetries)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-a.contextOrBackground().Done():
+			timer.Stop()
+			return "", ai.TokenUsage{}, fmt.Errorf("%w (retry interrupted: %v)", err, a.contextOrBackground().Err())
+		}
+	}
+}
+
+// streamInterruptedMarker follows the chunks streamed by a failed completion,
+// so that they aren't read along with the ones of the retry or fallback.
+const streamInterruptedMarker = "\n[stream interrupted, the partial explanation above is discarded]\n"
+
+// getCompletion streams the completion to a.onChunk while it's set. The system
+// portion of the prompt is sent apart to the clients supporting it. The
+// completion waits for the RateLimiter and is cancelled after the
+// RequestTimeout, if any.
+func (a *Analysis) getCompletion(client ai.IAI, prompt string) (string, ai.TokenUsage, error) {
+	if err := a.waitRateLimit(); err != nil {
+		return "", ai.TokenUsage{}, err
+	}
+	start := time.Now()
+	var response string
+	var usage ai.TokenUsage
+	var err error
+	ctx := a.contextOrBackground()
+	if a.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.RequestTimeout)
+		defer cancel()
+	}
+	if a.confidenceRequested() {
+		ctx = ai.WithConfidence(ctx)
+	}
+	if a.onChunk != nil {
+		onChunk := a.onChunk
+		var streamed bool
+		response, usage, err = ai.GetCompletionStream(ctx, client, prompt, func(chunk string) {
+			streamed = true
+			onChunk(chunk)
+		})
+		if err != nil && streamed {
+			onChunk(streamInterruptedMarker)
+		}
+	} else {
+		response, usage, err = ai.GetCompletionWithSystem(ctx, client, prompt)
+	}
+	a.metrics().AICallCompleted(client.GetName(), time.Since(start), err != nil)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && a.contextOrBackground().Err() == nil {
+		// Not wrapping the deadline lets the fallback providers be tried.
+		err = fmt.Errorf("%w after %s (ai.request_timeout): %v", errRequestTimeout, a.RequestTimeout, err)
+	}
+	return response, usage, err
+}
+
+func (a *Analysis) contextOrBackground() context.Context {
+	if a.Context == nil {
+		return context.Background()
+	}
+	return a.Context
+}
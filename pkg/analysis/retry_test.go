@@ -0,0 +1,207 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util/backoff"
+	"github.com/stretchr/testify/require"
+)
+
+// rateLimitedAIClient fails with a 429 error for the first `failures` calls.
+type rateLimitedAIClient struct {
+	ai.NoOpAIClient
+	failures int
+	calls    int
+	err      error
+}
+
+func (c *rateLimitedAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	c.calls++
+	if c.calls <= c.failures {
+		return "", c.err
+	}
+	return c.NoOpAIClient.GetCompletion(ctx, prompt)
+}
+
+var errRateLimited = errors.New("error, status code: 429, status: 429 Too Many Requests, message: Rate limit reached")
+
+func TestGetCompletionWithRetry(t *testing.T) {
+	client := &rateLimitedAIClient{failures: 2, err: errRateLimited}
+	a := Analysis{
+		Context:        context.Background(),
+		AIClient:       client,
+		MaxRetries:     3,
+		RetryBaseDelay: time.Millisecond,
+	}
+	response, _, err := a.getCompletionWithRetry(client, "prompt")
+	require.NoError(t, err)
+	require.Equal(t, "I am a noop response to the prompt prompt", response)
+	require.Equal(t, 3, client.calls)
+}
+
+func TestGetCompletionWithRetry_Exhausted(t *testing.T) {
+	client := &rateLimitedAIClient{failures: 10, err: errRateLimited}
+	a := Analysis{
+		Context:        context.Background(),
+		AIClient:       client,
+		MaxRetries:     2,
+		RetryBaseDelay: time.Millisecond,
+	}
+	_, _, err := a.getCompletionWithRetry(client, "prompt")
+	require.ErrorIs(t, err, errRateLimited)
+	require.Equal(t, 3, client.calls)
+}
+
+func TestGetCompletionWithRetry_NotRateLimited(t *testing.T) {
+	otherErr := errors.New("error, status code: 500")
+	client := &rateLimitedAIClient{failures: 10, err: otherErr}
+	a := Analysis{
+		Context:        context.Background(),
+		AIClient:       client,
+		MaxRetries:     2,
+		RetryBaseDelay: time.Millisecond,
+	}
+	_, _, err := a.getCompletionWithRetry(client, "prompt")
+	require.ErrorIs(t, err, otherErr)
+	require.Equal(t, 1, client.calls)
+}
+
+func TestGetCompletionWithRetry_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := &rateLimitedAIClient{failures: 10, err: errRateLimited}
+	a := Analysis{
+		Context:        ctx,
+		AIClient:       client,
+		MaxRetries:     5,
+		RetryBaseDelay: time.Hour,
+	}
+	_, _, err := a.getCompletionWithRetry(client, "prompt")
+	require.ErrorIs(t, err, errRateLimited)
+	require.ErrorContains(t, err, "retry interrupted")
+	require.Equal(t, 1, client.calls)
+}
+
+// hangingAIClient doesn't answer the first `hangs` calls until they are
+// cancelled.
+type hangingAIClient struct {
+	ai.NoOpAIClient
+	hangs int
+	calls int
+}
+
+func (c *hangingAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	c.calls++
+	if c.calls <= c.hangs {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return c.NoOpAIClient.GetCompletion(ctx, prompt)
+}
+
+func TestGetCompletionWithRetry_RequestTimeout(t *testing.T) {
+	client := &hangingAIClient{hangs: 1}
+	a := Analysis{
+		Context:        context.Background(),
+		AIClient:       client,
+		MaxRetries:     3,
+		RetryBaseDelay: time.Millisecond,
+		RequestTimeout: 10 * time.Millisecond,
+	}
+	response, _, err := a.getCompletionWithRetry(client, "prompt")
+	require.NoError(t, err)
+	require.Equal(t, "I am a noop response to the prompt prompt", response)
+	require.Equal(t, 2, client.calls)
+
+	// The timeouts are reported apart from the rate limits and the
+	// cancellation of the analysis, so that the fallbacks are tried.
+	client = &hangingAIClient{hangs: 10}
+	a.AIClient = client
+	a.MaxRetries = 1
+	_, _, err = a.getCompletionWithRetry(client, "prompt")
+	require.ErrorIs(t, err, errRequestTimeout)
+	require.NotErrorIs(t, err, context.DeadlineExceeded)
+	require.True(t, shouldFallback(err))
+	require.ErrorContains(t, err, "request timed out after 10ms (ai.request_timeout): context deadline exceeded")
+	require.Equal(t, 2, client.calls)
+}
+
+func TestAnalysis_ExplainRequestTimeout(t *testing.T) {
+	a := Analysis{
+		Context:        context.Background(),
+		AIClient:       &hangingAIClient{hangs: 10},
+		Cache:          newMemoryCache(),
+		Language:       "english",
+		PromptMap:      map[string]string{"default": "%s %s"},
+		RequestTimeout: 10 * time.Millisecond,
+		Results:        []common.Result{{Kind: "Pod", Name: "default/crash", Error: []common.Failure{{Text: "crash loop"}}}},
+	}
+	err := a.explainResults(false, false)
+	require.EqualError(t, err, "timed out waiting for AI provider noopai: request timed out after 10ms (ai.request_timeout): context deadline exceeded")
+}
+
+func TestBackoffDelay(t *testing.T) {
+	require.Equal(t, 100*time.Millisecond, backoffDelay(errRateLimited, 100*time.Millisecond, 0))
+	require.Equal(t, 400*time.Millisecond, backoffDelay(errRateLimited, 100*time.Millisecond, 2))
+	require.Equal(t, 20*time.Second, backoffDelay(errors.New("status code: 429, Retry-After: 20"), time.Millisecond, 0))
+	require.Equal(t, 1500*time.Millisecond, backoffDelay(errors.New("status code: 429, Please try again in 1.5s."), time.Millisecond, 3))
+	require.Equal(t, backoff.MaxDelay, backoffDelay(errRateLimited, time.Minute, 10))
+	require.Equal(t, backoff.MaxDelay, backoffDelay(errors.New("status code: 429, Retry-After: 3600"), time.Second, 0))
+	// A zero Retry-After or base delay retries right away.
+	require.Zero(t, backoffDelay(errors.New("status code: 429, Retry-After: 0"), time.Second, 0))
+	require.Zero(t, backoffDelay(errors.New("status code: 429, Please try again in 0s."), time.Second, 2))
+	require.Zero(t, backoffDelay(&webhookStatusError{statusCode: 429, hasRetryAfter: true}, time.Second, 1))
+	require.Zero(t, backoffDelay(errRateLimited, 0, 3))
+}
+
+// Test: the requests are spaced by the RateLimiter, retries included
+func TestGetCompletionRateLimited(t *testing.T) {
+	require.Nil(t, NewRateLimiter(0))
+	client := &rateLimitedAIClient{failures: 1, err: errRateLimited}
+	a := Analysis{
+		Context:        context.Background(),
+		AIClient:       client,
+		MaxRetries:     1,
+		RetryBaseDelay: time.Nanosecond,
+		RateLimiter:    NewRateLimiter(20),
+	}
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		_, _, err := a.getCompletionWithRetry(client, "prompt")
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, client.calls)
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+// Test: waiting for the RateLimiter stops with the analysis
+func TestGetCompletionRateLimitedCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &rateLimitedAIClient{}
+	a := Analysis{Context: ctx, AIClient: client, RateLimiter: NewRateLimiter(0.001)}
+	_, _, err := a.getCompletion(client, "prompt")
+	require.NoError(t, err)
+	cancel()
+	_, _, err = a.getCompletion(client, "prompt")
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorContains(t, err, "waiting for the AI rate limit (ai.rps)")
+	require.Equal(t, 1, client.calls)
+}
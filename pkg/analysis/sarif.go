@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// Minimal subset of the SARIF 2.1.0 object model needed to report k8sgpt
+// findings, see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifLevel maps the severity of a result to a SARIF result level.
+func sarifLevel(result common.Result) string {
+	switch result.Severity {
+	case common.SeverityCritical:
+		return "error"
+	case common.SeverityInfo:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// sarifOutput converts the results into a single SARIF run where every analyzer
+// (result Kind) is a rule and every failure is a SARIF result located at the
+// <Kind>/<namespace>/<name> of the affected object.
+func (a *Analysis) sarifOutput() ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "k8sgpt",
+				InformationURI: "https://k8sgpt.ai",
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	ruleIndex := map[string]int{}
+	for _, result := range a.Results {
+		index, ok := ruleIndex[result.Kind]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[result.Kind] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               result.Kind,
+				Name:             result.Kind,
+				ShortDescription: sarifMessage{Text: fmt.Sprintf("Problems detected by the %s analyzer", result.Kind)},
+			})
+		}
+		for _, failure := range result.Error {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    result.Kind,
+				RuleIndex: index,
+				Level:     sarifLevel(result),
+				Message:   sarifMessage{Text: failure.Text},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: fmt.Sprintf("%s/%s", result.Kind, result.Name)},
+					},
+				}},
+			})
+		}
+	}
+
+	output, err := json.MarshalIndent(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling sarif: %v", err)
+	}
+	return output, nil
+}
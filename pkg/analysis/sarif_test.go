@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSarifOutput(t *testing.T) {
+	a := &Analysis{
+		Results: []common.Result{
+			{
+				Kind:  "Pod",
+				Name:  "default/pod-1",
+				Error: []common.Failure{{Text: "first"}, {Text: "second"}},
+			},
+			{
+				Kind:  "Service",
+				Name:  "default/svc",
+				Error: []common.Failure{{Text: "no endpoints"}},
+			},
+			{
+				Kind:     "Pod",
+				Name:     "kube-system/pod-2",
+				Error:    []common.Failure{{Text: "third"}},
+				Severity: common.SeverityCritical,
+			},
+		},
+	}
+
+	output, err := a.PrintOutput("sarif")
+	require.NoError(t, err)
+
+	var got sarifLog
+	require.NoError(t, json.Unmarshal(output, &got))
+	require.Equal(t, sarifVersion, got.Version)
+	require.Len(t, got.Runs, 1)
+
+	run := got.Runs[0]
+	require.Equal(t, []string{"Pod", "Service"}, []string{run.Tool.Driver.Rules[0].ID, run.Tool.Driver.Rules[1].ID})
+	require.Len(t, run.Results, 4)
+	require.Equal(t, "Pod", run.Results[3].RuleID)
+	require.Equal(t, 0, run.Results[3].RuleIndex)
+	require.Equal(t, "third", run.Results[3].Message.Text)
+	require.Equal(t, "error", run.Results[3].Level)
+	require.Equal(t, "warning", run.Results[0].Level)
+	require.Equal(t, "Pod/kube-system/pod-2", run.Results[3].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestSarifOutputNoProblems(t *testing.T) {
+	output, err := (&Analysis{}).PrintOutput("sarif")
+	require.NoError(t, err)
+	require.Contains(t, string(output), `"results": []`)
+	require.Contains(t, string(output), `"rules": []`)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// OutputSchemaID identifies the JSON Schema of the JSON output.
+const OutputSchemaID = "https://k8sgpt.ai/schemas/analysis-output.json"
+
+// schemaNames are the names of the definitions of the types encoded by their
+// JSON methods, after the types the encoding stands for.
+var schemaNames = map[string]string{
+	"analyzerErrorJSON": "AnalyzerError",
+	"analysisStatsJSON": "AnalysisStats",
+}
+
+// OutputSchema returns the JSON Schema of JsonOutput, the document of the JSON
+// output. The fields without omitempty are required, and as the empty lists
+// are encoded as null, the required lists may be null.
+func OutputSchema() ([]byte, error) {
+	reflector := jsonschema.Reflector{
+		// JsonOutput is the root rather than a reference to its definition,
+		// which the draft 7 validators would ignore the definitions next to.
+		ExpandedStruct: true,
+		Namer: func(t reflect.Type) string {
+			if name, ok := schemaNames[t.Name()]; ok {
+				return name
+			}
+			return t.Name()
+		},
+	}
+	schema := reflector.Reflect(&JsonOutput{})
+	// Only the draft 7 keywords are used, so that more validators read it.
+	schema.Version = "http://json-schema.org/draft-07/schema#"
+	schema.ID = OutputSchemaID
+	schema.Title = "k8sgpt analysis output"
+	allowNullLists(schema)
+	for name, definition := range schema.Definitions {
+		if definition.Type == "array" {
+			// The list types, e.g. AnalysisErrors.
+			schema.Definitions[name] = nullable(definition)
+			continue
+		}
+		allowNullLists(definition)
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func allowNullLists(definition *jsonschema.Schema) {
+	for _, required := range definition.Required {
+		if property, ok := definition.Properties.Get(required); ok && property.Type == "array" {
+			definition.Properties.Set(required, nullable(property))
+		}
+	}
+}
+
+func nullable(schema *jsonschema.Schema) *jsonschema.Schema {
+	return &jsonschema.Schema{AnyOf: []*jsonschema.Schema{schema, {Type: "null"}}}
+}
+
+// ValidateOutput checks a document of the JSON output against OutputSchema,
+// e.g. to catch the changes of the results breaking the consumers.
+func ValidateOutput(document []byte) error {
+	schema, err := OutputSchema()
+	if err != nil {
+		return err
+	}
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(document))
+	if err != nil {
+		return fmt.Errorf("validating the output: %w", err)
+	}
+	if result.Valid() {
+		return nil
+	}
+	violations := make([]string, 0, len(result.Errors()))
+	for _, violation := range result.Errors() {
+		violations = append(violations, violation.String())
+	}
+	return errors.New("the output doesn't match the schema: " + strings.Join(violations, "; "))
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+// Test: the schema only changes along with testdata/output.schema.json, so that
+// the changes of the output contract are deliberate.
+func TestOutputSchema(t *testing.T) {
+	schema, err := OutputSchema()
+	require.NoError(t, err)
+	expected, err := os.ReadFile("testdata/output.schema.json")
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(schema), "the output schema changed, if on purpose run: go run . schema > pkg/analysis/testdata/output.schema.json")
+}
+
+func TestValidateOutput(t *testing.T) {
+	confidence := 0.9
+	objects := 3
+	a := Analysis{
+		Results: []common.Result{{
+			Kind:        "Pod",
+			Name:        "default/crash",
+			Error:       []common.Failure{{Text: "crash loop", Sensitive: []common.Sensitive{{Unmasked: "crash", Masked: "xxxxx"}}}},
+			Details:     "Error: the container exits",
+			Provider:    "openai",
+			Severity:    common.SeverityCritical,
+			DetectedBy:  2,
+			Confidence:  &confidence,
+			Remediation: []common.Remediation{{Command: "kubectl logs crash -n default --previous", Description: "Show the logs"}},
+		}},
+		Errors:           AnalysisErrors{{Analyzer: "Service", Phase: PhaseAnalysis, Namespace: "default", Partial: true, Err: errors.New("forbidden")}},
+		SkippedAnalyzers: []string{"Node"},
+		WithStats:        true,
+		Stats:            []common.AnalysisStats{{Analyzer: "Pod", DurationTime: time.Second, ObjectsScanned: &objects, Problems: 1}},
+		Previous:         &JsonOutput{},
+	}
+	output, err := a.jsonOutput()
+	require.NoError(t, err)
+	require.NoError(t, ValidateOutput(output))
+
+	// The empty lists are encoded as null.
+	output, err = (&Analysis{}).jsonOutput()
+	require.NoError(t, err)
+	require.NoError(t, ValidateOutput(output))
+
+	err = ValidateOutput([]byte(`{"provider": "openai", "errors": null, "status": "OK", "problems": "none", "results": [], "unknown": true}`))
+	require.ErrorContains(t, err, "the output doesn't match the schema: ")
+	require.ErrorContains(t, err, "problems: Invalid type. Expected: integer, given: string")
+	require.ErrorContains(t, err, "Additional property unknown is not allowed")
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// sessionVersion is the version of the session files, bumped when the older
+// ones can't be read anymore.
+const sessionVersion = 1
+
+// Session is the state of a completed analysis saved by SaveSession, enough to
+// render it in any output format without the cluster or the AI provider.
+type Session struct {
+	Version int        `json:"version"`
+	Output  JsonOutput `json:"output"`
+	// Explained tells whether the AI provider was used.
+	Explained bool `json:"explained"`
+	// Stats and Cache are kept whether or not the output includes them.
+	Stats []common.AnalysisStats `json:"stats,omitempty"`
+	Cache CacheStats             `json:"cache"`
+	// CappedResults are the results dropped by MaxProblems.
+	CappedResults   []common.Result `json:"cappedResults,omitempty"`
+	Previous        *JsonOutput     `json:"previous,omitempty"`
+	ExecutionBudget time.Duration   `json:"executionBudget,omitempty"`
+}
+
+// SaveSession writes the results, errors and stats of the analysis to a
+// session file, to render them later with LoadSession, e.g. in another output
+// format.
+func (a *Analysis) SaveSession(path string) error {
+	session := Session{
+		Version:         sessionVersion,
+		Output:          a.getJsonOutput(),
+		Explained:       a.Explain,
+		Stats:           a.Stats,
+		Cache:           a.CacheStats(),
+		CappedResults:   a.cappedResults,
+		Previous:        a.Previous,
+		ExecutionBudget: a.ExecutionBudget,
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling session: %v", err)
+	}
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("writing session to %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadSession reads a session file written by SaveSession. The analysis it
+// returns only renders the output, it has neither a Kubernetes client nor an
+// AI client.
+func LoadSession(path string) (*Analysis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading session: %w", err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("decoding session %s: %w", path, err)
+	}
+	if session.Version != sessionVersion {
+		return nil, fmt.Errorf("session %s is of version %d, only version %d is supported", path, session.Version, sessionVersion)
+	}
+	return &Analysis{
+		AnalysisAIProvider: session.Output.Provider,
+		Results:            session.Output.Results,
+		Errors:             session.Output.Errors,
+		SkippedAnalyzers:   session.Output.SkippedAnalyzers,
+		Explain:            session.Explained,
+		WithStats:          session.Output.Stats != nil,
+		Stats:              session.Stats,
+		cacheStats:         session.Cache,
+		cappedResults:      session.CappedResults,
+		truncated:          session.Output.TruncatedResults,
+		Previous:           session.Previous,
+		ExecutionBudget:    session.ExecutionBudget,
+		noAnalyzers:        session.Output.Status == StateNoAnalyzers,
+	}, nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+// Test: a reloaded session renders the same outputs as the analysis saved
+func TestAnalysis_SaveLoadSession(t *testing.T) {
+	objects := 4
+	a := &Analysis{
+		AnalysisAIProvider: "openai",
+		Explain:            true,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/crash", Error: []common.Failure{{Text: "crash loop"}}, Details: "Error: the container exits", Severity: common.SeverityCritical},
+			{Kind: "Service", Name: "default/web", Error: []common.Failure{{Text: "no endpoints"}}},
+		},
+		Errors:           AnalysisErrors{{Analyzer: "Node", Phase: PhaseAnalysis, Err: errors.New("forbidden")}},
+		SkippedAnalyzers: []string{"Ingress"},
+		Stats:            []common.AnalysisStats{{Analyzer: "Pod", DurationTime: 1500 * time.Millisecond, ObjectsScanned: &objects, Problems: 1, PromptTokens: 42}},
+		cacheStats:       CacheStats{Hits: 1, Misses: 1},
+		MaxProblems:      1,
+		Previous:         &JsonOutput{Results: []common.Result{{Kind: "Pod", Name: "default/gone", Error: []common.Failure{{Text: "pending"}}}}},
+	}
+	a.capResults()
+	path := filepath.Join(t.TempDir(), "sessions", "run.json")
+	require.NoError(t, a.SaveSession(path))
+
+	loaded, err := LoadSession(path)
+	require.NoError(t, err)
+	for _, format := range []string{"json", "sarif", "junit", "text"} {
+		expected, err := a.PrintOutput(format)
+		require.NoError(t, err)
+		output, err := loaded.PrintOutput(format)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(output), format)
+	}
+	require.Equal(t, a.PrintStats(), loaded.PrintStats())
+	require.Equal(t, 2, loaded.totalResults())
+	require.NotNil(t, loaded.Diff())
+}
+
+func TestLoadSession_Errors(t *testing.T) {
+	_, err := LoadSession(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "reading session: ")
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 2, "output": {}}`), 0o600))
+	_, err = LoadSession(path)
+	require.EqualError(t, err, "session "+path+" is of version 2, only version 1 is supported")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": `), 0o600))
+	_, err = LoadSession(path)
+	require.ErrorContains(t, err, "decoding session "+path)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// OutputSink receives the output of an analysis, e.g. to print it or to send it
+// to another system.
+type OutputSink interface {
+	Write(output JsonOutput) error
+}
+
+// TextSink writes the text output to Writer, the standard output when nil.
+type TextSink struct {
+	Writer io.Writer
+	// Explain tells whether the results were explained, see Analysis.Explain.
+	Explain bool
+	// GroupBy groups the results, see Analysis.GroupBy.
+	GroupBy string
+	// ExecutionBudget is reported along with the skipped analyzers.
+	ExecutionBudget time.Duration
+	// KindsShown tells whether the results were filtered by
+	// Analysis.ShowKinds.
+	KindsShown bool
+}
+
+func (s *TextSink) Write(output JsonOutput) error {
+	text := renderText(output, s.Explain, s.GroupBy, s.ExecutionBudget, s.KindsShown)
+	_, err := fmt.Fprintln(writerOrStdout(s.Writer), string(text))
+	return err
+}
+
+// JSONSink writes the JSON output to Writer, the standard output when nil.
+type JSONSink struct {
+	Writer io.Writer
+}
+
+func (s *JSONSink) Write(output JsonOutput) error {
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling json: %v", err)
+	}
+	_, err = fmt.Fprintln(writerOrStdout(s.Writer), string(data))
+	return err
+}
+
+// FileSink writes the JSON output to the file at Path, replacing its content
+// atomically.
+type FileSink struct {
+	Path string
+}
+
+func (s *FileSink) Write(output JsonOutput) error {
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling json: %v", err)
+	}
+	if err := writeFileAtomic(s.Path, data); err != nil {
+		return fmt.Errorf("writing output to %s: %w", s.Path, err)
+	}
+	return nil
+}
+
+// FormatSink writes Analysis in Format, one of the output formats of
+// PrintOutput, to the file at Path, or to Writer, the standard output when
+// nil, when Path is empty. The text written to the file has no colors. Unlike
+// the other sinks it renders Analysis rather than the output it receives, most
+// formats needing more than the JsonOutput.
+type FormatSink struct {
+	Analysis *Analysis
+	Format   string
+	Writer   io.Writer
+	Path     string
+}
+
+func (s *FormatSink) Write(JsonOutput) error {
+	data, err := s.Analysis.renderOutput(s.Format, s.Path != "")
+	if err != nil {
+		return err
+	}
+	if s.Path == "" {
+		_, err = fmt.Fprintln(writerOrStdout(s.Writer), string(data))
+		return err
+	}
+	if err := writeFileAtomic(s.Path, append(data, '\n')); err != nil {
+		return fmt.Errorf("writing output to %s: %w", s.Path, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path, creating the
+// parent directories, then renames it to path, so that an interrupted run
+// never leaves a partial file behind.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func writerOrStdout(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
+
+// WriteOutputFile writes the output of the analysis in format to the file at
+// path instead of the standard output, see writeFileAtomic. The text output is
+// written without colors.
+func (a *Analysis) WriteOutputFile(path string, format string) error {
+	return (&FormatSink{Analysis: a, Format: format, Path: path}).Write(a.getJsonOutput())
+}
+
+// renderOutput renders the analysis in format, without colors when noColor is
+// set.
+func (a *Analysis) renderOutput(format string, noColor bool) ([]byte, error) {
+	if noColor {
+		saved := color.NoColor
+		color.NoColor = true
+		defer func() { color.NoColor = saved }()
+	}
+	return a.PrintOutput(format)
+}
+
+// WriteOutput writes the output of the analysis to the Sink, or in the text
+// format to the standard output when there's none.
+func (a *Analysis) WriteOutput() error {
+	sink := a.Sink
+	if sink == nil {
+		sink = &FormatSink{Analysis: a, Format: "text"}
+	}
+	return sink.Write(a.getJsonOutput())
+}
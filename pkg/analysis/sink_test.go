@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newSinkAnalysis() *Analysis {
+	return &Analysis{
+		Explain:            true,
+		AnalysisAIProvider: "openai",
+		GroupBy:            GroupByNamespace,
+		Errors:             AnalysisErrors{{Analyzer: "Pod", Phase: PhaseAnalysis, Err: errors.New("forbidden")}},
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "crash loop"}}, Details: "explanation"},
+		},
+	}
+}
+
+func TestTextSink(t *testing.T) {
+	a := newSinkAnalysis()
+	var buf bytes.Buffer
+	sink := &TextSink{Writer: &buf, Explain: a.Explain, GroupBy: a.GroupBy, ExecutionBudget: a.ExecutionBudget}
+	require.NoError(t, sink.Write(a.getJsonOutput()))
+
+	text, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Equal(t, string(text)+"\n", buf.String())
+}
+
+func TestTextSink_NotExplained(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, (&TextSink{Writer: &buf}).Write(JsonOutput{}))
+	require.Contains(t, buf.String(), "AI Provider: AI not used; --explain not set")
+	require.Contains(t, buf.String(), "No problems detected")
+}
+
+// Test: the FormatSink writes any output format, e.g. the summary
+func TestFormatSink(t *testing.T) {
+	a := newSinkAnalysis()
+	var buf bytes.Buffer
+	require.NoError(t, (&FormatSink{Analysis: a, Format: "summary", Writer: &buf}).Write(a.getJsonOutput()))
+
+	summary, err := a.PrintOutput("summary")
+	require.NoError(t, err)
+	require.Equal(t, string(summary)+"\n", buf.String())
+
+	path := filepath.Join(t.TempDir(), "analysis.xml")
+	require.NoError(t, (&FormatSink{Analysis: a, Format: "junit", Path: path}).Write(a.getJsonOutput()))
+	junit, err := a.PrintOutput("junit")
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(junit)+"\n", string(data))
+
+	require.ErrorContains(t, (&FormatSink{Analysis: a, Format: "xml"}).Write(a.getJsonOutput()), "unsupported output format")
+}
+
+func TestJSONSink(t *testing.T) {
+	a := newSinkAnalysis()
+	var buf bytes.Buffer
+	require.NoError(t, (&JSONSink{Writer: &buf}).Write(a.getJsonOutput()))
+
+	data, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	require.Equal(t, string(data)+"\n", buf.String())
+}
+
+func TestFileSink(t *testing.T) {
+	a := newSinkAnalysis()
+	path := filepath.Join(t.TempDir(), "analysis.json")
+	require.NoError(t, os.WriteFile(path, []byte("previous content"), 0o644))
+	require.NoError(t, (&FileSink{Path: path}).Write(a.getJsonOutput()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got JsonOutput
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, a.getJsonOutput(), got)
+
+	// The missing parent directories are created.
+	nested := filepath.Join(t.TempDir(), "missing", "analysis.json")
+	require.NoError(t, (&FileSink{Path: nested}).Write(a.getJsonOutput()))
+	require.FileExists(t, nested)
+
+	err = (&FileSink{Path: filepath.Join(path, "analysis.json")}).Write(a.getJsonOutput())
+	require.ErrorContains(t, err, "writing output to")
+}
+
+func TestAnalysis_WriteOutputFile(t *testing.T) {
+	a := newSinkAnalysis()
+	path := filepath.Join(t.TempDir(), "reports", "analysis.txt")
+	// Colors are dropped even when the terminal supports them.
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+	require.NoError(t, a.WriteOutputFile(path, "text"))
+	require.False(t, color.NoColor)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "crash loop")
+	require.NotContains(t, string(data), "\x1b[")
+	// Only the output is left, no temporary file.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	require.NoError(t, a.WriteOutputFile(path, "json"))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	var got JsonOutput
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, a.getJsonOutput(), got)
+
+	require.ErrorContains(t, a.WriteOutputFile(path, "xml"), "unsupported output format")
+}
+
+// recordingSink keeps the outputs it receives.
+type recordingSink struct {
+	outputs []JsonOutput
+}
+
+func (s *recordingSink) Write(output JsonOutput) error {
+	s.outputs = append(s.outputs, output)
+	return nil
+}
+
+func TestAnalysis_WriteOutput(t *testing.T) {
+	a := newSinkAnalysis()
+	sink := &recordingSink{}
+	a.Sink = sink
+	require.NoError(t, a.WriteOutput())
+	require.Equal(t, []JsonOutput{a.getJsonOutput()}, sink.outputs)
+
+	// Without a sink, the text output is printed.
+	a.Sink = nil
+	output := util.CaptureOutput(func() {
+		require.NoError(t, a.WriteOutput())
+	})
+	text, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Equal(t, string(text)+"\n", output)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"sync"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+)
+
+// syncCache serializes the accesses to a cache, which explainResults shares
+// between its workers.
+type syncCache struct {
+	cache.ICache
+	mutex sync.Mutex
+}
+
+func (c *syncCache) Store(key string, data string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.ICache.Store(key, data)
+}
+
+func (c *syncCache) Load(key string) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.ICache.Load(key)
+}
+
+func (c *syncCache) List() ([]cache.CacheObjectDetails, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.ICache.List()
+}
+
+func (c *syncCache) Remove(key string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.ICache.Remove(key)
+}
+
+func (c *syncCache) Exists(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.ICache.Exists(key)
+}
+
+// Close waits for the Store operations of the other workers.
+func (c *syncCache) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.ICache.Close()
+}
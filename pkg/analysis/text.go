@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/spf13/viper"
+)
+
+// textResultKind is the Kind of the synthetic result explained by ExplainText.
+const textResultKind = "Text"
+
+// NewTextAnalysis returns an analysis which only explains text with
+// ExplainText, so it doesn't need a Kubernetes client.
+func NewTextAnalysis(ctx context.Context, backend string, language string, noCache bool, httpHeaders []string) (*Analysis, error) {
+	cache, err := loadCache(noCache)
+	if err != nil {
+		return nil, err
+	}
+	maxRetries, retryBaseDelay := getRetryConfiguration()
+	anonymizePatterns, err := getAnonymizePatterns()
+	if err != nil {
+		return nil, err
+	}
+	a := &Analysis{
+		Context:           ctx,
+		Language:          validLanguage(language),
+		Cache:             cache,
+		Explain:           true,
+		MaxRetries:        maxRetries,
+		RetryBaseDelay:    retryBaseDelay,
+		RequestTimeout:    viper.GetDuration("ai.request_timeout"),
+		RateLimiter:       NewRateLimiter(viper.GetFloat64("ai.rps")),
+		CompressCache:     viper.GetBool("cache.compress"),
+		RedactCache:       viper.GetBool("cache.redact"),
+		AnonymizePatterns: anonymizePatterns,
+		NoProgress:        viper.GetBool("no_progress"),
+	}
+	if err := a.configureAI(backend, httpHeaders); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ExplainText explains text, e.g. a pasted kubectl error message, as the only
+// failure of a synthetic result, bypassing RunAnalysis. The explanation is
+// cached and the text anonymized like the failures found by the analyzers.
+func (a *Analysis) ExplainText(text string, anonymize bool) (common.Result, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return common.Result{}, errors.New("no text to explain")
+	}
+	result := common.Result{
+		Kind:  textResultKind,
+		Error: []common.Failure{{Text: text}},
+	}
+	texts := a.sanitizedFailureTexts(result, anonymize)
+	details, provider, confidence, cached, err := a.getAIResultForSanitizedFailures(result.Kind, texts, a.promptTemplate(result.Kind))
+	if err != nil {
+		return common.Result{}, err
+	}
+	if anonymize {
+		details = a.unmaskDetails(result, details)
+	}
+	result.Details = a.setRemediation(&result, details)
+	result.Provider = provider
+	result.Confidence = confidence
+	result.Cached = cached
+	a.Results = []common.Result{result}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainText(t *testing.T) {
+	client := &echoAIClient{}
+	a := Analysis{
+		AIClient:  client,
+		Cache:     newMemoryCache(),
+		Language:  "english",
+		PromptMap: map[string]string{"default": "%s %s"},
+	}
+	result, err := a.ExplainText("  error: the server doesn't have a resource type \"pods\"\n", false)
+	require.NoError(t, err)
+	require.Equal(t, "Text", result.Kind)
+	require.Equal(t, "english error: the server doesn't have a resource type \"pods\"", result.Details)
+	require.Equal(t, result, a.Results[0])
+
+	// The second explanation of the same text is served from the cache.
+	_, err = a.ExplainText("error: the server doesn't have a resource type \"pods\"", false)
+	require.NoError(t, err)
+	require.Len(t, client.prompts, 1)
+}
+
+func TestExplainText_Anonymize(t *testing.T) {
+	client := &echoAIClient{}
+	a := Analysis{
+		AIClient:          client,
+		Cache:             newMemoryCache(),
+		Language:          "english",
+		PromptMap:         map[string]string{"default": "%s %s"},
+		AnonymizePatterns: []*regexp.Regexp{regexp.MustCompile(`OPS-[0-9]+`)},
+	}
+	result, err := a.ExplainText("deployment rollout blocked, see OPS-42", true)
+	require.NoError(t, err)
+	require.Len(t, client.prompts, 1)
+	require.NotContains(t, client.prompts[0], "OPS-42")
+	require.Equal(t, "english deployment rollout blocked, see OPS-42", result.Details)
+}
+
+func TestExplainText_Empty(t *testing.T) {
+	a := Analysis{Cache: newMemoryCache()}
+	_, err := a.ExplainText(" \n", false)
+	require.EqualError(t, err, "no text to explain")
+}
+
+func TestNewTextAnalysis_TestingProvider(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	// The testing provider needs no k8sgpt auth.
+	a, err := NewTextAnalysis(context.Background(), "noopai", "english", true, nil)
+	require.NoError(t, err)
+	a.Cache = newMemoryCache()
+	result, err := a.ExplainText("error: the server doesn't have a resource type \"pods\"", false)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(result.Details, "I am a noop response to the prompt"))
+	again, err := a.ExplainText("error: the server doesn't have a resource type \"pods\"", false)
+	require.NoError(t, err)
+	require.Equal(t, result.Details, again.Details)
+	require.Equal(t, int64(1), a.CacheStats().Hits)
+
+	_, err = NewTextAnalysis(context.Background(), "openai", "english", true, nil)
+	require.EqualError(t, err, "AI provider not specified in configuration. Please run k8sgpt auth")
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+// NextRun prepares the analysis to run again, e.g. in watch mode. The output
+// of the last run becomes the Previous one, so that the next run is compared
+// with it and its unchanged results reuse their explanations. The results,
+// errors and stats are cleared, the clients and the cache are kept.
+func (a *Analysis) NextRun() {
+	previous := a.getJsonOutput()
+	// The diff with the run before isn't needed anymore.
+	previous.Diff = nil
+	a.Previous = &previous
+	a.Results = nil
+	a.Errors = nil
+	a.Stats = nil
+	a.SkippedAnalyzers = nil
+	a.cappedResults = nil
+	a.resultAnalyzers = nil
+	a.truncated = nil
+	a.linesWritten = nil
+	a.explanationErr = nil
+	a.cutShort = nil
+	a.flagged = nil
+	a.cacheStats = CacheStats{}
+}
+
+// Changed tells whether problems are new or resolved since the Previous
+// analysis, always true without one.
+func (a *Analysis) Changed() bool {
+	diff := a.Diff()
+	return diff == nil || len(diff.New) > 0 || len(diff.Resolved) > 0
+}
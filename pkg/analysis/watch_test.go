@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+// Test: the next run is compared with the last one and reuses its explanations
+func TestAnalysis_NextRun(t *testing.T) {
+	client := &echoAIClient{}
+	a := Analysis{
+		AIClient:  client,
+		Cache:     newMemoryCache(),
+		Language:  "english",
+		PromptMap: map[string]string{"default": "%s %s"},
+		WithStats: true,
+		Results:   []common.Result{{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "pending"}}}},
+		Stats:     []common.AnalysisStats{{Analyzer: "Pod"}},
+	}
+	a.addError("Service", PhaseAnalysis, errors.New("forbidden"))
+	require.True(t, a.Changed())
+	require.NoError(t, a.GetAIResults("json", false))
+
+	a.NextRun()
+	require.Empty(t, a.Results)
+	require.Empty(t, a.Errors)
+	require.Empty(t, a.Stats)
+	require.Equal(t, CacheStats{}, a.CacheStats())
+	require.Len(t, a.Previous.Results, 1)
+	require.Nil(t, a.Previous.Diff)
+
+	a.Results = []common.Result{{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "pending"}}}}
+	require.False(t, a.Changed())
+	require.NoError(t, a.GetAIResults("json", false))
+	require.Equal(t, "english pending", a.Results[0].Details)
+	require.Len(t, client.prompts, 1)
+
+	a.NextRun()
+	require.True(t, a.Changed(), "the problem is resolved")
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultWebhookTimeout    = 10 * time.Second
+	defaultWebhookMaxRetries = 3
+)
+
+// Webhook delivers the JSON output of the analysis to an HTTP endpoint, e.g.
+// an incident management system.
+type Webhook struct {
+	URL     string
+	Headers map[string]string
+	// OnlyOnProblems skips the delivery when no problem was found.
+	OnlyOnProblems bool
+	// Timeout bounds each delivery attempt.
+	Timeout time.Duration
+	// MaxRetries and RetryBaseDelay control the exponential backoff applied to
+	// the failed deliveries, except for the requests rejected with a 4xx status.
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+}
+
+// getWebhookConfiguration reads the webhook configuration keys, nil when
+// webhook.url isn't set.
+func getWebhookConfiguration() *Webhook {
+	url := viper.GetString("webhook.url")
+	if url == "" {
+		return nil
+	}
+	webhook := &Webhook{
+		URL:            url,
+		Headers:        viper.GetStringMapString("webhook.headers"),
+		OnlyOnProblems: viper.GetBool("webhook.only_on_problems"),
+		Timeout:        defaultWebhookTimeout,
+		MaxRetries:     defaultWebhookMaxRetries,
+		RetryBaseDelay: defaultRetryBaseDelay,
+	}
+	if viper.IsSet("webhook.timeout") {
+		webhook.Timeout = viper.GetDuration("webhook.timeout")
+	}
+	if viper.IsSet("webhook.max_retries") {
+		webhook.MaxRetries = viper.GetInt("webhook.max_retries")
+	}
+	return webhook
+}
+
+// webhookStatusError is the status of a rejected delivery.
+type webhookStatusError struct {
+	statusCode    int
+	retryAfter    time.Duration
+	hasRetryAfter bool
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("status code: %d", e.statusCode)
+}
+
+func (e *webhookStatusError) RetryAfter() (time.Duration, bool) {
+	return e.retryAfter, e.hasRetryAfter
+}
+
+// SendWebhook posts the JSON output to the Webhook, if any. A failed delivery
+// is reported in Errors, it doesn't fail the analysis.
+func (a *Analysis) SendWebhook() {
+	if a.Webhook == nil {
+		return
+	}
+	output := a.getJsonOutput()
+	if a.Webhook.OnlyOnProblems && output.Problems == 0 {
+		a.logger().Debug("No problem found, skipping the webhook")
+		return
+	}
+	body, err := json.Marshal(output)
+	if err == nil {
+		err = a.Webhook.send(a.contextOrBackground(), body, a.logger())
+	}
+	if err != nil {
+		a.addError("Webhook", PhaseDelivery, fmt.Errorf("delivery failed: %w", err))
+	}
+}
+
+func (w *Webhook) send(ctx context.Context, body []byte, log *slog.Logger) error {
+	client := &http.Client{Timeout: w.Timeout}
+	for attempt := 0; ; attempt++ {
+		err := w.post(ctx, client, body)
+		if err == nil || attempt >= w.MaxRetries || !isRetryableWebhookError(err) {
+			return err
+		}
+		delay := backoffDelay(err, w.RetryBaseDelay, attempt)
+		log.Debug("Webhook delivery failed, retrying", "delay", delay, "attempt", attempt+1, "maxRetries", w.MaxRetries, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (retry interrupted: %v)", err, ctx.Err())
+		}
+	}
+}
+
+func (w *Webhook) post(ctx context.Context, client *http.Client, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	statusErr := &webhookStatusError{statusCode: resp.StatusCode}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		statusErr.retryAfter, statusErr.hasRetryAfter = time.Duration(seconds)*time.Second, true
+	}
+	return statusErr
+}
+
+// isRetryableWebhookError reports whether a delivery may succeed when retried:
+// the endpoint was unreachable, overloaded or failed.
+func isRetryableWebhookError(err error) bool {
+	statusErr, ok := err.(*webhookStatusError)
+	if !ok {
+		return true
+	}
+	return statusErr.statusCode == http.StatusTooManyRequests || statusErr.statusCode >= 500
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func newWebhookAnalysis(url string) *Analysis {
+	return &Analysis{
+		Results: []common.Result{{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "pod failure"}}}},
+		Webhook: &Webhook{
+			URL:            url,
+			Headers:        map[string]string{"Authorization": "Bearer token"},
+			Timeout:        time.Second,
+			MaxRetries:     2,
+			RetryBaseDelay: time.Millisecond,
+		},
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var calls int32
+	var got JsonOutput
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first delivery fails and is retried.
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer server.Close()
+
+	a := newWebhookAnalysis(server.URL)
+	a.SendWebhook()
+	require.Empty(t, a.Errors)
+	require.Equal(t, int32(2), calls)
+	require.Equal(t, StateProblemDetected, got.Status)
+	require.Equal(t, 1, got.Problems)
+	require.Equal(t, "default/pod", got.Results[0].Name)
+}
+
+// Test: a Retry-After of 0 retries the delivery right away
+func TestSendWebhook_RetryAfterZero(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	a := newWebhookAnalysis(server.URL)
+	a.Webhook.RetryBaseDelay = time.Minute
+	start := time.Now()
+	a.SendWebhook()
+	require.Empty(t, a.Errors)
+	require.Equal(t, int32(2), calls)
+	require.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestSendWebhook_Failure(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	a := newWebhookAnalysis(server.URL)
+	a.SendWebhook()
+	// Rejected deliveries aren't retried.
+	require.Equal(t, int32(1), calls)
+	require.Equal(t, []string{"[Webhook] delivery failed: status code: 400"}, a.Errors.Strings())
+}
+
+func TestSendWebhook_OnlyOnProblems(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	a := newWebhookAnalysis(server.URL)
+	a.Webhook.OnlyOnProblems = true
+	a.Results = nil
+	a.SendWebhook()
+	require.Zero(t, calls)
+
+	a.Results = []common.Result{{Kind: "Pod", Name: "default/pod", Error: []common.Failure{{Text: "pod failure"}}}}
+	a.SendWebhook()
+	require.Equal(t, int32(1), calls)
+}
+
+func TestGetWebhookConfiguration(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	require.Nil(t, getWebhookConfiguration())
+
+	viper.Set("webhook.url", "https://example.com/hook")
+	viper.Set("webhook.headers", map[string]string{"X-Token": "secret"})
+	viper.Set("webhook.only_on_problems", true)
+	viper.Set("webhook.timeout", "5s")
+	webhook := getWebhookConfiguration()
+	require.Equal(t, &Webhook{
+		URL:            "https://example.com/hook",
+		Headers:        map[string]string{"X-Token": "secret"},
+		OnlyOnProblems: true,
+		Timeout:        5 * time.Second,
+		MaxRetries:     defaultWebhookMaxRetries,
+		RetryBaseDelay: defaultRetryBaseDelay,
+	}, webhook)
+}
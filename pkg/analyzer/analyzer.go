@@ -48,15 +48,19 @@ var coreAnalyzerMap = map[string]common.IAnalyzer{
 }
 
 var additionalAnalyzerMap = map[string]common.IAnalyzer{
-	"HorizontalPodAutoscaler": HpaAnalyzer{},
-	"PodDisruptionBudget":     PdbAnalyzer{},
-	"NetworkPolicy":           NetworkPolicyAnalyzer{},
-	"Log":                     LogAnalyzer{},
-	"GatewayClass":            GatewayClassAnalyzer{},
-	"Gateway":                 GatewayAnalyzer{},
-	"HTTPRoute":               HTTPRouteAnalyzer{},
-	"Storage":                 StorageAnalyzer{},
-	"Security":                SecurityAnalyzer{},
+	"HorizontalPodAutoscaler":             HpaAnalyzer{},
+	"PodDisruptionBudget":                 PdbAnalyzer{},
+	"NetworkPolicy":                       NetworkPolicyAnalyzer{},
+	"Log":                                 LogAnalyzer{},
+	"GatewayClass":                        GatewayClassAnalyzer{},
+	"Gateway":                             GatewayAnalyzer{},
+	"HTTPRoute":                           HTTPRouteAnalyzer{},
+	"Storage":                             StorageAnalyzer{},
+	"Security":                            SecurityAnalyzer{},
+	"ContainerResources":                  ResourcesAnalyzer{},
+	"TLSCertificate":                      TLSCertificateAnalyzer{},
+	"IngressBackend":                      IngressBackendAnalyzer{},
+	"PodDisruptionBudgetMisconfiguration": PdbMisconfigurationAnalyzer{},
 }
 
 func ListFilters() ([]string, []string, []string) {
@@ -70,6 +74,10 @@ func ListFilters() ([]string, []string, []string) {
 		additionalKeys = append(additionalKeys, k)
 	}
 
+	registeredCore, registeredAdditional := registeredAnalyzerNames()
+	coreKeys = append(coreKeys, registeredCore...)
+	additionalKeys = append(additionalKeys, registeredAdditional...)
+
 	integrationProvider := integration.NewIntegration()
 	var integrationAnalyzers []string
 
@@ -104,6 +112,10 @@ func GetAnalyzerMap() (map[string]common.IAnalyzer, map[string]common.IAnalyzer)
 		mergedAnalyzerMap[key] = value
 	}
 
+	// add the analyzers registered from outside of k8sgpt, the integrations
+	// take precedence over them
+	addRegisteredAnalyzers(coreAnalyzer, mergedAnalyzerMap)
+
 	integrationProvider := integration.NewIntegration()
 
 	for _, i := range integrationProvider.List() {
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// defaultCertExpiryDays is the threshold of the TLSCertificate analyzer when
+// cert_expiry_days isn't set.
+const defaultCertExpiryDays = 30
+
+// TLSCertificateAnalyzer flags the kubernetes.io/tls Secrets whose certificate
+// expires within cert_expiry_days days, or can't be parsed.
+type TLSCertificateAnalyzer struct {
+	// now is the time the expiry is checked against, time.Now when nil.
+	now func() time.Time
+}
+
+func (t TLSCertificateAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+	kind := "TLSCertificate"
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	fieldSelector := fields.OneTermEqualSelector("type", string(v1.SecretTypeTLS)).String()
+	if a.FieldSelector != "" {
+		fieldSelector += "," + a.FieldSelector
+	}
+	secrets, err := a.Client.GetClient().CoreV1().Secrets(a.Namespace).List(a.Context, metav1.ListOptions{
+		LabelSelector: a.LabelSelector,
+		FieldSelector: fieldSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.CountScanned(len(secrets.Items))
+
+	threshold := defaultCertExpiryDays
+	if viper.IsSet("cert_expiry_days") {
+		threshold = viper.GetInt("cert_expiry_days")
+	}
+	now := time.Now()
+	if t.now != nil {
+		now = t.now()
+	}
+
+	var results []common.Result
+	for _, secret := range secrets.Items {
+		// The fake clients ignore the field selectors.
+		if secret.Type != v1.SecretTypeTLS {
+			continue
+		}
+		var text string
+		certificate, err := parseCertificate(secret.Data[v1.TLSCertKey])
+		switch {
+		case err != nil:
+			text = fmt.Sprintf("TLS secret %s in namespace %s has an unparseable certificate: %v", secret.Name, secret.Namespace, err)
+		case certificate.NotAfter.Before(now):
+			text = fmt.Sprintf("The certificate of TLS secret %s in namespace %s expired %d days ago, on %s", secret.Name, secret.Namespace, int(now.Sub(certificate.NotAfter).Hours()/24), certificate.NotAfter.UTC().Format(time.DateOnly))
+		default:
+			days := int(certificate.NotAfter.Sub(now).Hours() / 24)
+			if days >= threshold {
+				continue
+			}
+			text = fmt.Sprintf("The certificate of TLS secret %s in namespace %s expires in %d days, on %s", secret.Name, secret.Namespace, days, certificate.NotAfter.UTC().Format(time.DateOnly))
+		}
+		results = append(results, common.Result{
+			Kind: kind,
+			Name: fmt.Sprintf("%s/%s", secret.Namespace, secret.Name),
+			Error: []common.Failure{{
+				Text: text,
+				Sensitive: []common.Sensitive{
+					{
+						Unmasked: secret.Name,
+						Masked:   util.MaskString(secret.Name),
+					},
+					{
+						Unmasked: secret.Namespace,
+						Masked:   util.MaskString(secret.Namespace),
+					},
+				},
+			}},
+			Labels: a.ResultLabels(secret.ObjectMeta),
+		})
+		AnalyzerErrorsMetric.WithLabelValues(kind, secret.Name, secret.Namespace).Set(1)
+	}
+
+	return results, nil
+}
+
+// parseCertificate parses the first certificate of a PEM bundle, the one of
+// the server.
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no %s", v1.TLSCertKey)
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no PEM encoded certificate")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
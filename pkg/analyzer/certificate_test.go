@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var certificateNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func certificatePEM(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func tlsSecret(name string, namespace string, certificate []byte) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": name}},
+		Type:       v1.SecretTypeTLS,
+		Data:       map[string][]byte{v1.TLSCertKey: certificate},
+	}
+}
+
+func TestTLSCertificateAnalyzer(t *testing.T) {
+	viper.Reset()
+	clientset := fake.NewSimpleClientset(
+		tlsSecret("valid", "default", certificatePEM(t, certificateNow.AddDate(0, 0, 90))),
+		tlsSecret("expiring", "default", certificatePEM(t, certificateNow.AddDate(0, 0, 10))),
+		tlsSecret("expired", "default", certificatePEM(t, certificateNow.AddDate(0, 0, -3))),
+		tlsSecret("malformed", "default", []byte("not a certificate")),
+		tlsSecret("other", "other", certificatePEM(t, certificateNow.AddDate(0, 0, 1))),
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "opaque", Namespace: "default"},
+			Type:       v1.SecretTypeOpaque,
+		},
+	)
+	config := common.Analyzer{
+		Client:    &kubernetes.Client{Client: clientset},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+	analyzer := TLSCertificateAnalyzer{now: func() time.Time { return certificateNow }}
+	results, err := analyzer.Analyze(config)
+	require.NoError(t, err)
+
+	texts := map[string]string{}
+	for _, result := range results {
+		require.Equal(t, "TLSCertificate", result.Kind)
+		require.Len(t, result.Error, 1)
+		texts[result.Name] = result.Error[0].Text
+	}
+	require.Equal(t, map[string]string{
+		"default/expiring":  "The certificate of TLS secret expiring in namespace default expires in 10 days, on 2024-06-11",
+		"default/expired":   "The certificate of TLS secret expired in namespace default expired 3 days ago, on 2024-05-29",
+		"default/malformed": "TLS secret malformed in namespace default has an unparseable certificate: no PEM encoded certificate",
+	}, texts)
+
+	// The threshold is configurable, and so is the selection of the secrets.
+	viper.Set("cert_expiry_days", 5)
+	config.LabelSelector = "app=expiring"
+	results, err = analyzer.Analyze(config)
+	require.NoError(t, err)
+	require.Empty(t, results)
+	viper.Set("cert_expiry_days", 100)
+	results, err = analyzer.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "default/expiring", results[0].Name)
+	viper.Reset()
+}
@@ -32,10 +32,12 @@ func (ConfigMapAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	// Get all ConfigMaps in the namespace
 	configMaps, err := a.Client.GetClient().CoreV1().ConfigMaps(a.Namespace).List(a.Context, metav1.ListOptions{
 		LabelSelector: a.LabelSelector,
+		FieldSelector: a.FieldSelector,
 	})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(configMaps.Items))
 
 	// Get all Pods to check ConfigMap usage
 	pods, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{})
@@ -78,6 +80,9 @@ func (ConfigMapAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	// Analyze each ConfigMap
 	for _, cm := range configMaps.Items {
+		if !a.InWindow(cm.CreationTimestamp) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Check for unused ConfigMaps
@@ -113,9 +118,10 @@ func (ConfigMapAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 		if len(failures) > 0 {
 			results = append(results, common.Result{
-				Kind:  kind,
-				Name:  fmt.Sprintf("%s/%s", cm.Namespace, cm.Name),
-				Error: failures,
+				Kind:   kind,
+				Name:   fmt.Sprintf("%s/%s", cm.Namespace, cm.Name),
+				Error:  failures,
+				Labels: a.ResultLabels(cm.ObjectMeta),
 			})
 			AnalyzerErrorsMetric.WithLabelValues(kind, cm.Name, cm.Namespace).Set(float64(len(failures)))
 		}
@@ -43,14 +43,24 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 		"analyzer_name": kind,
 	})
 
-	cronJobList, err := a.Client.GetClient().BatchV1().CronJobs(a.Namespace).List(a.Context, v1.ListOptions{LabelSelector: a.LabelSelector})
+	cronJobList, err := a.Client.GetClient().BatchV1().CronJobs(a.Namespace).List(a.Context, v1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(cronJobList.Items))
 
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, cronJob := range cronJobList.Items {
+		var timestamps []v1.Time
+		for _, timestamp := range []*v1.Time{cronJob.Status.LastScheduleTime, cronJob.Status.LastSuccessfulTime} {
+			if timestamp != nil {
+				timestamps = append(timestamps, *timestamp)
+			}
+		}
+		if !a.InWindow(cronJob.CreationTimestamp, timestamps...) {
+			continue
+		}
 		var failures []common.Failure
 		if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
 			doc := apiDoc.GetApiDocV2("spec.suspend")
@@ -118,6 +128,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 
 		if len(failures) > 0 {
 			preAnalysis[fmt.Sprintf("%s/%s", cronJob.Namespace, cronJob.Name)] = common.PreAnalysis{
+				CronJob:        cronJob,
 				FailureDetails: failures,
 			}
 			AnalyzerErrorsMetric.WithLabelValues(kind, cronJob.Name, cronJob.Namespace).Set(float64(len(failures)))
@@ -127,9 +138,10 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 
 	for key, value := range preAnalysis {
 		currentAnalysis := common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.CronJob.ObjectMeta),
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
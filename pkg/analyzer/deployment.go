@@ -46,13 +46,21 @@ func (d DeploymentAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 		"analyzer_name": kind,
 	})
 
-	deployments, err := a.Client.GetClient().AppsV1().Deployments(a.Namespace).List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector})
+	deployments, err := a.Client.GetClient().AppsV1().Deployments(a.Namespace).List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(deployments.Items))
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, deployment := range deployments.Items {
+		var timestamps []v1.Time
+		for _, condition := range deployment.Status.Conditions {
+			timestamps = append(timestamps, condition.LastUpdateTime, condition.LastTransitionTime)
+		}
+		if !a.InWindow(deployment.CreationTimestamp, timestamps...) {
+			continue
+		}
 		var failures []common.Failure
 		if *deployment.Spec.Replicas != deployment.Status.ReadyReplicas {
 			if  deployment.Status.Replicas > *deployment.Spec.Replicas {
@@ -102,9 +110,20 @@ func (d DeploymentAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.Deployment.ObjectMeta),
+			Remediation: []common.Remediation{
+				{
+					Command:     fmt.Sprintf("kubectl rollout status deployment/%s -n %s", value.Deployment.Name, value.Deployment.Namespace),
+					Description: "Check the progress of the rollout",
+				},
+				{
+					Command:     fmt.Sprintf("kubectl rollout restart deployment/%s -n %s", value.Deployment.Name, value.Deployment.Namespace),
+					Description: "Restart the pods of the deployment",
+				},
+			},
 		}
 
 		a.Results = append(a.Results, currentAnalysis)
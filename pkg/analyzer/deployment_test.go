@@ -16,6 +16,7 @@ package analyzer
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
@@ -72,6 +73,7 @@ func TestDeploymentAnalyzer(t *testing.T) {
 	assert.Equal(t, len(analysisResults), 1)
 	assert.Equal(t, analysisResults[0].Kind, "Deployment")
 	assert.Equal(t, analysisResults[0].Name, "default/example")
+	assert.Equal(t, analysisResults[0].Remediation[1].Command, "kubectl rollout restart deployment/example -n default")
 }
 
 func TestDeploymentAnalyzerNamespaceFiltering(t *testing.T) {
@@ -203,3 +205,44 @@ func TestDeploymentAnalyzerLabelSelectorFiltering(t *testing.T) {
 	}
 	assert.Equal(t, len(analysisResults), 1)
 }
+
+func TestDeploymentAnalyzerSince(t *testing.T) {
+	now := time.Now()
+	deployment := func(name string, updated time.Time) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(now.Add(-24 * time.Hour)),
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: func() *int32 { i := int32(3); return &i }(),
+			},
+			Status: appsv1.DeploymentStatus{
+				Replicas: 1,
+				Conditions: []appsv1.DeploymentCondition{{
+					Type:           appsv1.DeploymentProgressing,
+					LastUpdateTime: metav1.NewTime(updated),
+				}},
+			},
+		}
+	}
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				deployment("stale", now.Add(-24*time.Hour)),
+				deployment("updated", now.Add(-time.Minute)),
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+		Since:     now.Add(-time.Hour),
+	}
+
+	analysisResults, err := DeploymentAnalyzer{}.Analyze(config)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, len(analysisResults), 1)
+	assert.Equal(t, analysisResults[0].Name, "default/updated")
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/custom"
+	"github.com/spf13/viper"
+)
+
+// FilterSource tells where the analyzer run by a filter comes from.
+type FilterSource string
+
+const (
+	FilterSourceCore        FilterSource = "core"
+	FilterSourceAdditional  FilterSource = "additional"
+	FilterSourceIntegration FilterSource = "integration"
+	FilterSourceCustom      FilterSource = "custom"
+)
+
+// Filter describes a filter for the listings of the available filters.
+type Filter struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Source      FilterSource `json:"source"`
+	// DefaultOn tells the analyzer runs when no filter is set, Active that
+	// it runs with the active_filters of the configuration.
+	DefaultOn bool `json:"defaultOn"`
+	Active    bool `json:"active"`
+}
+
+// Describer may be implemented by the registered analyzers to describe
+// their filter.
+type Describer interface {
+	Description() string
+}
+
+var filterDescriptions = map[string]string{
+	"Pod":                                 "Pods which are pending, crash looping or whose containers aren't ready",
+	"Deployment":                          "Deployments with fewer available replicas than desired",
+	"ReplicaSet":                          "ReplicaSets with a failure condition, e.g. failing to create their pods",
+	"PersistentVolumeClaim":               "PersistentVolumeClaims stuck pending, with their provisioning events",
+	"Service":                             "Services without endpoints or ready endpoints",
+	"Ingress":                             "Ingresses with a missing class, backend Service or TLS secret",
+	"StatefulSet":                         "StatefulSets with a missing Service or StorageClass, or unavailable replicas",
+	"Job":                                 "Jobs which are suspended or failed",
+	"CronJob":                             "CronJobs which are suspended or have an invalid schedule or starting deadline",
+	"Node":                                "Nodes with an unhealthy condition",
+	"ValidatingWebhookConfiguration":      "Validating webhooks whose Service doesn't exist or has no running pod",
+	"MutatingWebhookConfiguration":        "Mutating webhooks whose Service doesn't exist or has no running pod",
+	"ConfigMap":                           "ConfigMaps which are empty, larger than 1MB or not used by any pod",
+	"HorizontalPodAutoscaler":             "HorizontalPodAutoscalers failing to scale, or whose target is missing or lacks resources",
+	"PodDisruptionBudget":                 "PodDisruptionBudgets whose expected pods are missing",
+	"NetworkPolicy":                       "NetworkPolicies allowing all traffic or selecting no pod",
+	"Log":                                 "Errors in the logs of the pods, sent to the AI provider",
+	"GatewayClass":                        "GatewayClasses which aren't accepted",
+	"Gateway":                             "Gateways with a missing class or which aren't accepted",
+	"HTTPRoute":                           "HTTPRoutes with a missing Gateway or backend Service",
+	"Storage":                             "StorageClasses, PersistentVolumes and PersistentVolumeClaims misconfigurations",
+	"Security":                            "Privileged pods, pods without a security context or with the default ServiceAccount, wildcard Roles",
+	"ContainerResources":                  "Containers lacking CPU or memory requests or limits",
+	"TLSCertificate":                      "TLS secrets whose certificate expires soon or can't be parsed",
+	"IngressBackend":                      "Ingress backends whose Service doesn't exist, doesn't expose their port or has no ready endpoint",
+	"PodDisruptionBudgetMisconfiguration": "PodDisruptionBudgets selecting no pod or blocking all the evictions",
+}
+
+// DescribeFilters returns the available filters sorted by name: the core,
+// additional and registered ones, those of the active integrations and the
+// custom analyzers of the configuration.
+func DescribeFilters() []Filter {
+	coreFilters, additionalFilters, integrationFilters := ListFilters()
+	activeFilters := viper.GetStringSlice("active_filters")
+	// Like the analysis, the core analyzers run when no filter is active.
+	active := func(name string, defaultOn bool) bool {
+		if len(activeFilters) == 0 {
+			return defaultOn
+		}
+		return slices.Contains(activeFilters, name)
+	}
+
+	registeredMutex.RLock()
+	description := func(name string) string {
+		if text, ok := filterDescriptions[name]; ok {
+			return text
+		}
+		if describer, ok := registeredAnalyzerMap[name].(Describer); ok {
+			return describer.Description()
+		}
+		return ""
+	}
+	var filters []Filter
+	for _, name := range coreFilters {
+		filters = append(filters, Filter{Name: name, Description: description(name), Source: FilterSourceCore, DefaultOn: true, Active: active(name, true)})
+	}
+	for _, name := range additionalFilters {
+		filters = append(filters, Filter{Name: name, Description: description(name), Source: FilterSourceAdditional, Active: active(name, false)})
+	}
+	registeredMutex.RUnlock()
+	for _, name := range integrationFilters {
+		filters = append(filters, Filter{Name: name, Source: FilterSourceIntegration, Active: active(name, false)})
+	}
+
+	var customAnalyzers []custom.CustomAnalyzer
+	// A malformed configuration is reported by the analysis.
+	_ = viper.UnmarshalKey("custom_analyzers", &customAnalyzers)
+	for _, cAnalyzer := range customAnalyzers {
+		// All the custom analyzers run when no filter is active.
+		filters = append(filters, Filter{Name: cAnalyzer.Name, Source: FilterSourceCustom, DefaultOn: true, Active: active(cAnalyzer.Name, true)})
+	}
+
+	sort.SliceStable(filters, func(i, j int) bool {
+		return filters[i].Name < filters[j].Name
+	})
+	return filters
+}
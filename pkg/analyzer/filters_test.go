@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+type describedAnalyzer struct {
+	registeredAnalyzer
+}
+
+func (describedAnalyzer) Description() string {
+	return "Widgets which are broken"
+}
+
+func TestDescribeFilters(t *testing.T) {
+	t.Cleanup(func() {
+		registeredMutex.Lock()
+		defer registeredMutex.Unlock()
+		registeredAnalyzerMap = map[string]common.IAnalyzer{}
+		registeredCoreAnalyzers = map[string]bool{}
+		viper.Set("active_filters", nil)
+		viper.Set("custom_analyzers", nil)
+	})
+	require.NoError(t, Register("Widget", describedAnalyzer{}))
+	viper.Set("custom_analyzers", []map[string]interface{}{
+		{"name": "my-analyzer", "connection": map[string]interface{}{"url": "localhost", "port": "8085"}},
+	})
+
+	describe := func() map[string]Filter {
+		filters := map[string]Filter{}
+		for _, filter := range DescribeFilters() {
+			filters[filter.Name] = filter
+		}
+		return filters
+	}
+
+	// All the built-in filters are described.
+	filters := describe()
+	for name := range coreAnalyzerMap {
+		require.NotEmpty(t, filters[name].Description, name)
+	}
+	for name := range additionalAnalyzerMap {
+		require.NotEmpty(t, filters[name].Description, name)
+	}
+
+	// Without active filters, the core analyzers and the custom ones run.
+	require.Equal(t, Filter{Name: "Pod", Description: filterDescriptions["Pod"], Source: FilterSourceCore, DefaultOn: true, Active: true}, filters["Pod"])
+	require.Equal(t, Filter{Name: "Log", Description: filterDescriptions["Log"], Source: FilterSourceAdditional}, filters["Log"])
+	require.Equal(t, Filter{Name: "Widget", Description: "Widgets which are broken", Source: FilterSourceAdditional}, filters["Widget"])
+	require.Equal(t, Filter{Name: "my-analyzer", Source: FilterSourceCustom, DefaultOn: true, Active: true}, filters["my-analyzer"])
+
+	viper.Set("active_filters", []string{"Log", "Widget"})
+	filters = describe()
+	require.False(t, filters["Pod"].Active)
+	require.True(t, filters["Pod"].DefaultOn)
+	require.True(t, filters["Log"].Active)
+	require.True(t, filters["Widget"].Active)
+	require.False(t, filters["my-analyzer"].Active)
+}
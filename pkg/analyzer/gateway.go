@@ -46,11 +46,19 @@ func (GatewayAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	if err := client.List(a.Context, gtwList, &ctrl.ListOptions{LabelSelector: labelSelector}); err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(gtwList.Items))
 
 	var preAnalysis = map[string]common.PreAnalysis{}
 	// Find all unhealthy gateway Classes
 
 	for _, gtw := range gtwList.Items {
+		var timestamps []metav1.Time
+		for _, condition := range gtw.Status.Conditions {
+			timestamps = append(timestamps, condition.LastTransitionTime)
+		}
+		if !a.InWindow(gtw.CreationTimestamp, timestamps...) {
+			continue
+		}
 		var failures []common.Failure
 
 		gtwName := gtw.GetName()
@@ -103,9 +111,10 @@ func (GatewayAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	}
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.Gateway.ObjectMeta),
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
@@ -44,11 +44,19 @@ func (GatewayClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 	if err := client.List(a.Context, gcList, &ctrl.ListOptions{LabelSelector: labelSelector}); err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(gcList.Items))
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	// Find all unhealthy gateway Classes
 
 	for _, gc := range gcList.Items {
+		var timestamps []metav1.Time
+		for _, condition := range gc.Status.Conditions {
+			timestamps = append(timestamps, condition.LastTransitionTime)
+		}
+		if !a.InWindow(gc.CreationTimestamp, timestamps...) {
+			continue
+		}
 		var failures []common.Failure
 
 		gcName := gc.GetName()
@@ -79,9 +87,10 @@ func (GatewayClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 	}
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.GatewayClass.ObjectMeta),
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
@@ -44,14 +44,25 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		"analyzer_name": kind,
 	})
 
-	list, err := a.Client.GetClient().AutoscalingV2().HorizontalPodAutoscalers(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().AutoscalingV2().HorizontalPodAutoscalers(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(list.Items))
 
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, hpa := range list.Items {
+		var timestamps []metav1.Time
+		for _, condition := range hpa.Status.Conditions {
+			timestamps = append(timestamps, condition.LastTransitionTime)
+		}
+		if hpa.Status.LastScaleTime != nil {
+			timestamps = append(timestamps, *hpa.Status.LastScaleTime)
+		}
+		if !a.InWindow(hpa.CreationTimestamp, timestamps...) {
+			continue
+		}
 		var failures []common.Failure
 
 		//check the error from status field
@@ -158,9 +169,10 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.HorizontalPodAutoscalers.ObjectMeta),
 		}
 
 		parent, found := util.GetParent(a.Client, value.HorizontalPodAutoscalers.ObjectMeta)
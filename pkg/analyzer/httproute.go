@@ -47,10 +47,14 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	if err := client.List(a.Context, routeList, &ctrl.ListOptions{LabelSelector: labelSelector}); err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(routeList.Items))
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	// Find all unhealthy gateway Classes
 	for _, route := range routeList.Items {
+		if !a.InWindow(route.CreationTimestamp) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Check if Gateways exists in the same or designated namespace
@@ -221,9 +225,10 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	}
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.HTTPRoute.ObjectMeta),
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
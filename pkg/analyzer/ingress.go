@@ -41,14 +41,18 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		"analyzer_name": kind,
 	})
 
-	list, err := a.Client.GetClient().NetworkingV1().Ingresses(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().NetworkingV1().Ingresses(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(list.Items))
 
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, ing := range list.Items {
+		if !a.InWindow(ing.CreationTimestamp) {
+			continue
+		}
 		var failures []common.Failure
 
 		// get ingressClassName
@@ -158,9 +162,10 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.Ingress.ObjectMeta),
 		}
 
 		parent, found := util.GetParent(a.Client, value.Ingress.ObjectMeta)
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IngressBackendAnalyzer flags the backends of the Ingresses, their default
+// backend and the paths of their rules, whose Service doesn't exist, doesn't
+// expose their port or has no ready endpoint. The Ingresses of the older
+// extensions/v1beta1 and networking.k8s.io/v1beta1 APIs are served as
+// networking.k8s.io/v1 ones, so they are analyzed alike.
+type IngressBackendAnalyzer struct{}
+
+// ingressService is a Service of the Ingresses, nil when it doesn't exist, with
+// whether it has a ready endpoint.
+type ingressService struct {
+	service *v1.Service
+	ready   bool
+}
+
+func (IngressBackendAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+	kind := "IngressBackend"
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	list, err := a.Client.GetClient().NetworkingV1().Ingresses(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
+	if err != nil {
+		return nil, err
+	}
+	a.CountScanned(len(list.Items))
+
+	// services are fetched once for all the backends referencing them.
+	services := map[string]ingressService{}
+	getService := func(namespace string, name string) (ingressService, error) {
+		key := namespace + "/" + name
+		if service, ok := services[key]; ok {
+			return service, nil
+		}
+		var service ingressService
+		found, err := a.Client.GetClient().CoreV1().Services(namespace).Get(a.Context, name, metav1.GetOptions{})
+		switch {
+		case errors.IsNotFound(err):
+		case err != nil:
+			return service, err
+		default:
+			service.service = found
+			endpoints, err := a.Client.GetClient().CoreV1().Endpoints(namespace).Get(a.Context, name, metav1.GetOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return service, err
+			}
+			service.ready = err == nil && hasReadyAddress(endpoints)
+		}
+		services[key] = service
+		return service, nil
+	}
+
+	var results []common.Result
+	for _, ing := range list.Items {
+		if !a.InWindow(ing.CreationTimestamp) {
+			continue
+		}
+		var failures []common.Failure
+		check := func(backend *networkingv1.IngressBackend, route string) error {
+			// The Resource backends aren't Services.
+			if backend == nil || backend.Service == nil {
+				return nil
+			}
+			service, err := getService(ing.Namespace, backend.Service.Name)
+			if err != nil {
+				return err
+			}
+			var text string
+			switch {
+			case service.service == nil:
+				text = fmt.Sprintf("Ingress %s/%s routes %s to the Service %s which does not exist.", ing.Namespace, ing.Name, route, backend.Service.Name)
+			case service.service.Spec.Type == v1.ServiceTypeExternalName:
+				// Neither ports nor endpoints are required.
+				return nil
+			case !exposesPort(service.service, backend.Service.Port):
+				text = fmt.Sprintf("Ingress %s/%s routes %s to the port %s of the Service %s which does not expose it.", ing.Namespace, ing.Name, route, backendPort(backend.Service.Port), backend.Service.Name)
+			case !service.ready:
+				text = fmt.Sprintf("Ingress %s/%s routes %s to the Service %s which has no ready endpoints.", ing.Namespace, ing.Name, route, backend.Service.Name)
+			default:
+				return nil
+			}
+			failures = append(failures, common.Failure{
+				Text: text,
+				Sensitive: []common.Sensitive{
+					{
+						Unmasked: ing.Namespace,
+						Masked:   util.MaskString(ing.Namespace),
+					},
+					{
+						Unmasked: ing.Name,
+						Masked:   util.MaskString(ing.Name),
+					},
+					{
+						Unmasked: backend.Service.Name,
+						Masked:   util.MaskString(backend.Service.Name),
+					},
+				},
+			})
+			return nil
+		}
+
+		if err := check(ing.Spec.DefaultBackend, "the default backend"); err != nil {
+			return nil, err
+		}
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if err := check(&path.Backend, fmt.Sprintf("the path %s%s", rule.Host, path.Path)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(failures) > 0 {
+			results = append(results, common.Result{
+				Kind:   kind,
+				Name:   fmt.Sprintf("%s/%s", ing.Namespace, ing.Name),
+				Error:  failures,
+				Labels: a.ResultLabels(ing.ObjectMeta),
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind, ing.Name, ing.Namespace).Set(float64(len(failures)))
+		}
+	}
+
+	return results, nil
+}
+
+// exposesPort tells whether the Service has the port of a backend, by name or
+// by number.
+func exposesPort(service *v1.Service, port networkingv1.ServiceBackendPort) bool {
+	for _, servicePort := range service.Spec.Ports {
+		if port.Name != "" && servicePort.Name == port.Name || port.Name == "" && servicePort.Port == port.Number {
+			return true
+		}
+	}
+	return false
+}
+
+// backendPort is the name or else the number of the port of a backend.
+func backendPort(port networkingv1.ServiceBackendPort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return strconv.Itoa(int(port.Number))
+}
+
+// hasReadyAddress tells whether the Endpoints have a ready address.
+func hasReadyAddress(endpoints *v1.Endpoints) bool {
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// serviceBackend is a backend to the port of the Service, a name or a number.
+func serviceBackend(service string, port any) networkingv1.IngressBackend {
+	backend := networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: service}}
+	switch port := port.(type) {
+	case string:
+		backend.Service.Port.Name = port
+	case int:
+		backend.Service.Port.Number = int32(port)
+	}
+	return backend
+}
+
+// backendIngress routes the paths of example.com to the backends.
+func backendIngress(name string, namespace string, defaultBackend *networkingv1.IngressBackend, backends map[string]networkingv1.IngressBackend) *networkingv1.Ingress {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": name}},
+		Spec:       networkingv1.IngressSpec{DefaultBackend: defaultBackend},
+	}
+	if len(backends) > 0 {
+		rule := networkingv1.IngressRule{Host: "example.com", IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{}}}
+		paths := make([]string, 0, len(backends))
+		for path := range backends {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			rule.HTTP.Paths = append(rule.HTTP.Paths, networkingv1.HTTPIngressPath{Path: path, Backend: backends[path]})
+		}
+		ing.Spec.Rules = []networkingv1.IngressRule{rule}
+	}
+	return ing
+}
+
+// backendService exposes the http port 80, with a ready endpoint when ready.
+func backendService(name string, ready bool) (*v1.Service, *v1.Endpoints) {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1.ServiceSpec{Ports: []v1.ServicePort{{Name: "http", Port: 80}}},
+	}
+	endpoints := &v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	if ready {
+		endpoints.Subsets = []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{{IP: "10.0.0.1"}}}}
+	} else {
+		endpoints.Subsets = []v1.EndpointSubset{{NotReadyAddresses: []v1.EndpointAddress{{IP: "10.0.0.1"}}}}
+	}
+	return service, endpoints
+}
+
+func TestIngressBackendAnalyzer(t *testing.T) {
+	web, webEndpoints := backendService("web", true)
+	idle, idleEndpoints := backendService("idle", false)
+	external := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeExternalName, ExternalName: "example.org"},
+	}
+	webBackend := serviceBackend("web", "http")
+	missingBackend := serviceBackend("missing", 80)
+	clientset := fake.NewSimpleClientset(
+		web, webEndpoints, idle, idleEndpoints, external,
+		backendIngress("healthy", "default", &webBackend, map[string]networkingv1.IngressBackend{
+			"/":         serviceBackend("web", 80),
+			"/api":      serviceBackend("web", "http"),
+			"/external": serviceBackend("external", 443),
+			"/static":   {Resource: &v1.TypedLocalObjectReference{Kind: "StorageBucket", Name: "static"}},
+		}),
+		backendIngress("broken", "default", &missingBackend, map[string]networkingv1.IngressBackend{
+			"/api":     serviceBackend("missing", 80),
+			"/grpc":    serviceBackend("web", "grpc"),
+			"/metrics": serviceBackend("web", 9090),
+			"/idle":    serviceBackend("idle", 80),
+		}),
+		backendIngress("other", "other", &missingBackend, nil),
+	)
+	config := common.Analyzer{
+		Client:    &kubernetes.Client{Client: clientset},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := IngressBackendAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "IngressBackend", results[0].Kind)
+	require.Equal(t, "default/broken", results[0].Name)
+	var texts []string
+	for _, failure := range results[0].Error {
+		texts = append(texts, failure.Text)
+	}
+	require.Equal(t, []string{
+		"Ingress default/broken routes the default backend to the Service missing which does not exist.",
+		"Ingress default/broken routes the path example.com/api to the Service missing which does not exist.",
+		"Ingress default/broken routes the path example.com/grpc to the port grpc of the Service web which does not expose it.",
+		"Ingress default/broken routes the path example.com/idle to the Service idle which has no ready endpoints.",
+		"Ingress default/broken routes the path example.com/metrics to the port 9090 of the Service web which does not expose it.",
+	}, texts)
+	require.Equal(t, "missing", results[0].Error[0].Sensitive[2].Unmasked)
+}
+
+// Test: the Ingresses are selected by their labels, and a Service without endpoints isn't ready
+func TestIngressBackendAnalyzer_LabelSelector(t *testing.T) {
+	web, _ := backendService("web", true)
+	clientset := fake.NewSimpleClientset(
+		web,
+		backendIngress("selected", "default", nil, map[string]networkingv1.IngressBackend{"/": serviceBackend("web", 80)}),
+		backendIngress("ignored", "default", nil, map[string]networkingv1.IngressBackend{"/": serviceBackend("missing", 80)}),
+	)
+	config := common.Analyzer{
+		Client:        &kubernetes.Client{Client: clientset},
+		Context:       context.Background(),
+		LabelSelector: "app=selected",
+	}
+
+	results, err := IngressBackendAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "default/selected", results[0].Name)
+	require.Len(t, results[0].Error, 1)
+	require.Equal(t, "Ingress default/selected routes the path example.com/ to the Service web which has no ready endpoints.", results[0].Error[0].Text)
+}
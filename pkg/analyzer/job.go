@@ -41,14 +41,27 @@ func (analyzer JobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 		"analyzer_name": kind,
 	})
 
-	JobList, err := a.Client.GetClient().BatchV1().Jobs(a.Namespace).List(a.Context, v1.ListOptions{LabelSelector: a.LabelSelector})
+	JobList, err := a.Client.GetClient().BatchV1().Jobs(a.Namespace).List(a.Context, v1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(JobList.Items))
 
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, Job := range JobList.Items {
+		var timestamps []v1.Time
+		for _, condition := range Job.Status.Conditions {
+			timestamps = append(timestamps, condition.LastTransitionTime)
+		}
+		for _, timestamp := range []*v1.Time{Job.Status.StartTime, Job.Status.CompletionTime} {
+			if timestamp != nil {
+				timestamps = append(timestamps, *timestamp)
+			}
+		}
+		if !a.InWindow(Job.CreationTimestamp, timestamps...) {
+			continue
+		}
 		var failures []common.Failure
 		if Job.Spec.Suspend != nil && *Job.Spec.Suspend {
 			doc := apiDoc.GetApiDocV2("spec.suspend")
@@ -88,6 +101,7 @@ func (analyzer JobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 
 		if len(failures) > 0 {
 			preAnalysis[fmt.Sprintf("%s/%s", Job.Namespace, Job.Name)] = common.PreAnalysis{
+				Job:            Job,
 				FailureDetails: failures,
 			}
 			AnalyzerErrorsMetric.WithLabelValues(kind, Job.Name, Job.Namespace).Set(float64(len(failures)))
@@ -96,9 +110,10 @@ func (analyzer JobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 
 	for key, value := range preAnalysis {
 		currentAnalysis := common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.Job.ObjectMeta),
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
@@ -41,10 +41,11 @@ func (LogAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	})
 
 	// search all namespaces for pods that are not running
-	list, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(list.Items))
 	var preAnalysis = map[string]common.PreAnalysis{}
 	// Iterate through each pod
 
@@ -92,9 +93,10 @@ func (LogAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	}
 	for key, value := range preAnalysis {
 		currentAnalysis := common.Result{
-			Kind:  "Pod",
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   "Pod",
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.Pod.ObjectMeta),
 		}
 		parent, found := util.GetParent(a.Client, value.Pod.ObjectMeta)
 		if found {
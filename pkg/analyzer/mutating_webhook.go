@@ -42,14 +42,18 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 		"analyzer_name": kind,
 	})
 
-	mutatingWebhooks, err := a.Client.GetClient().AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector})
+	mutatingWebhooks, err := a.Client.GetClient().AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(mutatingWebhooks.Items))
 
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, webhookConfig := range mutatingWebhooks.Items {
+		if !a.InWindow(webhookConfig.CreationTimestamp) {
+			continue
+		}
 		for _, webhook := range webhookConfig.Webhooks {
 			var failures []common.Failure
 
@@ -146,9 +150,10 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 	}
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.MutatingWebhook.ObjectMeta),
 		}
 
 		parent, found := util.GetParent(a.Client, value.MutatingWebhook.ObjectMeta)
@@ -43,14 +43,18 @@ func (NetworkPolicyAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 
 	// get all network policies in the namespace
 	policies, err := a.Client.GetClient().NetworkingV1().
-		NetworkPolicies(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+		NetworkPolicies(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(policies.Items))
 
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, policy := range policies.Items {
+		if !a.InWindow(policy.CreationTimestamp) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Check if policy allows traffic to all pods in the namespace
@@ -98,9 +102,10 @@ func (NetworkPolicyAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 
 	for key, value := range preAnalysis {
 		currentAnalysis := common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.NetworkPolicy.ObjectMeta),
 		}
 		a.Results = append(a.Results, currentAnalysis)
 	}
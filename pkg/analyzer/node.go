@@ -33,14 +33,22 @@ func (NodeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		"analyzer_name": kind,
 	})
 
-	list, err := a.Client.GetClient().CoreV1().Nodes().List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().CoreV1().Nodes().List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(list.Items))
 
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, node := range list.Items {
+		var timestamps []metav1.Time
+		for _, condition := range node.Status.Conditions {
+			timestamps = append(timestamps, condition.LastTransitionTime)
+		}
+		if !a.InWindow(node.CreationTimestamp, timestamps...) {
+			continue
+		}
 		var failures []common.Failure
 		for _, nodeCondition := range node.Status.Conditions {
 			// https://kubernetes.io/docs/concepts/architecture/nodes/#condition
@@ -72,9 +80,10 @@ func (NodeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.Node.ObjectMeta),
 		}
 
 		parent, found := util.GetParent(a.Client, value.Node.ObjectMeta)
@@ -41,14 +41,22 @@ func (PdbAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		"analyzer_name": kind,
 	})
 
-	list, err := a.Client.GetClient().PolicyV1().PodDisruptionBudgets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().PolicyV1().PodDisruptionBudgets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(list.Items))
 
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, pdb := range list.Items {
+		var timestamps []metav1.Time
+		for _, condition := range pdb.Status.Conditions {
+			timestamps = append(timestamps, condition.LastTransitionTime)
+		}
+		if !a.InWindow(pdb.CreationTimestamp, timestamps...) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Before accessing the Conditions, check if they exist or not.
@@ -94,9 +102,10 @@ func (PdbAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.PodDisruptionBudget.ObjectMeta),
 		}
 
 		parent, found := util.GetParent(a.Client, value.PodDisruptionBudget.ObjectMeta)
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// PdbMisconfigurationAnalyzer flags the PodDisruptionBudgets which select no
+// pod, or whose minAvailable or maxUnavailable always allows zero disruptions,
+// blocking all the evictions, e.g. of the node drains.
+type PdbMisconfigurationAnalyzer struct{}
+
+func (PdbMisconfigurationAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+	kind := "PodDisruptionBudgetMisconfiguration"
+	apiDoc := kubernetes.K8sApiReference{
+		Kind: "PodDisruptionBudget",
+		ApiVersion: schema.GroupVersion{
+			Group:   "policy",
+			Version: "v1",
+		},
+		OpenapiSchema: a.OpenapiSchema,
+	}
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	list, err := a.Client.GetClient().PolicyV1().PodDisruptionBudgets(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
+	if err != nil {
+		return nil, err
+	}
+	a.CountScanned(len(list.Items))
+
+	// pods are the pods of all the PodDisruptionBudgets, whatever their labels,
+	// listed once there is one to check.
+	var pods []v1.Pod
+	listed := false
+
+	var results []common.Result
+	for _, pdb := range list.Items {
+		if !a.InWindow(pdb.CreationTimestamp) {
+			continue
+		}
+		if !listed {
+			podList, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			pods, listed = podList.Items, true
+		}
+
+		failures := pdbMisconfigurations(pdb, pods, apiDoc)
+		if len(failures) > 0 {
+			results = append(results, common.Result{
+				Kind:   kind,
+				Name:   fmt.Sprintf("%s/%s", pdb.Namespace, pdb.Name),
+				Error:  failures,
+				Labels: a.ResultLabels(pdb.ObjectMeta),
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind, pdb.Name, pdb.Namespace).Set(float64(len(failures)))
+		}
+	}
+
+	return results, nil
+}
+
+// pdbMisconfigurations reports a PodDisruptionBudget selecting no pod, or
+// whose minAvailable or maxUnavailable always allows zero disruptions. Without
+// either, no pod is required to be available, so nothing is reported.
+func pdbMisconfigurations(pdb policyv1.PodDisruptionBudget, pods []v1.Pod, apiDoc kubernetes.K8sApiReference) []common.Failure {
+	if pdb.Spec.MinAvailable == nil && pdb.Spec.MaxUnavailable == nil {
+		return nil
+	}
+	sensitive := []common.Sensitive{
+		{
+			Unmasked: pdb.Name,
+			Masked:   util.MaskString(pdb.Name),
+		},
+	}
+	// A nil selector selects no pod, an empty one all the pods of the
+	// namespace.
+	selected := 0
+	if pdb.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			return []common.Failure{{
+				Text:          fmt.Sprintf("PodDisruptionBudget %s has an invalid selector: %v", pdb.Name, err),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.selector"),
+				Sensitive:     sensitive,
+			}}
+		}
+		for _, pod := range pods {
+			if pod.Namespace == pdb.Namespace && selector.Matches(labels.Set(pod.Labels)) {
+				selected++
+			}
+		}
+	}
+	if selected == 0 {
+		return []common.Failure{{
+			Text:          fmt.Sprintf("PodDisruptionBudget %s selects no pods, so it protects none of them", pdb.Name),
+			KubernetesDoc: apiDoc.GetApiDocV2("spec.selector"),
+			Sensitive:     sensitive,
+		}}
+	}
+
+	// The expected pods are counted from the replicas of their controllers.
+	expected := int(pdb.Status.ExpectedPods)
+	if expected == 0 {
+		expected = selected
+	}
+	if pdb.Spec.MaxUnavailable != nil {
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MaxUnavailable, expected, true)
+		if err == nil && maxUnavailable <= 0 {
+			return []common.Failure{{
+				Text:          fmt.Sprintf("PodDisruptionBudget %s allows no disruption with maxUnavailable %s, so it blocks all the evictions, e.g. of the node drains", pdb.Name, pdb.Spec.MaxUnavailable.String()),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.maxUnavailable"),
+				Sensitive:     sensitive,
+			}}
+		}
+		return nil
+	}
+	required, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MinAvailable, expected, true)
+	if err == nil && required >= expected {
+		return []common.Failure{{
+			Text:          fmt.Sprintf("PodDisruptionBudget %s allows no disruption with minAvailable %s of %d pods, so it blocks all the evictions, e.g. of the node drains", pdb.Name, pdb.Spec.MinAvailable.String(), expected),
+			KubernetesDoc: apiDoc.GetApiDocV2("spec.minAvailable"),
+			Sensitive:     sensitive,
+		}}
+	}
+	return nil
+}
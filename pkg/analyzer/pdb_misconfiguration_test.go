@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func webPod(name string, namespace string) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": "web"}}}
+}
+
+func TestPdbMisconfigurationAnalyzer(t *testing.T) {
+	webSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	minAvailable := func(value intstr.IntOrString) policyv1.PodDisruptionBudgetSpec {
+		return policyv1.PodDisruptionBudgetSpec{Selector: webSelector, MinAvailable: &value}
+	}
+	maxUnavailable := func(value intstr.IntOrString) policyv1.PodDisruptionBudgetSpec {
+		return policyv1.PodDisruptionBudgetSpec{Selector: webSelector, MaxUnavailable: &value}
+	}
+	tests := []struct {
+		name     string
+		spec     policyv1.PodDisruptionBudgetSpec
+		expected string
+	}{
+		{
+			name:     "minAvailable blocks everything",
+			spec:     minAvailable(intstr.FromInt32(2)),
+			expected: "PodDisruptionBudget web allows no disruption with minAvailable 2 of 2 pods, so it blocks all the evictions, e.g. of the node drains",
+		},
+		{
+			name:     "minAvailable percentage blocks everything",
+			spec:     minAvailable(intstr.FromString("100%")),
+			expected: "PodDisruptionBudget web allows no disruption with minAvailable 100% of 2 pods, so it blocks all the evictions, e.g. of the node drains",
+		},
+		{
+			name:     "maxUnavailable blocks everything",
+			spec:     maxUnavailable(intstr.FromInt32(0)),
+			expected: "PodDisruptionBudget web allows no disruption with maxUnavailable 0, so it blocks all the evictions, e.g. of the node drains",
+		},
+		{
+			name: "selects nothing",
+			spec: policyv1.PodDisruptionBudgetSpec{
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+				MinAvailable: &intstr.IntOrString{IntVal: 1},
+			},
+			expected: "PodDisruptionBudget web selects no pods, so it protects none of them",
+		},
+		{
+			name:     "no selector",
+			spec:     policyv1.PodDisruptionBudgetSpec{MinAvailable: &intstr.IntOrString{IntVal: 1}},
+			expected: "PodDisruptionBudget web selects no pods, so it protects none of them",
+		},
+		{
+			name: "healthy minAvailable",
+			spec: minAvailable(intstr.FromInt32(1)),
+		},
+		{
+			name: "healthy maxUnavailable",
+			spec: maxUnavailable(intstr.FromString("50%")),
+		},
+		{
+			// No pod is required to be available.
+			name: "neither minAvailable nor maxUnavailable",
+			spec: policyv1.PodDisruptionBudgetSpec{Selector: webSelector},
+		},
+		{
+			name: "neither minAvailable nor maxUnavailable, selecting nothing",
+			spec: policyv1.PodDisruptionBudgetSpec{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := common.Analyzer{
+				Client: &kubernetes.Client{
+					Client: fake.NewSimpleClientset(
+						webPod("web-1", "default"),
+						webPod("web-2", "default"),
+						// Not selected, being in another namespace.
+						webPod("web-3", "other"),
+						&policyv1.PodDisruptionBudget{
+							ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+							Spec:       tt.spec,
+						},
+					),
+				},
+				Context: context.Background(),
+			}
+			results, err := PdbMisconfigurationAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+			if tt.expected == "" {
+				require.Empty(t, results)
+				return
+			}
+			require.Len(t, results, 1)
+			require.Equal(t, "PodDisruptionBudgetMisconfiguration", results[0].Kind)
+			require.Equal(t, "default/web", results[0].Name)
+			require.Len(t, results[0].Error, 1)
+			require.Equal(t, tt.expected, results[0].Error[0].Text)
+			require.Equal(t, "web", results[0].Error[0].Sensitive[0].Unmasked)
+		})
+	}
+}
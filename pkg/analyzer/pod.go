@@ -36,13 +36,18 @@ func (PodAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	// search all namespaces for pods that are not running
 	list, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{
 		LabelSelector: a.LabelSelector,
+		FieldSelector: a.FieldSelector,
 	})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(list.Items))
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, pod := range list.Items {
+		if !a.InWindow(pod.CreationTimestamp, podTimestamps(pod)...) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Check for pending pods
@@ -77,9 +82,11 @@ func (PodAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:        kind,
+			Name:        key,
+			Error:       value.FailureDetails,
+			Labels:      a.ResultLabels(value.Pod.ObjectMeta),
+			Remediation: podRemediation(value.Pod),
 		}
 
 		parent, found := util.GetParent(a.Client, value.Pod.ObjectMeta)
@@ -92,6 +99,21 @@ func (PodAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	return a.Results, nil
 }
 
+// podRemediation suggests reading the logs of the crashed containers of pod.
+func podRemediation(pod v1.Pod) []common.Remediation {
+	var remediation []common.Remediation
+	for _, containerStatus := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if containerStatus.State.Waiting == nil || containerStatus.State.Waiting.Reason != "CrashLoopBackOff" {
+			continue
+		}
+		remediation = append(remediation, common.Remediation{
+			Command:     fmt.Sprintf("kubectl logs %s -n %s -c %s --previous", pod.Name, pod.Namespace, containerStatus.Name),
+			Description: "Show the logs of the last crash",
+		})
+	}
+	return remediation
+}
+
 func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerStatus, name string, namespace string, statusPhase string) []common.Failure {
 	var failures []common.Failure
 
@@ -184,3 +206,24 @@ func isEvtErrorReason(reason string) bool {
 	}
 	return false
 }
+
+// podTimestamps returns the times the conditions and containers of the pod
+// last changed, to tell whether it changed within the Since window.
+func podTimestamps(pod v1.Pod) []metav1.Time {
+	var timestamps []metav1.Time
+	for _, condition := range pod.Status.Conditions {
+		timestamps = append(timestamps, condition.LastTransitionTime)
+	}
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		for _, state := range []v1.ContainerState{status.State, status.LastTerminationState} {
+			if state.Running != nil {
+				timestamps = append(timestamps, state.Running.StartedAt)
+			}
+			if state.Terminated != nil {
+				timestamps = append(timestamps, state.Terminated.FinishedAt)
+			}
+		}
+	}
+	return timestamps
+}
@@ -17,6 +17,7 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
@@ -420,3 +421,113 @@ func TestPodAnalyzer(t *testing.T) {
 		})
 	}
 }
+
+func TestPodAnalyzerCountsScannedPods(t *testing.T) {
+	scanned := &common.ScanCount{}
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "Pod1", Namespace: "default"}},
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "Pod2", Namespace: "default"}},
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "Pod3", Namespace: "test"}},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+		Scanned:   scanned,
+	}
+
+	_, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	objects, ok := scanned.Objects()
+	require.True(t, ok)
+	require.Equal(t, 2, objects)
+}
+
+func TestPodAnalyzerSince(t *testing.T) {
+	now := time.Now()
+	old := metav1.NewTime(now.Add(-24 * time.Hour))
+	recent := metav1.NewTime(now.Add(-10 * time.Minute))
+	pendingPod := func(name string, created, transitioned metav1.Time) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: created},
+			Status: v1.PodStatus{
+				Phase: v1.PodPending,
+				Conditions: []v1.PodCondition{{
+					Type:               v1.PodScheduled,
+					Reason:             "Unschedulable",
+					Message:            "0/1 nodes are available",
+					LastTransitionTime: transitioned,
+				}},
+			},
+		}
+	}
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				pendingPod("stale", old, old),
+				pendingPod("rescheduled", old, recent),
+				pendingPod("created", recent, recent),
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+		Since:     now.Add(-time.Hour),
+	}
+
+	results, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+	require.ElementsMatch(t, []string{"default/rescheduled", "default/created"}, names)
+}
+
+func TestPodAnalyzerPropagateLabels(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "Pod1",
+			Namespace:   "default",
+			Labels:      map[string]string{"team": "payments", "app.kubernetes.io/name": "api", "tier": "backend"},
+			Annotations: map[string]string{"team": "ignored", "owner": "oncall@example.com"},
+		},
+		Status: v1.PodStatus{
+			Phase:      v1.PodPending,
+			Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Reason: "Unschedulable", Message: "0/1 nodes are available"}},
+		},
+	}
+	config := common.Analyzer{
+		Client:          &kubernetes.Client{Client: fake.NewSimpleClientset(pod)},
+		Context:         context.Background(),
+		Namespace:       "default",
+		PropagateLabels: []string{"team", "app.kubernetes.io/name", "owner", "missing"},
+	}
+
+	results, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	// The labels take precedence over the annotations of the same key.
+	require.Equal(t, map[string]string{"team": "payments", "app.kubernetes.io/name": "api", "owner": "oncall@example.com"}, results[0].Labels)
+
+	config.PropagateLabels = nil
+	results, err = PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Nil(t, results[0].Labels)
+}
+
+func TestPodRemediation(t *testing.T) {
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "Pod1", Namespace: "default"},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "crashing", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+				{Name: "pulling", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			},
+		},
+	}
+
+	remediation := podRemediation(pod)
+	require.Len(t, remediation, 1)
+	require.Equal(t, "kubectl logs Pod1 -n default -c crashing --previous", remediation[0].Command)
+}
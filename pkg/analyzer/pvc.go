@@ -33,14 +33,22 @@ func (PvcAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	})
 
 	// search all namespaces for pods that are not running
-	list, err := a.Client.GetClient().CoreV1().PersistentVolumeClaims(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector})
+	list, err := a.Client.GetClient().CoreV1().PersistentVolumeClaims(a.Namespace).List(a.Context, metav1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(list.Items))
 
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, pvc := range list.Items {
+		var timestamps []metav1.Time
+		for _, condition := range pvc.Status.Conditions {
+			timestamps = append(timestamps, condition.LastTransitionTime)
+		}
+		if !a.InWindow(pvc.CreationTimestamp, timestamps...) {
+			continue
+		}
 		var failures []common.Failure
 
 		// Check for empty rs
@@ -69,9 +77,10 @@ func (PvcAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.PersistentVolumeClaim.ObjectMeta),
 		}
 
 		parent, found := util.GetParent(a.Client, value.PersistentVolumeClaim.ObjectMeta)
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+var (
+	registeredMutex sync.RWMutex
+	// registeredAnalyzerMap holds the analyzers added by Register and
+	// RegisterCore, registeredCoreAnalyzers the names of the latter.
+	registeredAnalyzerMap   = map[string]common.IAnalyzer{}
+	registeredCoreAnalyzers = map[string]bool{}
+)
+
+// Register adds an analyzer compiled in from outside of k8sgpt, e.g. in the
+// init function of its package. Like the additional analyzers, it runs when
+// selected by a filter. The names of the built-in analyzers can't be reused.
+func Register(name string, analyzer common.IAnalyzer) error {
+	return register(name, analyzer, false)
+}
+
+// RegisterCore is like Register, but the analyzer also runs when no filter is
+// selected, like the core analyzers.
+func RegisterCore(name string, analyzer common.IAnalyzer) error {
+	return register(name, analyzer, true)
+}
+
+func register(name string, analyzer common.IAnalyzer, core bool) error {
+	if name == "" {
+		return errors.New("an analyzer needs a name to be registered")
+	}
+	if analyzer == nil {
+		return fmt.Errorf("no analyzer to register as %s", name)
+	}
+	_, isCore := coreAnalyzerMap[name]
+	_, isAdditional := additionalAnalyzerMap[name]
+	if isCore || isAdditional {
+		return fmt.Errorf("analyzer %s is built in, register it under another name", name)
+	}
+
+	registeredMutex.Lock()
+	defer registeredMutex.Unlock()
+	if _, ok := registeredAnalyzerMap[name]; ok {
+		return fmt.Errorf("analyzer %s is already registered", name)
+	}
+	registeredAnalyzerMap[name] = analyzer
+	if core {
+		registeredCoreAnalyzers[name] = true
+	}
+	return nil
+}
+
+// Unregister removes an analyzer added by Register or RegisterCore, e.g. at the
+// end of a test. Unknown names are ignored.
+func Unregister(name string) {
+	registeredMutex.Lock()
+	defer registeredMutex.Unlock()
+	delete(registeredAnalyzerMap, name)
+	delete(registeredCoreAnalyzers, name)
+}
+
+// addRegisteredAnalyzers adds the registered analyzers to the maps of
+// GetAnalyzerMap.
+func addRegisteredAnalyzers(coreAnalyzers map[string]common.IAnalyzer, analyzers map[string]common.IAnalyzer) {
+	registeredMutex.RLock()
+	defer registeredMutex.RUnlock()
+	for name, analyzer := range registeredAnalyzerMap {
+		if registeredCoreAnalyzers[name] {
+			coreAnalyzers[name] = analyzer
+		}
+		analyzers[name] = analyzer
+	}
+}
+
+// registeredAnalyzerNames returns the names of the registered core and
+// additional analyzers, for ListFilters.
+func registeredAnalyzerNames() ([]string, []string) {
+	registeredMutex.RLock()
+	defer registeredMutex.RUnlock()
+	var core, additional []string
+	for name := range registeredAnalyzerMap {
+		if registeredCoreAnalyzers[name] {
+			core = append(core, name)
+		} else {
+			additional = append(additional, name)
+		}
+	}
+	return core, additional
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+type registeredAnalyzer struct{}
+
+func (registeredAnalyzer) Analyze(common.Analyzer) ([]common.Result, error) {
+	return []common.Result{{Kind: "Widget", Name: "default/widget"}}, nil
+}
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(func() {
+		registeredMutex.Lock()
+		defer registeredMutex.Unlock()
+		registeredAnalyzerMap = map[string]common.IAnalyzer{}
+		registeredCoreAnalyzers = map[string]bool{}
+	})
+
+	require.NoError(t, Register("Widget", registeredAnalyzer{}))
+	require.NoError(t, RegisterCore("Gadget", registeredAnalyzer{}))
+
+	coreAnalyzers, analyzers := GetAnalyzerMap()
+	require.Contains(t, analyzers, "Widget")
+	require.NotContains(t, coreAnalyzers, "Widget")
+	require.Contains(t, analyzers, "Gadget")
+	require.Contains(t, coreAnalyzers, "Gadget")
+
+	core, additional, _ := ListFilters()
+	require.Contains(t, core, "Gadget")
+	require.Contains(t, additional, "Widget")
+
+	require.EqualError(t, Register("Pod", registeredAnalyzer{}), "analyzer Pod is built in, register it under another name")
+	require.EqualError(t, RegisterCore("Log", registeredAnalyzer{}), "analyzer Log is built in, register it under another name")
+	require.EqualError(t, Register("Widget", registeredAnalyzer{}), "analyzer Widget is already registered")
+	require.EqualError(t, Register("", registeredAnalyzer{}), "an analyzer needs a name to be registered")
+	require.EqualError(t, Register("Empty", nil), "no analyzer to register as Empty")
+
+	Unregister("Widget")
+	_, analyzers = GetAnalyzerMap()
+	require.NotContains(t, analyzers, "Widget")
+	require.NoError(t, Register("Widget", registeredAnalyzer{}))
+}
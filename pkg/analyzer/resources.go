@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourcesAnalyzer flags the containers of the Deployments and of the Pods
+// which lack CPU or memory requests or limits. The pods of a ReplicaSet are
+// reported through their Deployment.
+type ResourcesAnalyzer struct{}
+
+func (ResourcesAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+	kind := "ContainerResources"
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	deployments, err := a.Client.GetClient().AppsV1().Deployments(a.Namespace).List(a.Context, metav1.ListOptions{
+		LabelSelector: a.LabelSelector,
+		FieldSelector: a.FieldSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{
+		LabelSelector: a.LabelSelector,
+		FieldSelector: a.FieldSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.CountScanned(len(deployments.Items) + len(pods.Items))
+
+	var results []common.Result
+	for _, deployment := range deployments.Items {
+		if !a.InWindow(deployment.CreationTimestamp) {
+			continue
+		}
+		failures := containerResourceFailures("deployment", deployment.Name, deployment.Spec.Template.Spec.Containers)
+		if len(failures) > 0 {
+			results = append(results, common.Result{
+				Kind:   kind + "/Deployment",
+				Name:   fmt.Sprintf("%s/%s", deployment.Namespace, deployment.Name),
+				Error:  failures,
+				Labels: a.ResultLabels(deployment.ObjectMeta),
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind+"/Deployment", deployment.Name, deployment.Namespace).Set(float64(len(failures)))
+		}
+	}
+	for _, pod := range pods.Items {
+		if !a.InWindow(pod.CreationTimestamp) || ownedByReplicaSet(pod.ObjectMeta) {
+			continue
+		}
+		failures := containerResourceFailures("pod", pod.Name, pod.Spec.Containers)
+		if len(failures) > 0 {
+			results = append(results, common.Result{
+				Kind:   kind + "/Pod",
+				Name:   fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+				Error:  failures,
+				Labels: a.ResultLabels(pod.ObjectMeta),
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind+"/Pod", pod.Name, pod.Namespace).Set(float64(len(failures)))
+		}
+	}
+
+	return results, nil
+}
+
+// containerResourceFailures reports a failure per container lacking a CPU or
+// memory request or limit, the name of the owner is masked when anonymizing.
+func containerResourceFailures(owner string, name string, containers []v1.Container) []common.Failure {
+	var failures []common.Failure
+	for _, container := range containers {
+		var missing []string
+		for _, resource := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+			if _, ok := container.Resources.Requests[resource]; !ok {
+				missing = append(missing, fmt.Sprintf("%s request", resource))
+			}
+			if _, ok := container.Resources.Limits[resource]; !ok {
+				missing = append(missing, fmt.Sprintf("%s limit", resource))
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		failures = append(failures, common.Failure{
+			Text: fmt.Sprintf("Container %s of %s %s has no %s", container.Name, owner, name, strings.Join(missing, ", ")),
+			Sensitive: []common.Sensitive{
+				{
+					Unmasked: name,
+					Masked:   util.MaskString(name),
+				},
+			},
+		})
+	}
+	return failures
+}
+
+func ownedByReplicaSet(meta metav1.ObjectMeta) bool {
+	for _, owner := range meta.OwnerReferences {
+		if owner.Kind == "ReplicaSet" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func governedResources() v1.ResourceRequirements {
+	quantities := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("100m"),
+		v1.ResourceMemory: resource.MustParse("128Mi"),
+	}
+	return v1.ResourceRequirements{Requests: quantities, Limits: quantities}
+}
+
+func TestResourcesAnalyzer(t *testing.T) {
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		expected []common.Result
+	}{
+		{
+			name: "requests and limits set",
+			objects: []runtime.Object{
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "nginx", Resources: governedResources()}}},
+				},
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+					Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{Containers: []v1.Container{{Name: "api", Resources: governedResources()}}},
+					}},
+				},
+			},
+		},
+		{
+			name: "requests and limits missing",
+			objects: []runtime.Object{
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Spec: v1.PodSpec{Containers: []v1.Container{
+						{Name: "nginx", Resources: governedResources()},
+						{Name: "sidecar", Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("10m")},
+						}},
+					}},
+				},
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+					Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{Containers: []v1.Container{{Name: "api"}}},
+					}},
+				},
+				// Reported through its Deployment.
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "api-6d4cf56db6-x7k2p",
+						Namespace:       "default",
+						OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-6d4cf56db6"}},
+					},
+					Spec: v1.PodSpec{Containers: []v1.Container{{Name: "api"}}},
+				},
+			},
+			expected: []common.Result{
+				{
+					Kind: "ContainerResources/Deployment",
+					Name: "default/api",
+					Error: []common.Failure{{
+						Text:      "Container api of deployment api has no cpu request, cpu limit, memory request, memory limit",
+						Sensitive: []common.Sensitive{{Unmasked: "api"}},
+					}},
+				},
+				{
+					Kind: "ContainerResources/Pod",
+					Name: "default/web",
+					Error: []common.Failure{{
+						Text:      "Container sidecar of pod web has no cpu limit, memory request, memory limit",
+						Sensitive: []common.Sensitive{{Unmasked: "web"}},
+					}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := common.Analyzer{
+				Client:    &kubernetes.Client{Client: fake.NewSimpleClientset(tt.objects...)},
+				Context:   context.Background(),
+				Namespace: "default",
+			}
+			results, err := ResourcesAnalyzer{}.Analyze(config)
+			require.NoError(t, err)
+			// The masks are random.
+			for _, result := range results {
+				for i := range result.Error {
+					for j := range result.Error[i].Sensitive {
+						require.NotEmpty(t, result.Error[i].Sensitive[j].Masked)
+						result.Error[i].Sensitive[j].Masked = ""
+					}
+				}
+			}
+			require.Equal(t, tt.expected, results)
+		})
+	}
+}
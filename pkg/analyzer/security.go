@@ -60,10 +60,12 @@ func analyzeServiceAccounts(a common.Analyzer) ([]common.Result, error) {
 
 	sas, err := a.Client.GetClient().CoreV1().ServiceAccounts(a.Namespace).List(a.Context, metav1.ListOptions{
 		LabelSelector: a.LabelSelector,
+		FieldSelector: a.FieldSelector,
 	})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(sas.Items))
 
 	for _, sa := range sas.Items {
 		var failures []common.Failure
@@ -92,9 +94,10 @@ func analyzeServiceAccounts(a common.Analyzer) ([]common.Result, error) {
 
 		if len(failures) > 0 {
 			results = append(results, common.Result{
-				Kind:  "Security/ServiceAccount",
-				Name:  fmt.Sprintf("%s/%s", sa.Namespace, sa.Name),
-				Error: failures,
+				Kind:   "Security/ServiceAccount",
+				Name:   fmt.Sprintf("%s/%s", sa.Namespace, sa.Name),
+				Error:  failures,
+				Labels: a.ResultLabels(sa.ObjectMeta),
 			})
 			AnalyzerErrorsMetric.WithLabelValues("Security/ServiceAccount", sa.Name, sa.Namespace).Set(float64(len(failures)))
 		}
@@ -108,10 +111,12 @@ func analyzeRoleBindings(a common.Analyzer) ([]common.Result, error) {
 
 	rbs, err := a.Client.GetClient().RbacV1().RoleBindings(a.Namespace).List(a.Context, metav1.ListOptions{
 		LabelSelector: a.LabelSelector,
+		FieldSelector: a.FieldSelector,
 	})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(rbs.Items))
 
 	for _, rb := range rbs.Items {
 		var failures []common.Failure
@@ -133,9 +138,10 @@ func analyzeRoleBindings(a common.Analyzer) ([]common.Result, error) {
 
 		if len(failures) > 0 {
 			results = append(results, common.Result{
-				Kind:  "Security/RoleBinding",
-				Name:  fmt.Sprintf("%s/%s", rb.Namespace, rb.Name),
-				Error: failures,
+				Kind:   "Security/RoleBinding",
+				Name:   fmt.Sprintf("%s/%s", rb.Namespace, rb.Name),
+				Error:  failures,
+				Labels: a.ResultLabels(rb.ObjectMeta),
 			})
 			AnalyzerErrorsMetric.WithLabelValues("Security/RoleBinding", rb.Name, rb.Namespace).Set(float64(len(failures)))
 		}
@@ -149,10 +155,12 @@ func analyzePodSecurityContexts(a common.Analyzer) ([]common.Result, error) {
 
 	pods, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{
 		LabelSelector: a.LabelSelector,
+		FieldSelector: a.FieldSelector,
 	})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(pods.Items))
 
 	for _, pod := range pods.Items {
 		var failures []common.Failure
@@ -180,9 +188,10 @@ func analyzePodSecurityContexts(a common.Analyzer) ([]common.Result, error) {
 
 		if len(failures) > 0 {
 			results = append(results, common.Result{
-				Kind:  "Security/Pod",
-				Name:  fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
-				Error: failures[:1],
+				Kind:   "Security/Pod",
+				Name:   fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+				Error:  failures[:1],
+				Labels: a.ResultLabels(pod.ObjectMeta),
 			})
 			AnalyzerErrorsMetric.WithLabelValues("Security/Pod", pod.Name, pod.Namespace).Set(1)
 		}
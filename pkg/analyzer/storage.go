@@ -64,6 +64,7 @@ func analyzeStorageClasses(a common.Analyzer) ([]common.Result, error) {
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(scs.Items))
 
 	for _, sc := range scs.Items {
 		var failures []common.Failure
@@ -95,9 +96,10 @@ func analyzeStorageClasses(a common.Analyzer) ([]common.Result, error) {
 
 		if len(failures) > 0 {
 			results = append(results, common.Result{
-				Kind:  "Storage/StorageClass",
-				Name:  sc.Name,
-				Error: failures,
+				Kind:   "Storage/StorageClass",
+				Name:   sc.Name,
+				Error:  failures,
+				Labels: a.ResultLabels(sc.ObjectMeta),
 			})
 			AnalyzerErrorsMetric.WithLabelValues("Storage/StorageClass", sc.Name, "").Set(float64(len(failures)))
 		}
@@ -113,6 +115,7 @@ func analyzePersistentVolumes(a common.Analyzer) ([]common.Result, error) {
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(pvs.Items))
 
 	for _, pv := range pvs.Items {
 		var failures []common.Failure
@@ -145,9 +148,10 @@ func analyzePersistentVolumes(a common.Analyzer) ([]common.Result, error) {
 
 		if len(failures) > 0 {
 			results = append(results, common.Result{
-				Kind:  "Storage/PersistentVolume",
-				Name:  pv.Name,
-				Error: failures,
+				Kind:   "Storage/PersistentVolume",
+				Name:   pv.Name,
+				Error:  failures,
+				Labels: a.ResultLabels(pv.ObjectMeta),
 			})
 			AnalyzerErrorsMetric.WithLabelValues("Storage/PersistentVolume", pv.Name, "").Set(float64(len(failures)))
 		}
@@ -161,10 +165,12 @@ func analyzePersistentVolumeClaims(a common.Analyzer) ([]common.Result, error) {
 
 	pvcs, err := a.Client.GetClient().CoreV1().PersistentVolumeClaims(a.Namespace).List(a.Context, metav1.ListOptions{
 		LabelSelector: a.LabelSelector,
+		FieldSelector: a.FieldSelector,
 	})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(pvcs.Items))
 
 	for _, pvc := range pvcs.Items {
 		var failures []common.Failure
@@ -204,9 +210,10 @@ func analyzePersistentVolumeClaims(a common.Analyzer) ([]common.Result, error) {
 		// Only report the first failure found
 		if len(failures) > 0 {
 			results = append(results, common.Result{
-				Kind:  "Storage/PersistentVolumeClaim",
-				Name:  fmt.Sprintf("%s/%s", pvc.Namespace, pvc.Name),
-				Error: failures[:1],
+				Kind:   "Storage/PersistentVolumeClaim",
+				Name:   fmt.Sprintf("%s/%s", pvc.Namespace, pvc.Name),
+				Error:  failures[:1],
+				Labels: a.ResultLabels(pvc.ObjectMeta),
 			})
 			AnalyzerErrorsMetric.WithLabelValues("Storage/PersistentVolumeClaim", pvc.Name, pvc.Namespace).Set(1)
 		}
@@ -42,13 +42,17 @@ func (ValidatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 		"analyzer_name": kind,
 	})
 
-	validatingWebhooks, err := a.Client.GetClient().AdmissionregistrationV1().ValidatingWebhookConfigurations().List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector})
+	validatingWebhooks, err := a.Client.GetClient().AdmissionregistrationV1().ValidatingWebhookConfigurations().List(context.Background(), v1.ListOptions{LabelSelector: a.LabelSelector, FieldSelector: a.FieldSelector})
 	if err != nil {
 		return nil, err
 	}
+	a.CountScanned(len(validatingWebhooks.Items))
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, webhookConfig := range validatingWebhooks.Items {
+		if !a.InWindow(webhookConfig.CreationTimestamp) {
+			continue
+		}
 		for _, webhook := range webhookConfig.Webhooks {
 			var failures []common.Failure
 			if webhook.ClientConfig.Service == nil {
@@ -144,9 +148,10 @@ func (ValidatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 	}
 	for key, value := range preAnalysis {
 		var currentAnalysis = common.Result{
-			Kind:  kind,
-			Name:  key,
-			Error: value.FailureDetails,
+			Kind:   kind,
+			Name:   key,
+			Error:  value.FailureDetails,
+			Labels: a.ResultLabels(value.ValidatingWebhook.ObjectMeta),
 		}
 
 		parent, found := util.GetParent(a.Client, value.ValidatingWebhook.ObjectMeta)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
@@ -18,6 +19,7 @@ type AzureCache struct {
 	noCache       bool
 	containerName string
 	session       *azblob.Client
+	ttl           time.Duration
 }
 
 type AzureCacheConfiguration struct {
@@ -75,6 +77,11 @@ func (s *AzureCache) Load(key string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if load.LastModified != nil && isExpired(*load.LastModified, s.ttl) {
+		load.Body.Close()
+		_ = s.Remove(key)
+		return "", ErrExpired
+	}
 	data := bytes.Buffer{}
 	retryReader := load.NewRetryReader(s.ctx, &azblob.RetryReaderOptions{})
 	_, err = data.ReadFrom(retryReader)
@@ -134,6 +141,10 @@ func (s *AzureCache) Exists(key string) bool {
 
 		for _, blob := range resp.Segment.BlobItems {
 			if *blob.Name == key {
+				if blob.Properties.LastModified != nil && isExpired(*blob.Properties.LastModified, s.ttl) {
+					_ = s.Remove(key)
+					return false
+				}
 				return true
 			}
 		}
@@ -153,3 +164,12 @@ func (s *AzureCache) GetName() string {
 func (s *AzureCache) DisableCache() {
 	s.noCache = true
 }
+
+func (s *AzureCache) SetTTL(ttl time.Duration) {
+	s.ttl = ttl
+}
+
+// Close is a no-op, Store writes synchronously.
+func (s *AzureCache) Close() error {
+	return nil
+}
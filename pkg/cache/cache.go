@@ -1,7 +1,11 @@
 package cache
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"google.golang.org/grpc/codes"
@@ -15,6 +19,7 @@ var (
 		&GCSCache{},
 		&S3Cache{},
 		&InterplexCache{},
+		&RedisCache{},
 	}
 )
 
@@ -28,6 +33,49 @@ type ICache interface {
 	IsCacheDisabled() bool
 	GetName() string
 	DisableCache()
+	// SetTTL makes Load and Exists treat entries stored more than ttl ago as
+	// missing. Zero, the default, means entries never expire.
+	SetTTL(ttl time.Duration)
+	// Close flushes the pending Store operations and releases the
+	// connections of the cache. It may be called more than once. The caches
+	// storing synchronously have nothing to flush.
+	Close() error
+}
+
+// ErrExpired is returned by Load for entries older than the cache TTL.
+var ErrExpired = errors.New("cache entry expired")
+
+// isExpired reports whether an entry last stored at storedAt outlived the ttl.
+func isExpired(storedAt time.Time, ttl time.Duration) bool {
+	return ttl > 0 && time.Since(storedAt) > ttl
+}
+
+// storedAtPrefix starts the timestamp the values are stored with by the caches
+// keeping no modification time, Interplex and Redis. It can't clash with
+// base64 data.
+const storedAtPrefix = "k8sgpt-stored-at:"
+
+// withStoredAt prepends the current time to the data to store.
+func withStoredAt(data string) string {
+	return fmt.Sprintf("%s%d:%s", storedAtPrefix, time.Now().Unix(), data)
+}
+
+// parseStoredValue splits a stored value into its data and timestamp. Values
+// stored without timestamp are returned as is.
+func parseStoredValue(value string) (string, time.Time, bool) {
+	rest, found := strings.CutPrefix(value, storedAtPrefix)
+	if !found {
+		return value, time.Time{}, false
+	}
+	seconds, data, found := strings.Cut(rest, ":")
+	if !found {
+		return value, time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return value, time.Time{}, false
+	}
+	return data, time.Unix(unix, 0), true
 }
 
 func New(cacheType string) ICache {
@@ -70,6 +118,8 @@ func NewCacheProvider(cacheType, bucketname, region, endpoint, storageAccount, c
 	case cacheType == "interplex":
 		cProvider.Interplex.ConnectionString = endpoint
 		cProvider.CurrentCacheType = "interplex"
+	case cacheType == "redis":
+		return NewRedisCacheProvider(RedisCacheConfiguration{URL: endpoint})
 	default:
 		return CacheProvider{}, status.Error(codes.Internal, fmt.Sprintf("%s is not a valid option", cacheType))
 	}
@@ -82,6 +132,21 @@ func NewCacheProvider(cacheType, bucketname, region, endpoint, storageAccount, c
 	return cProvider, nil
 }
 
+// NewRedisCacheProvider validates the Redis configuration by connecting to it
+// and returns the matching cache configuration.
+func NewRedisCacheProvider(redisInfo RedisCacheConfiguration) (CacheProvider, error) {
+	cProvider := CacheProvider{
+		CurrentCacheType: "redis",
+		Redis:            redisInfo,
+	}
+	cache := &RedisCache{}
+	if err := cache.Configure(cProvider); err != nil {
+		return CacheProvider{}, err
+	}
+	defer cache.client.Close()
+	return cProvider, nil
+}
+
 // If we have set a remote cache, return the remote cache configuration
 func GetCacheConfiguration() (ICache, error) {
 	cacheInfo, err := ParseCacheConfiguration()
@@ -99,15 +164,30 @@ func GetCacheConfiguration() (ICache, error) {
 		cache = &S3Cache{}
 	case cacheInfo.CurrentCacheType == "interplex":
 		cache = &InterplexCache{}
+	case cacheInfo.CurrentCacheType == "redis":
+		cache = &RedisCache{}
 	default:
 		cache = &FileBasedCache{}
 	}
 	err_config := cache.Configure(cacheInfo)
-	return cache, err_config
+	if err_config != nil {
+		return cache, err_config
+	}
+	if cacheInfo.TTL != "" {
+		ttl, err := time.ParseDuration(cacheInfo.TTL)
+		if err != nil || ttl < 0 {
+			return cache, fmt.Errorf("invalid cache TTL %q", cacheInfo.TTL)
+		}
+		cache.SetTTL(ttl)
+	}
+	return cache, nil
 }
 
 func AddRemoteCache(cacheInfo CacheProvider) error {
-
+	if cacheInfo.TTL == "" {
+		cacheInfo.TTL = viper.GetString("cache.ttl")
+	}
+	cacheInfo.Compress = cacheInfo.Compress || viper.GetBool("cache.compress")
 	viper.Set("cache", cacheInfo)
 
 	err := viper.WriteConfig()
@@ -124,7 +204,8 @@ func RemoveRemoteCache() error {
 		return status.Error(codes.Internal, "cache unmarshal")
 	}
 
-	cacheInfo = CacheProvider{}
+	// The TTL and the compression also apply to the local file cache.
+	cacheInfo = CacheProvider{TTL: cacheInfo.TTL, Compress: cacheInfo.Compress}
 	viper.Set("cache", cacheInfo)
 	err = viper.WriteConfig()
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
@@ -13,6 +14,7 @@ var _ (ICache) = (*FileBasedCache)(nil)
 
 type FileBasedCache struct {
 	noCache bool
+	ttl     time.Duration
 }
 
 func (f *FileBasedCache) Configure(cacheInfo CacheProvider) error {
@@ -49,7 +51,7 @@ func (*FileBasedCache) List() ([]CacheObjectDetails, error) {
 	return result, nil
 }
 
-func (*FileBasedCache) Exists(key string) bool {
+func (f *FileBasedCache) Exists(key string) bool {
 	path, err := xdg.CacheFile(filepath.Join("k8sgpt", key))
 
 	if err != nil {
@@ -64,16 +66,20 @@ func (*FileBasedCache) Exists(key string) bool {
 		return false
 	}
 
-	return exists
+	return exists && !f.pruneIfExpired(path)
 }
 
-func (*FileBasedCache) Load(key string) (string, error) {
+func (f *FileBasedCache) Load(key string) (string, error) {
 	path, err := xdg.CacheFile(filepath.Join("k8sgpt", key))
 
 	if err != nil {
 		return "", err
 	}
 
+	if f.pruneIfExpired(path) {
+		return "", ErrExpired
+	}
+
 	data, err := os.ReadFile(path)
 
 	if err != nil {
@@ -107,6 +113,26 @@ func (*FileBasedCache) Store(key string, data string) error {
 	return os.WriteFile(path, []byte(data), 0600)
 }
 
+// pruneIfExpired removes the cache file at path if it outlived the TTL, and
+// reports whether it did.
+func (f *FileBasedCache) pruneIfExpired(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !isExpired(info.ModTime(), f.ttl) {
+		return false
+	}
+	_ = os.Remove(path)
+	return true
+}
+
+func (s *FileBasedCache) SetTTL(ttl time.Duration) {
+	s.ttl = ttl
+}
+
+// Close is a no-op, Store writes synchronously.
+func (s *FileBasedCache) Close() error {
+	return nil
+}
+
 func (s *FileBasedCache) GetName() string {
 	return "file"
 }
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBasedCacheTTL(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	cache := &FileBasedCache{}
+	require.NoError(t, cache.Store("fresh", "value"))
+	require.NoError(t, cache.Store("stale", "value"))
+	stalePath := filepath.Join(xdg.CacheHome, "k8sgpt", "stale")
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(stalePath, old, old))
+
+	// No TTL by default.
+	require.True(t, cache.Exists("stale"))
+
+	cache.SetTTL(time.Hour)
+	require.True(t, cache.Exists("fresh"))
+	value, err := cache.Load("fresh")
+	require.NoError(t, err)
+	require.Equal(t, "value", value)
+
+	_, err = cache.Load("stale")
+	require.ErrorIs(t, err, ErrExpired)
+	require.False(t, cache.Exists("stale"))
+	// Expired entries are pruned.
+	_, err = os.Stat(stalePath)
+	require.True(t, os.IsNotExist(err))
+}
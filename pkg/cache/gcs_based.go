@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"log"
+	"time"
 
 	"cloud.google.com/go/storage"
 	"google.golang.org/api/iterator"
@@ -16,6 +17,7 @@ type GCSCache struct {
 	projectId  string
 	region     string
 	session    *storage.Client
+	ttl        time.Duration
 }
 
 type GCSCacheConfiguration struct {
@@ -77,6 +79,11 @@ func (s *GCSCache) Load(key string) (string, error) {
 	}
 	defer reader.Close()
 
+	if isExpired(reader.Attrs.LastModified, s.ttl) {
+		_ = s.Remove(key)
+		return "", ErrExpired
+	}
+
 	data, err := io.ReadAll(reader)
 	if err != nil {
 		return "", err
@@ -116,8 +123,24 @@ func (s *GCSCache) List() ([]CacheObjectDetails, error) {
 
 func (s *GCSCache) Exists(key string) bool {
 	obj := s.session.Bucket(s.bucketName).Object(key)
-	_, err := obj.Attrs(s.ctx)
-	return err == nil
+	attrs, err := obj.Attrs(s.ctx)
+	if err != nil {
+		return false
+	}
+	if isExpired(attrs.Updated, s.ttl) {
+		_ = s.Remove(key)
+		return false
+	}
+	return true
+}
+
+func (s *GCSCache) SetTTL(ttl time.Duration) {
+	s.ttl = ttl
+}
+
+// Close is a no-op, Store writes synchronously.
+func (s *GCSCache) Close() error {
+	return nil
 }
 
 func (s *GCSCache) IsCacheDisabled() bool {
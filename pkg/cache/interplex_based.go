@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	rpc "buf.build/gen/go/interplex-ai/schemas/grpc/go/protobuf/schema/v1/schemav1grpc"
 	schemav1 "buf.build/gen/go/interplex-ai/schemas/protocolbuffers/go/protobuf/schema/v1"
@@ -19,6 +20,7 @@ type InterplexCache struct {
 	client             InterplexClient
 	cacheServiceClient rpc.CacheServiceClient
 	noCache            bool
+	ttl                time.Duration
 }
 
 type InterplexCacheConfiguration struct {
@@ -52,7 +54,7 @@ func (c *InterplexCache) Store(key string, data string) error {
 	c.cacheServiceClient = serviceClient
 	req := schemav1.SetRequest{
 		Key:   key,
-		Value: data,
+		Value: withStoredAt(data),
 	}
 	_, err = c.cacheServiceClient.Set(context.Background(), &req)
 	if err != nil {
@@ -80,7 +82,12 @@ func (c *InterplexCache) Load(key string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return resp.Value, nil
+	data, storedAt, ok := parseStoredValue(resp.Value)
+	if ok && isExpired(storedAt, c.ttl) {
+		_ = c.Remove(key)
+		return "", ErrExpired
+	}
+	return data, nil
 }
 
 func (c *InterplexCache) List() ([]CacheObjectDetails, error) {
@@ -118,6 +125,15 @@ func (c *InterplexCache) Exists(key string) bool {
 	return err == nil
 }
 
+func (c *InterplexCache) SetTTL(ttl time.Duration) {
+	c.ttl = ttl
+}
+
+// Close is a no-op, Store writes synchronously.
+func (c *InterplexCache) Close() error {
+	return nil
+}
+
 func (c *InterplexCache) IsCacheDisabled() bool {
 	return c.noCache
 }
@@ -8,6 +8,9 @@ import (
 	"google.golang.org/grpc"
 	"net"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestInterplexCache(t *testing.T) {
@@ -75,3 +78,39 @@ func (m *mockCacheService) Get(ctx context.Context, req *schemav1.GetRequest) (*
 	}
 	return &schemav1.GetResponse{Value: value}, nil
 }
+
+func TestParseStoredValue(t *testing.T) {
+	data, storedAt, ok := parseStoredValue(storedAtPrefix + "1700000000:dmFsdWU=")
+	require.True(t, ok)
+	require.Equal(t, "dmFsdWU=", data)
+	require.Equal(t, time.Unix(1700000000, 0), storedAt)
+
+	// Values stored before timestamps were recorded are returned as is.
+	data, _, ok = parseStoredValue("dmFsdWU=")
+	require.False(t, ok)
+	require.Equal(t, "dmFsdWU=", data)
+}
+
+// Test: the values stored before timestamps were recorded are still loaded, and never expire
+func TestInterplexCache_LoadUntimestampedValue(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	service := &mockCacheService{data: map[string]string{
+		"old":   "dmFsdWU=",
+		"stale": storedAtPrefix + "1700000000:dmFsdWU=",
+	}}
+	s := grpc.NewServer()
+	rpc.RegisterCacheServiceServer(s, service)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	cache := &InterplexCache{configuration: InterplexCacheConfiguration{ConnectionString: lis.Addr().String()}}
+	cache.SetTTL(time.Hour)
+	value, err := cache.Load("old")
+	require.NoError(t, err)
+	require.Equal(t, "dmFsdWU=", value)
+	require.True(t, cache.Exists("old"))
+
+	_, err = cache.Load("stale")
+	require.ErrorIs(t, err, ErrExpired)
+}
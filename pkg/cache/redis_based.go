@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ ICache = (*RedisCache)(nil)
+
+// defaultRedisKeyPrefix namespaces the k8sgpt keys in a shared Redis.
+const defaultRedisKeyPrefix = "k8sgpt:"
+
+type RedisCache struct {
+	ctx     context.Context
+	noCache bool
+	prefix  string
+	ttl     time.Duration
+	client  *redis.Client
+}
+
+type RedisCacheConfiguration struct {
+	// URL is a redis:// or rediss:// connection URL, e.g. redis://localhost:6379/0.
+	URL      string `mapstructure:"url" yaml:"url,omitempty"`
+	Password string `mapstructure:"password" yaml:"password,omitempty"`
+	// DB overrides the database index of the URL when set.
+	DB int `mapstructure:"db" yaml:"db,omitempty"`
+	// Prefix is prepended to every key, so several users can share one Redis.
+	Prefix string `mapstructure:"prefix" yaml:"prefix,omitempty"`
+}
+
+func (r *RedisCache) Configure(cacheInfo CacheProvider) error {
+	r.ctx = context.Background()
+	if cacheInfo.Redis.URL == "" {
+		return errors.New("redis URL is required")
+	}
+	options, err := redis.ParseURL(cacheInfo.Redis.URL)
+	if err != nil {
+		return fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if cacheInfo.Redis.Password != "" {
+		options.Password = cacheInfo.Redis.Password
+	}
+	if cacheInfo.Redis.DB != 0 {
+		options.DB = cacheInfo.Redis.DB
+	}
+	r.prefix = cacheInfo.Redis.Prefix
+	if r.prefix == "" {
+		r.prefix = defaultRedisKeyPrefix
+	}
+
+	client := redis.NewClient(options)
+	if err := client.Ping(r.ctx).Err(); err != nil {
+		client.Close()
+		return fmt.Errorf("unable to connect to redis: %w", err)
+	}
+	r.client = client
+	return nil
+}
+
+func (r *RedisCache) Store(key string, data string) error {
+	return r.client.Set(r.ctx, r.prefix+key, withStoredAt(data), r.ttl).Err()
+}
+
+// Load returns the data of the key. The values stored with a timestamp expire
+// when older than the TTL, even when Redis would keep them, e.g. when the TTL
+// was lowered since. The values stored without are given the TTL from now, if
+// they would outlive it.
+func (r *RedisCache) Load(key string) (string, error) {
+	value, err := r.client.Get(r.ctx, r.prefix+key).Result()
+	if err != nil {
+		return "", err
+	}
+	data, storedAt, ok := parseStoredValue(value)
+	if ok && isExpired(storedAt, r.ttl) {
+		_ = r.Remove(key)
+		return "", ErrExpired
+	}
+	if !ok && r.ttl > 0 {
+		_ = r.client.ExpireLT(r.ctx, r.prefix+key, r.ttl).Err()
+	}
+	return data, nil
+}
+
+func (r *RedisCache) List() ([]CacheObjectDetails, error) {
+	var keys []CacheObjectDetails
+
+	iter := r.client.Scan(r.ctx, 0, r.prefix+"*", 0).Iterator()
+	for iter.Next(r.ctx) {
+		details := CacheObjectDetails{Name: strings.TrimPrefix(iter.Val(), r.prefix)}
+		// Redis doesn't track modification times, the last access is the closest.
+		if idle, err := r.client.ObjectIdleTime(r.ctx, iter.Val()).Result(); err == nil {
+			details.UpdatedAt = time.Now().Add(-idle)
+		}
+		keys = append(keys, details)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *RedisCache) Remove(key string) error {
+	removed, err := r.client.Del(r.ctx, r.prefix+key).Result()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return fmt.Errorf("key %s not found", key)
+	}
+	return nil
+}
+
+func (r *RedisCache) Exists(key string) bool {
+	_, err := r.Load(key)
+	return err == nil
+}
+
+// SetTTL sets the expiry of the entries, Redis expires those stored from now on
+// natively.
+func (r *RedisCache) SetTTL(ttl time.Duration) {
+	r.ttl = ttl
+}
+
+// Close closes the connections to Redis, the writes being synchronous there is
+// nothing to flush.
+func (r *RedisCache) Close() error {
+	if r.client == nil {
+		return nil
+	}
+	if err := r.client.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+func (r *RedisCache) IsCacheDisabled() bool {
+	return r.noCache
+}
+
+func (r *RedisCache) GetName() string {
+	return "redis"
+}
+
+func (r *RedisCache) DisableCache() {
+	r.noCache = true
+}
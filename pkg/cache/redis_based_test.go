@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisCache(t *testing.T, redisInfo RedisCacheConfiguration) (*RedisCache, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	redisInfo.URL = "redis://" + server.Addr()
+	cache := &RedisCache{}
+	require.NoError(t, cache.Configure(CacheProvider{Redis: redisInfo}))
+	return cache, server
+}
+
+func TestRedisCache(t *testing.T) {
+	cache, server := newTestRedisCache(t, RedisCacheConfiguration{Prefix: "team-a:"})
+
+	require.False(t, cache.Exists("key1"))
+	require.NoError(t, cache.Store("key1", "dmFsdWUx"))
+	require.True(t, cache.Exists("key1"))
+	require.True(t, server.Exists("team-a:key1"))
+
+	value, err := cache.Load("key1")
+	require.NoError(t, err)
+	require.Equal(t, "dmFsdWUx", value)
+
+	// Keys of other users sharing the Redis aren't listed.
+	require.NoError(t, server.Set("team-b:key2", "other"))
+	keys, err := cache.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, "key1", keys[0].Name)
+
+	require.NoError(t, cache.Remove("key1"))
+	require.False(t, cache.Exists("key1"))
+	require.Error(t, cache.Remove("key1"))
+}
+
+func TestRedisCacheClose(t *testing.T) {
+	cache, _ := newTestRedisCache(t, RedisCacheConfiguration{})
+	require.NoError(t, cache.Store("key1", "value1"))
+
+	require.NoError(t, cache.Close())
+	require.NoError(t, cache.Close())
+	require.False(t, cache.Exists("key1"))
+}
+
+func TestRedisCacheTTL(t *testing.T) {
+	cache, server := newTestRedisCache(t, RedisCacheConfiguration{})
+	cache.SetTTL(time.Hour)
+
+	require.NoError(t, cache.Store("key1", "value1"))
+	require.True(t, server.Exists(defaultRedisKeyPrefix+"key1"))
+	require.Equal(t, time.Hour, server.TTL(defaultRedisKeyPrefix+"key1"))
+
+	server.FastForward(time.Hour)
+	require.False(t, cache.Exists("key1"))
+}
+
+// Test: the entries stored before the TTL was lowered expire with their age
+func TestRedisCacheLoweredTTL(t *testing.T) {
+	cache, server := newTestRedisCache(t, RedisCacheConfiguration{})
+	require.NoError(t, server.Set(defaultRedisKeyPrefix+"stale", storedAtPrefix+"1700000000:dmFsdWU="))
+	require.NoError(t, cache.Store("fresh", "dmFsdWU="))
+
+	// Without TTL they never expire.
+	value, err := cache.Load("stale")
+	require.NoError(t, err)
+	require.Equal(t, "dmFsdWU=", value)
+
+	cache.SetTTL(time.Hour)
+	_, err = cache.Load("stale")
+	require.ErrorIs(t, err, ErrExpired)
+	require.False(t, server.Exists(defaultRedisKeyPrefix+"stale"))
+	require.True(t, cache.Exists("fresh"))
+}
+
+// Test: the entries stored without timestamp are given the TTL from now
+func TestRedisCacheUntimestampedValue(t *testing.T) {
+	cache, server := newTestRedisCache(t, RedisCacheConfiguration{})
+	require.NoError(t, server.Set(defaultRedisKeyPrefix+"old", "dmFsdWU="))
+	require.NoError(t, server.Set(defaultRedisKeyPrefix+"expiring", "dmFsdWU="))
+	server.SetTTL(defaultRedisKeyPrefix+"expiring", time.Minute)
+
+	cache.SetTTL(time.Hour)
+	value, err := cache.Load("old")
+	require.NoError(t, err)
+	require.Equal(t, "dmFsdWU=", value)
+	require.Equal(t, time.Hour, server.TTL(defaultRedisKeyPrefix+"old"))
+	require.True(t, cache.Exists("expiring"))
+	require.Equal(t, time.Minute, server.TTL(defaultRedisKeyPrefix+"expiring"))
+}
+
+func TestRedisCacheConfigure(t *testing.T) {
+	tests := []struct {
+		name      string
+		redisInfo RedisCacheConfiguration
+	}{
+		{"missing URL", RedisCacheConfiguration{}},
+		{"invalid URL", RedisCacheConfiguration{URL: "http://localhost:6379"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &RedisCache{}
+			require.Error(t, cache.Configure(CacheProvider{Redis: tt.redisInfo}))
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -16,6 +17,7 @@ type S3Cache struct {
 	noCache    bool
 	bucketName string
 	session    *s3.S3
+	ttl        time.Duration
 }
 
 type S3CacheConfiguration struct {
@@ -98,6 +100,11 @@ func (s *S3Cache) Load(key string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if result.LastModified != nil && isExpired(*result.LastModified, s.ttl) {
+		result.Body.Close()
+		_ = s.Remove(key)
+		return "", ErrExpired
+	}
 
 	buf := new(bytes.Buffer)
 	_, err_read := buf.ReadFrom(result.Body)
@@ -126,12 +133,27 @@ func (s *S3Cache) List() ([]CacheObjectDetails, error) {
 
 func (s *S3Cache) Exists(key string) bool {
 	// Check if the object exists in the bucket
-	_, err := s.session.HeadObject(&s3.HeadObjectInput{
+	head, err := s.session.HeadObject(&s3.HeadObjectInput{
 		Bucket: aws.String(s.bucketName),
 		Key:    aws.String(key),
 	})
-	return err == nil
+	if err != nil {
+		return false
+	}
+	if head.LastModified != nil && isExpired(*head.LastModified, s.ttl) {
+		_ = s.Remove(key)
+		return false
+	}
+	return true
+}
+
+func (s *S3Cache) SetTTL(ttl time.Duration) {
+	s.ttl = ttl
+}
 
+// Close is a no-op, Store writes synchronously.
+func (s *S3Cache) Close() error {
+	return nil
 }
 
 func (s *S3Cache) IsCacheDisabled() bool {
@@ -8,6 +8,13 @@ type CacheProvider struct {
 	Azure            AzureCacheConfiguration     `mapstructure:"azure" yaml:"azure,omitempty"`
 	S3               S3CacheConfiguration        `mapstructure:"s3" yaml:"s3,omitempty"`
 	Interplex        InterplexCacheConfiguration `mapstructure:"interplex" yaml:"interplex,omitempty"`
+	Redis            RedisCacheConfiguration     `mapstructure:"redis" yaml:"redis,omitempty"`
+	// TTL is the duration after which cached entries expire, e.g. 168h. Empty
+	// means they never expire.
+	TTL string `mapstructure:"ttl" yaml:"ttl,omitempty"`
+	// Compress stores the explanations compressed with gzip. The entries
+	// stored uncompressed are still read.
+	Compress bool `mapstructure:"compress" yaml:"compress,omitempty"`
 }
 
 type CacheObjectDetails struct {
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is how serious the problems of a Result are.
+type Severity string
+
+const (
+	SeverityCritical Severity = "Critical"
+	SeverityWarning  Severity = "Warning"
+	SeverityInfo     Severity = "Info"
+)
+
+// Severities lists the severities from the most to the least serious.
+var Severities = []Severity{SeverityCritical, SeverityWarning, SeverityInfo}
+
+// Rank orders severities, higher is more serious. The empty severity ranks
+// below all others.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityCritical:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ParseSeverity returns the severity matching name, ignoring case.
+func ParseSeverity(name string) (Severity, error) {
+	for _, severity := range Severities {
+		if strings.EqualFold(name, string(severity)) {
+			return severity, nil
+		}
+	}
+	return "", fmt.Errorf("invalid severity %q, must be one of %v", name, Severities)
+}
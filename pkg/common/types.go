@@ -15,6 +15,8 @@ package common
 
 import (
 	"context"
+	"encoding/json"
+	"sync/atomic"
 	"time"
 
 	openapi_v2 "github.com/google/gnostic/openapiv2"
@@ -25,9 +27,11 @@ import (
 	regv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	autov2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	networkv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	gtwapi "sigs.k8s.io/gateway-api/apis/v1"
 )
 
@@ -40,16 +44,89 @@ type Analyzer struct {
 	Context       context.Context
 	Namespace     string
 	LabelSelector string
+	// FieldSelector selects the listed objects by their fields, along with the
+	// LabelSelector. Empty selects all objects.
+	FieldSelector string
 	AIClient      ai.IAI
 	PreAnalysis   map[string]PreAnalysis
 	Results       []Result
 	OpenapiSchema *openapi_v2.Document
+	// Scanned counts the objects the analyzer examined, nil when they aren't
+	// counted.
+	Scanned *ScanCount
+	// Since is the cutoff of the analyzers which only consider the recent
+	// objects, the zero time considers them all.
+	Since time.Time
+	// PropagateLabels are the keys of the labels, or else annotations, of the
+	// objects copied to the Labels of their results by ResultLabels.
+	PropagateLabels []string
+}
+
+// InWindow reports whether an object created at created, or with one of the
+// timestamps, e.g. of its conditions, after the Since cutoff is considered.
+func (a Analyzer) InWindow(created metav1.Time, timestamps ...metav1.Time) bool {
+	if a.Since.IsZero() || !created.Time.Before(a.Since) {
+		return true
+	}
+	for _, timestamp := range timestamps {
+		if !timestamp.Time.Before(a.Since) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResultLabels returns the PropagateLabels of an object, taken from its labels
+// or else its annotations, nil when it has none of them.
+func (a Analyzer) ResultLabels(meta metav1.ObjectMeta) map[string]string {
+	var labels map[string]string
+	for _, key := range a.PropagateLabels {
+		value, ok := meta.Labels[key]
+		if !ok {
+			value, ok = meta.Annotations[key]
+		}
+		if !ok {
+			continue
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// CountScanned records that the analyzer examined n more objects.
+func (a Analyzer) CountScanned(n int) {
+	if a.Scanned != nil {
+		a.Scanned.Add(n)
+	}
+}
+
+// ScanCount is the number of objects examined by an analyzer.
+type ScanCount struct {
+	objects  atomic.Int64
+	reported atomic.Bool
+}
+
+// Add adds n objects to the count.
+func (c *ScanCount) Add(n int) {
+	c.objects.Add(int64(n))
+	c.reported.Store(true)
+}
+
+// Objects returns the number of objects examined, false when the analyzer
+// didn't report any count.
+func (c *ScanCount) Objects() (int, bool) {
+	return int(c.objects.Load()), c.reported.Load()
 }
 
 type PreAnalysis struct {
 	Pod                      v1.Pod
 	FailureDetails           []Failure
 	Deployment               appsv1.Deployment
+	Job                      batchv1.Job
+	CronJob                  batchv1.CronJob
 	ReplicaSet               appsv1.ReplicaSet
 	PersistentVolumeClaim    v1.PersistentVolumeClaim
 	Endpoint                 v1.Endpoints
@@ -76,11 +153,86 @@ type Result struct {
 	Error        []Failure `json:"error"`
 	Details      string    `json:"details"`
 	ParentObject string    `json:"parentObject"`
+	// Provider is the name of the AI provider which produced Details.
+	Provider string `json:"provider,omitempty"`
+	// Severity defaults to Warning when the analyzer doesn't set it.
+	Severity Severity `json:"severity,omitempty"`
+	// DetectedBy is the number of analyzers which reported this result, when
+	// more than one did.
+	DetectedBy int `json:"detectedBy,omitempty"`
+	// Confidence is the confidence of the AI provider in Details, between 0
+	// and 1, when it was requested and the provider reports it.
+	Confidence *float64 `json:"confidence,omitempty"`
+	// Remediation lists the commands suggested by the analyzer, then the ones
+	// suggested by the AI provider when asked to.
+	Remediation []Remediation `json:"remediation,omitempty"`
+	// Cluster is the kube context the result was found in, when several
+	// were analyzed in one run.
+	Cluster string `json:"cluster,omitempty"`
+	// Cached tells that Details was taken from the cache rather than
+	// generated by this analysis, so it may be older.
+	Cached bool `json:"cached,omitempty"`
+	// Labels are the labels and annotations of the object selected by the
+	// propagate_labels configuration key, e.g. to route the result to its
+	// owners.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Remediation is a command suggested to fix a result.
+type Remediation struct {
+	// Command is meant to be copy-pasted, e.g. kubectl rollout restart
+	// deployment/x.
+	Command string `json:"command"`
+	// Description tells what the command does, when known.
+	Description string `json:"description,omitempty"`
 }
 
 type AnalysisStats struct {
-	Analyzer     string        `json:"analyzer"`
-	DurationTime time.Duration `json:"durationTime"`
+	Analyzer string `json:"analyzer"`
+	// DurationTime is encoded in milliseconds as durationMs.
+	DurationTime     time.Duration `json:"-"`
+	PromptTokens     int           `json:"promptTokens"`
+	CompletionTokens int           `json:"completionTokens"`
+	// TokensEstimated is set when at least one completion of this analyzer had
+	// no usage reported by the AI provider and its tokens were estimated.
+	TokensEstimated bool `json:"tokensEstimated,omitempty"`
+	// ObjectsScanned is the number of objects the analyzer examined, nil when
+	// it doesn't report it.
+	ObjectsScanned *int `json:"objectsScanned,omitempty"`
+	// Problems is the number of failures the analyzer found.
+	Problems int `json:"problems"`
+}
+
+// analysisStatsJSON is the JSON encoding of AnalysisStats.
+type analysisStatsJSON struct {
+	analysisStatsFields
+	DurationMs float64 `json:"durationMs"`
+}
+
+// analysisStatsFields has the fields of AnalysisStats without its JSON methods.
+type analysisStatsFields AnalysisStats
+
+func (s AnalysisStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(analysisStatsJSON{
+		analysisStatsFields: analysisStatsFields(s),
+		DurationMs:          float64(s.DurationTime) / float64(time.Millisecond),
+	})
+}
+
+func (s *AnalysisStats) UnmarshalJSON(data []byte) error {
+	var decoded analysisStatsJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*s = AnalysisStats(decoded.analysisStatsFields)
+	s.DurationTime = time.Duration(decoded.DurationMs * float64(time.Millisecond))
+	return nil
+}
+
+// JSONSchemaAlias describes AnalysisStats by its JSON encoding in the JSON
+// Schema of the output.
+func (AnalysisStats) JSONSchemaAlias() any {
+	return analysisStatsJSON{}
 }
 
 type Failure struct {
@@ -15,6 +15,8 @@ type Client struct {
 	analyzerClient rpc.CustomAnalyzerServiceClient
 }
 
+// NewClient returns a client of the custom analyzer served at c. The connection
+// is established by the first Run, so it respects the context of the run.
 func NewClient(c Connection) (*Client, error) {
 
 	//nolint:staticcheck // Ignoring SA1019 for compatibility reasons
@@ -30,10 +32,11 @@ func NewClient(c Connection) (*Client, error) {
 	}, nil
 }
 
-func (cli *Client) Run() (common.Result, error) {
+// Run runs the custom analyzer, until ctx is done.
+func (cli *Client) Run(ctx context.Context) (common.Result, error) {
 	var result common.Result
 	req := &schemav1.RunRequest{}
-	res, err := cli.analyzerClient.Run(context.Background(), req)
+	res, err := cli.analyzerClient.Run(ctx, req)
 	if err != nil {
 		return result, err
 	}
@@ -56,3 +59,8 @@ func (cli *Client) Run() (common.Result, error) {
 	}
 	return result, nil
 }
+
+// Close closes the connection to the custom analyzer.
+func (cli *Client) Close() error {
+	return cli.c.Close()
+}
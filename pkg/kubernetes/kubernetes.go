@@ -14,6 +14,9 @@ limitations under the License.
 package kubernetes
 
 import (
+	"math"
+	"time"
+
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	"k8s.io/client-go/rest"
@@ -34,6 +37,31 @@ func (c *Client) GetCtrlClient() ctrl.Client {
 }
 
 func NewClient(kubecontext string, kubeconfig string) (*Client, error) {
+	return NewClientWithRateLimit(kubecontext, kubeconfig, 0, 0)
+}
+
+// NewClientWithRateLimit is like NewClient, but caps the requests sent to the
+// API server to qps per second with bursts of up to burst requests. Zero values
+// keep the client-go defaults.
+func NewClientWithRateLimit(kubecontext string, kubeconfig string, qps float32, burst int) (*Client, error) {
+	return NewClientWithOptions(kubecontext, kubeconfig, ClientOptions{QPS: qps, Burst: burst})
+}
+
+// ClientOptions tune the requests of a client to the API server.
+type ClientOptions struct {
+	// QPS caps the requests per second, with bursts of up to Burst requests.
+	// Zero values keep the client-go defaults.
+	QPS   float32
+	Burst int
+	// MaxRetries retries the reads failing transiently, e.g. throttled or
+	// while the API server restarts, waiting RetryBaseDelay doubled on each
+	// retry. Zero doesn't retry them.
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+}
+
+// NewClientWithOptions is like NewClient, with the requests tuned by options.
+func NewClientWithOptions(kubecontext string, kubeconfig string, options ClientOptions) (*Client, error) {
 	var config *rest.Config
 	config, err := rest.InClusterConfig()
 	if kubeconfig != "" || err != nil {
@@ -54,6 +82,8 @@ func NewClient(kubecontext string, kubeconfig string) (*Client, error) {
 			return nil, err
 		}
 	}
+	applyRateLimit(config, options.QPS, options.Burst)
+	applyRetries(config, options.MaxRetries, options.RetryBaseDelay)
 	clientSet, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, err
@@ -76,3 +106,16 @@ func NewClient(kubecontext string, kubeconfig string) (*Client, error) {
 		ServerVersion: serverVersion,
 	}, nil
 }
+
+func applyRateLimit(config *rest.Config, qps float32, burst int) {
+	if qps > 0 {
+		config.QPS = qps
+	}
+	if burst > 0 {
+		config.Burst = burst
+	}
+	// client-go refuses a rate limit without burst.
+	if config.QPS > 0 && config.Burst <= 0 {
+		config.Burst = int(math.Ceil(float64(config.QPS)))
+	}
+}
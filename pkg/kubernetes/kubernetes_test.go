@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestApplyRateLimit(t *testing.T) {
+	config := &rest.Config{}
+	applyRateLimit(config, 0, 0)
+	require.Equal(t, &rest.Config{}, config)
+
+	applyRateLimit(config, 2.5, 0)
+	require.Equal(t, float32(2.5), config.QPS)
+	require.Equal(t, 3, config.Burst)
+
+	applyRateLimit(config, 0, 7)
+	require.Equal(t, float32(2.5), config.QPS)
+	require.Equal(t, 7, config.Burst)
+}
+
+// Test: the requests to the API server are spread by the rate limiter
+func TestApplyRateLimit_RequestTiming(t *testing.T) {
+	var mutex sync.Mutex
+	var requests []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests = append(requests, time.Now())
+		mutex.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[]}`))
+	}))
+	defer server.Close()
+
+	config := &rest.Config{Host: server.URL}
+	applyRateLimit(config, 20, 1)
+	clientSet, err := kubernetes.NewForConfig(config)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := clientSet.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+	}
+	require.Len(t, requests, 5)
+	// One request every 50ms after the burst of one.
+	require.GreaterOrEqual(t, requests[4].Sub(requests[0]), 190*time.Millisecond)
+}
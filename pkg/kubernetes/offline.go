@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gtwapi "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// NewOfflineClient returns a client backed by the objects of the YAML and JSON
+// manifests found in dir and its subdirectories, to analyze manifests without
+// a cluster. The objects of a kind which isn't known offline, e.g. of a custom
+// resource, are ignored and returned as "<file>: <kind>".
+func NewOfflineClient(dir string) (*Client, []string, error) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, nil, err
+	}
+	if err := gtwapi.AddToScheme(scheme); err != nil {
+		return nil, nil, err
+	}
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+
+	var objects []runtime.Object
+	var ctrlObjects []ctrl.Object
+	var ignored []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isManifestFile(path) {
+			return nil
+		}
+		documents, err := readManifestDocuments(path)
+		if err != nil {
+			return fmt.Errorf("reading manifest %s: %w", path, err)
+		}
+		for _, document := range documents {
+			obj, gvk, err := decoder.Decode(document, nil, nil)
+			if runtime.IsNotRegisteredError(err) {
+				ignored = append(ignored, fmt.Sprintf("%s: %s", path, manifestKind(document)))
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("decoding manifest %s: %w", path, err)
+			}
+			// The clientset only knows the built-in kinds, the controller-runtime
+			// client also serves the Gateway API ones.
+			if clientgoscheme.Scheme.Recognizes(*gvk) {
+				objects = append(objects, obj)
+			}
+			if ctrlObj, ok := obj.(ctrl.Object); ok {
+				ctrlObjects = append(ctrlObjects, ctrlObj)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Client{
+		Client:        fake.NewSimpleClientset(objects...),
+		CtrlClient:    ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(ctrlObjects...).Build(),
+		Config:        &rest.Config{},
+		ServerVersion: &version.Info{},
+		Offline:       true,
+	}, ignored, nil
+}
+
+func isManifestFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// readManifestDocuments splits a manifest file into its YAML documents.
+func readManifestDocuments(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var documents [][]byte
+	reader := yaml.NewYAMLReader(bufio.NewReader(f))
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(document)) == 0 || isCommentOnly(document) {
+			continue
+		}
+		documents = append(documents, document)
+	}
+}
+
+func manifestKind(document []byte) string {
+	var typeMeta metav1.TypeMeta
+	if err := yaml.Unmarshal(document, &typeMeta); err != nil {
+		return "unknown kind"
+	}
+	return typeMeta.Kind
+}
+
+func isCommentOnly(document []byte) bool {
+	for _, line := range strings.Split(string(document), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
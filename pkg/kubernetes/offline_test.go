@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	gtwapi "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const offlineManifests = `# pods of the app
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+  namespace: default
+---
+# only a comment
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+`
+
+const offlineGateway = `apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: gateway
+  namespace: default
+spec:
+  gatewayClassName: example
+  listeners:
+  - name: http
+    port: 80
+    protocol: HTTP
+`
+
+func TestNewOfflineClient(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(offlineManifests), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "gateway"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gateway", "gateway.yml"), []byte(offlineGateway), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0o600))
+
+	client, ignored, err := NewOfflineClient(dir)
+	require.NoError(t, err)
+	require.True(t, client.Offline)
+	require.Equal(t, []string{filepath.Join(dir, "app.yaml") + ": Widget"}, ignored)
+
+	pods, err := client.GetClient().CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, pods.Items, 1)
+	require.Equal(t, "app", pods.Items[0].Name)
+
+	gateway := &gtwapi.Gateway{}
+	require.NoError(t, client.GetCtrlClient().Get(context.Background(), ctrl.ObjectKey{Namespace: "default", Name: "gateway"}, gateway))
+	require.Equal(t, gtwapi.ObjectName("example"), gateway.Spec.GatewayClassName)
+}
+
+func TestNewOfflineClient_Malformed(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte("apiVersion: v1\nkind: Pod\nmetadata: [\n"), 0o600))
+
+	_, _, err := NewOfflineClient(dir)
+	require.ErrorContains(t, err, "bad.yaml")
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util/backoff"
+	"k8s.io/client-go/rest"
+)
+
+// retryTransport retries the reads failing transiently, e.g. while the API
+// server restarts or throttles, with jittered exponential backoff unless the
+// response says when to retry. The writes aren't retried, they may have been
+// applied. Waiting is interrupted when the context of the request is done.
+type retryTransport struct {
+	Origin     http.RoundTripper
+	MaxRetries int
+	BaseDelay  time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.Origin.RoundTrip(req)
+	}
+	for attempt := 0; ; attempt++ {
+		response, err := t.Origin.RoundTrip(req)
+		if attempt >= t.MaxRetries || !retryable(response, err) {
+			return response, err
+		}
+		delay, ok := retryAfter(response)
+		if !ok {
+			delay = backoff.WithJitter(backoff.Exponential(t.BaseDelay, attempt))
+		}
+		if response != nil {
+			// Drained, so that the connection is reused.
+			_, _ = io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// retryable reports whether a request failed transiently: throttled, an error
+// of the API server or of a proxy in front of it, or a dropped connection.
+func retryable(response *http.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+	}
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the delay in seconds of the Retry-After header, if any.
+func retryAfter(response *http.Response) (time.Duration, bool) {
+	if response == nil {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return backoff.Cap(time.Duration(seconds) * time.Second), true
+}
+
+func applyRetries(config *rest.Config, maxRetries int, baseDelay time.Duration) {
+	if maxRetries <= 0 {
+		return
+	}
+	wrap := config.WrapTransport
+	config.WrapTransport = func(origin http.RoundTripper) http.RoundTripper {
+		if wrap != nil {
+			origin = wrap(origin)
+		}
+		return &retryTransport{Origin: origin, MaxRetries: maxRetries, BaseDelay: baseDelay}
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// flakyServer answers the first failures requests with status, or drops
+// their connection when status is zero, then lists no pods.
+func flakyServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			if status == 0 {
+				connection, _, err := w.(http.Hijacker).Hijack()
+				require.NoError(t, err)
+				connection.Close()
+				return
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func retryingClientSet(t *testing.T, server *httptest.Server, maxRetries int) kubernetes.Interface {
+	config := &rest.Config{Host: server.URL}
+	applyRetries(config, maxRetries, time.Millisecond)
+	clientSet, err := kubernetes.NewForConfig(config)
+	require.NoError(t, err)
+	return clientSet
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		maxRetries int
+		failures   int32
+		expected   int32
+		succeeds   bool
+	}{
+		{name: "service unavailable", status: http.StatusServiceUnavailable, maxRetries: 3, failures: 2, expected: 3, succeeds: true},
+		{name: "bad gateway", status: http.StatusBadGateway, maxRetries: 3, failures: 1, expected: 2, succeeds: true},
+		{name: "connection dropped", maxRetries: 3, failures: 2, expected: 3, succeeds: true},
+		{name: "retries exhausted", status: http.StatusInternalServerError, maxRetries: 2, failures: 100, expected: 3},
+		{name: "not retryable", status: http.StatusForbidden, maxRetries: 3, failures: 1, expected: 1},
+		{name: "retries disabled", status: http.StatusServiceUnavailable, failures: 1, expected: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := flakyServer(t, tt.failures, tt.status)
+			_, err := retryingClientSet(t, server, tt.maxRetries).CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+			if tt.succeeds {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+			require.Equal(t, tt.expected, requests.Load())
+		})
+	}
+}
+
+// Test: the writes aren't retried, they may have been applied
+func TestRetryTransport_Writes(t *testing.T) {
+	server, requests := flakyServer(t, 1, http.StatusServiceUnavailable)
+	_, err := retryingClientSet(t, server, 3).CoreV1().Pods("default").Create(context.Background(), &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web"}}, metav1.CreateOptions{})
+	require.True(t, apierrors.IsServiceUnavailable(err), err)
+	require.Equal(t, int32(1), requests.Load())
+}
+
+// Test: the wait before a retry is interrupted when the context is done
+func TestRetryTransport_Context(t *testing.T) {
+	server, requests := flakyServer(t, 100, http.StatusServiceUnavailable)
+	config := &rest.Config{Host: server.URL}
+	applyRetries(config, 3, time.Hour)
+	clientSet, err := kubernetes.NewForConfig(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = clientSet.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 10*time.Second)
+	require.Equal(t, int32(1), requests.Load())
+}
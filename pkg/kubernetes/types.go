@@ -14,6 +14,9 @@ type Client struct {
 	CtrlClient    ctrl.Client
 	Config        *rest.Config
 	ServerVersion *version.Info
+	// Offline is set when the client is backed by manifest files, see
+	// NewOfflineClient, so no live data such as events is available.
+	Offline bool
 }
 
 type K8sApiReference struct {
@@ -6,21 +6,26 @@ import (
 
 	schemav1 "buf.build/gen/go/k8sgpt-ai/k8sgpt/protocolbuffers/go/schema/v1"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func (h *Handler) Analyze(ctx context.Context, i *schemav1.AnalyzeRequest) (
 	*schemav1.AnalyzeResponse,
 	error,
 ) {
-	if i.Output == "" {
-		i.Output = "json"
+	// The response is decoded from the JSON output, the other formats can't
+	// be returned.
+	if i.Output != "" && i.Output != "json" {
+		return &schemav1.AnalyzeResponse{}, status.Errorf(codes.InvalidArgument, "unsupported output format %s, only json is supported in server mode", i.Output)
 	}
 
 	if int(i.MaxConcurrency) == 0 {
 		i.MaxConcurrency = 10
 	}
 
-	config, err := analysis.NewAnalysis(
+	config, err := analysis.NewAnalysisWithContext(
+		ctx,
 		i.Backend,
 		i.Language,
 		i.Filters,
@@ -37,22 +42,17 @@ func (h *Handler) Analyze(ctx context.Context, i *schemav1.AnalyzeRequest) (
 	if err != nil {
 		return &schemav1.AnalyzeResponse{}, err
 	}
-	config.Context = ctx // Replace context for correct timeouts.
 	defer config.Close()
 
-	if config.CustomAnalyzersAreAvailable() {
-		config.RunCustomAnalysis()
-	}
-	config.RunAnalysis()
-
-	if i.Explain {
-		err := config.GetAIResults(i.Output, i.Anonymize)
-		if err != nil {
-			return &schemav1.AnalyzeResponse{}, err
-		}
+	config.CustomAnalysis = config.CustomAnalyzersAreAvailable()
+	config.Anonymize = i.Anonymize
+	config.Metrics = analysis.PrometheusMetrics{}
+	result, err := config.Analyze()
+	if err != nil {
+		return &schemav1.AnalyzeResponse{}, err
 	}
 
-	out, err := config.PrintOutput(i.Output)
+	out, err := json.Marshal(result)
 	if err != nil {
 		return &schemav1.AnalyzeResponse{}, err
 	}
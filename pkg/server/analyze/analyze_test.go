@@ -0,0 +1,18 @@
+package analyze
+
+import (
+	"context"
+	"testing"
+
+	schemav1 "buf.build/gen/go/k8sgpt-ai/k8sgpt/protocolbuffers/go/schema/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Test: the output formats which can't be returned are rejected
+func TestAnalyze_UnsupportedOutput(t *testing.T) {
+	_, err := (&Handler{}).Analyze(context.Background(), &schemav1.AnalyzeRequest{Output: "text"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(t, err, "unsupported output format text")
+}
@@ -109,6 +109,7 @@ type AnalyzeRequest struct {
 	Language        string   `json:"language,omitempty"`
 	Filters         []string `json:"filters,omitempty"`
 	LabelSelector   string   `json:"labelSelector,omitempty"`
+	FieldSelector   string   `json:"fieldSelector,omitempty"`
 	NoCache         bool     `json:"noCache,omitempty"`
 	Explain         bool     `json:"explain,omitempty"`
 	MaxConcurrency  int      `json:"maxConcurrency,omitempty"`
@@ -208,6 +209,7 @@ func (s *MCPServer) handleAnalyze(ctx context.Context, request *AnalyzeRequest)
 		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Failed to create analysis: %v", err))), nil
 	}
 	defer analysis.Close()
+	analysis.FieldSelector = request.FieldSelector
 
 	// Run the analysis
 	analysis.RunAnalysis()
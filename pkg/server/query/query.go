@@ -29,6 +29,14 @@ func (h *Handler) Query(ctx context.Context, i *schemav1.QueryRequest) (
 			},
 		}, nil
 	}
+	if err := configAI.Validate(); err != nil {
+		return &schemav1.QueryResponse{
+			Response: "",
+			Error: &schemav1.QueryError{
+				Message: fmt.Sprintf("Invalid AI configuration: %v", err),
+			},
+		}, nil
+	}
 
 	var aiProvider ai.AIProvider
 	for _, provider := range configAI.Providers {
@@ -46,6 +54,17 @@ func (h *Handler) Query(ctx context.Context, i *schemav1.QueryRequest) (
 		}, nil
 	}
 
+	transport, err := configAI.NewTransport()
+	if err != nil {
+		return &schemav1.QueryResponse{
+			Response: "",
+			Error: &schemav1.QueryError{
+				Message: fmt.Sprintf("Failed to configure the AI transport: %v", err),
+			},
+		}, nil
+	}
+	aiProvider.Transport = transport
+
 	// Configure the AI client
 	if err := aiClient.Configure(&aiProvider); err != nil {
 		return &schemav1.QueryResponse{
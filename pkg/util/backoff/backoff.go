@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// MaxDelay caps a single backoff, so that a bogus Retry-After can't stall the
+// analysis.
+const MaxDelay = 2 * time.Minute
+
+// Exponential returns how long to wait before the given retry attempt,
+// starting at 0: base * 2^attempt, capped at MaxDelay. A zero base retries
+// right away.
+func Exponential(base time.Duration, attempt int) time.Duration {
+	delay := base << attempt
+	// The shift overflowed.
+	if delay>>attempt != base {
+		return MaxDelay
+	}
+	return Cap(delay)
+}
+
+// Cap caps a delay, e.g. requested by a Retry-After, at MaxDelay.
+func Cap(delay time.Duration) time.Duration {
+	return min(delay, MaxDelay)
+}
+
+// WithJitter randomizes the second half of a backoff delay, so that the
+// clients failing at once don't retry at once.
+func WithJitter(delay time.Duration) time.Duration {
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(delay-half+1)
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExponential(t *testing.T) {
+	require.Equal(t, 100*time.Millisecond, Exponential(100*time.Millisecond, 0))
+	require.Equal(t, 400*time.Millisecond, Exponential(100*time.Millisecond, 2))
+	require.Equal(t, MaxDelay, Exponential(time.Minute, 10))
+	// The overflowing shifts are capped too.
+	require.Equal(t, MaxDelay, Exponential(time.Second, 40))
+	require.Equal(t, MaxDelay, Exponential(time.Second, 64))
+	// A zero base retries right away.
+	require.Zero(t, Exponential(0, 3))
+}
+
+func TestCap(t *testing.T) {
+	require.Equal(t, 20*time.Second, Cap(20*time.Second))
+	require.Equal(t, MaxDelay, Cap(time.Hour))
+	require.Zero(t, Cap(0))
+}
+
+func TestWithJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		delay := WithJitter(400 * time.Millisecond)
+		require.GreaterOrEqual(t, delay, 200*time.Millisecond)
+		require.LessOrEqual(t, delay, 400*time.Millisecond)
+	}
+	require.Equal(t, time.Duration(1), WithJitter(1))
+	require.Zero(t, WithJitter(0))
+}
@@ -168,8 +168,16 @@ func ReplaceIfMatch(text string, pattern string, replacement string) string {
 	return text
 }
 
-func GetCacheKey(provider string, language string, sEnc string) string {
+// GetCacheKey returns the cache key of an AI explanation. The model and base URL
+// of the provider are part of the key so switching models doesn't serve stale
+// explanations. When both are empty the key matches the one used before they were
+// introduced; entries cached by older versions for a configured model simply
+// become cache misses.
+func GetCacheKey(provider string, model string, baseURL string, language string, sEnc string) string {
 	data := fmt.Sprintf("%s-%s-%s", provider, language, sEnc)
+	if model != "" || baseURL != "" {
+		data = fmt.Sprintf("%s-%s-%s-%s-%s", provider, model, baseURL, language, sEnc)
+	}
 
 	hash := sha256.Sum256([]byte(data))
 
@@ -260,6 +260,8 @@ func TestReplaceIfMatch(t *testing.T) {
 func TestGetCacheKey(t *testing.T) {
 	tests := []struct {
 		provider       string
+		model          string
+		baseURL        string
 		language       string
 		sEnc           string
 		expectedOutput string
@@ -277,9 +279,18 @@ func TestGetCacheKey(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.language, func(t *testing.T) {
-			require.Equal(t, tt.expectedOutput, GetCacheKey(tt.provider, tt.language, tt.sEnc))
+			require.Equal(t, tt.expectedOutput, GetCacheKey(tt.provider, tt.model, tt.baseURL, tt.language, tt.sEnc))
 		})
 	}
+
+	// Model and base URL produce distinct keys.
+	keys := map[string]bool{
+		GetCacheKey("openai", "", "", "english", "encoding"):                               true,
+		GetCacheKey("openai", "gpt-3.5-turbo", "", "english", "encoding"):                  true,
+		GetCacheKey("openai", "gpt-4o", "", "english", "encoding"):                         true,
+		GetCacheKey("openai", "gpt-4o", "http://localhost:8080/v1", "english", "encoding"): true,
+	}
+	require.Len(t, keys, 4)
 }
 
 func TestGetPodListByLabels(t *testing.T) {